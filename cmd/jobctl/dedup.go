@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+)
+
+func runDeduplicate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("dedup", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the duplicates without updating the jobs")
+	threshold := flags.Float64("threshold", 0, "minimum similarity of a near-duplicate (default dedup.threshold)")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	detection := services.DuplicateDetection{Threshold: cfg.Dedup.Threshold, Window: cfg.Dedup.Window}
+	if *threshold > 0 {
+		detection.Threshold = *threshold
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	result, err := services.DeduplicateStoredJobs(ctx, jobStorage, detection, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("Found %d duplicates among %d jobs (threshold %.2f)\n", result.Duplicates, result.Total, detection.Threshold)
+	fmt.Printf("%s %d jobs, %d failed\n", verb, result.Updated, result.Failed)
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/evaluation"
+	"job-scraper/internal/logging"
+)
+
+func runEvaluate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	dataset := flags.String("dataset", "testdata/evaluation/golden.json", "path to the golden dataset")
+	output := flags.String("out", "", "write the JSON report to this file (default: stdout)")
+	label := flags.String("label", "", "label stored in the report, defaults to the configured model")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	cases, err := evaluation.LoadDataset(*dataset)
+	if err != nil {
+		return err
+	}
+
+	jobProcessor, err := app.NewProcessor(cfg)
+	if err != nil {
+		return err
+	}
+
+	if *label == "" {
+		*label = fmt.Sprintf("%s/%s", cfg.Processor.Type, cfg.OpenAI.Model)
+	}
+
+	report, err := evaluation.Run(ctx, jobProcessor, cases, *label)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return writeReport(os.Stdout, report)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := writeReport(file, report); err != nil {
+		return err
+	}
+
+	printSummary(os.Stdout, report)
+	return nil
+}
+
+func writeReport(w io.Writer, report *evaluation.Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func printSummary(w io.Writer, report *evaluation.Report) {
+	fmt.Fprintf(w, "Evaluation %s (prompt version %s): %d cases\n", report.Label, report.PromptVersion, report.Cases)
+	fmt.Fprintf(w, "  error rate          %6.1f%%\n", report.ErrorRate*100)
+	fmt.Fprintf(w, "  parse failure rate  %6.1f%%\n", report.ParseFailureRate*100)
+	fmt.Fprintf(w, "  categories          P %.2f  R %.2f  F1 %.2f\n", report.Categories.Precision, report.Categories.Recall, report.Categories.F1)
+	fmt.Fprintf(w, "  skills              P %.2f  R %.2f  F1 %.2f\n", report.Skills.Precision, report.Skills.Recall, report.Skills.F1)
+	fmt.Fprintln(w, "  field accuracy:")
+	for _, name := range report.SortedFieldNames() {
+		score := report.Fields[name]
+		fmt.Fprintf(w, "    %-18s %6.1f%% (%d/%d)\n", name, score.Accuracy*100, score.Correct, score.Labelled)
+	}
+}
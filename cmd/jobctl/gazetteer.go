@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"job-scraper/internal/geo"
+)
+
+func runImportGazetteer(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import-gazetteer", flag.ContinueOnError)
+	input := flags.String("input", "", "official directory of localities with postal codes (CSV, WGS84)")
+	output := flags.String("output", "internal/geo/data/municipalities.csv", "gazetteer file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return errors.New("-input is required")
+	}
+
+	in, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Erst nach erfolgreichem Import ersetzen, die Aliase werden aus der alten Datei übernommen
+	tmp := *output + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	count, err := geo.ImportLocalities(in, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, *output); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d municipalities into %s\n", count, *output)
+	return nil
+}
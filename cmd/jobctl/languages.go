@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/langdetect"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+)
+
+func runDetectLanguages(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("detect-languages", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the detected languages without updating the jobs")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	detector, err := langdetect.New()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	result, err := services.DetectStoredLanguages(ctx, jobStorage, detector, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Detected"
+	if *dryRun {
+		verb = "Would detect"
+	}
+	fmt.Printf("%s the language of %d of %d jobs, %d undetermined, %d failed\n", verb, result.Detected, result.Total, result.Unknown, result.Failed)
+
+	languages := make([]string, 0, len(result.ByLanguage))
+	for language := range result.ByLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	for _, language := range languages {
+		fmt.Printf("  %s  %d\n", language, result.ByLanguage[language])
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+)
+
+func runCheckLifecycle(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("check-lifecycle", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "check the jobs without closing or updating them")
+	maxChecks := flags.Int("max-checks", -1, "online checks, 0 checks all (default lifecycle.max_checks)")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if *maxChecks >= 0 {
+		cfg.Lifecycle.MaxChecks = *maxChecks
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	result, err := app.NewLifecycleService(cfg, jobStorage).CheckJobs(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Closed"
+	if *dryRun {
+		verb = "Would close"
+	}
+	fmt.Printf("Checked %d of %d open jobs online: %d still online, %d failed\n",
+		result.Checked, result.OpenJobs, result.Confirmed, result.Failed)
+	fmt.Printf("%s %d expired and %d removed jobs\n", verb, result.Expired, result.Removed)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/geo"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+)
+
+func runResolveLocations(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("resolve-locations", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the locations that would be resolved without updating the jobs")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	gazetteer, err := geo.Load()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	result, err := services.ResolveStoredLocations(ctx, jobStorage, gazetteer, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Resolved"
+	if *dryRun {
+		verb = "Would resolve"
+	}
+	fmt.Printf("%s %d of %d locations, %d not found in the gazetteer, %d failed\n", verb, result.Resolved, result.Total, result.Unresolved, result.Failed)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog/log"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"evaluate", "Evaluate the extraction quality against a golden dataset", runEvaluate},
+	{"reprocess", "Run stored jobs through the current prompt and model again", runReprocess},
+	{"canonicalize-skills", "Map the skills of stored jobs to the canonical skill taxonomy", runCanonicalizeSkills},
+	{"parse-salaries", "Convert plain-text salaries of stored jobs into structured salaries", runParseSalaries},
+	{"resolve-locations", "Resolve the location text of stored jobs with the Swiss gazetteer", runResolveLocations},
+	{"import-gazetteer", "Build the Swiss gazetteer from the official directory of localities", runImportGazetteer},
+	{"detect-languages", "Detect the posting language of stored jobs", runDetectLanguages},
+	{"dedup", "Link near-duplicate jobs to the first posting", runDeduplicate},
+	{"check-lifecycle", "Close jobs whose posting has expired or was taken offline", runCheckLifecycle},
+	{"migrate", "Apply pending schema migrations to the database", runMigrate},
+}
+
+func main() {
+	if os.Getenv("JOBSCRAPER_IN_CONTAINER") != "true" {
+		if err := godotenv.Load(); err != nil {
+			log.Debug().Err(err).Msg("Error loading .env file")
+		}
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(ctx, os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: jobctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", cmd.name, cmd.description)
+	}
+}
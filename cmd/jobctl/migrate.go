@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+)
+
+func runMigrate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	status := flags.Bool("status", false, "list the migrations and whether they have been applied")
+	logLevel := flags.String("log-level", "info", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	if *status {
+		migrations, err := app.MigrationStatus(ctx, jobStorage)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			state := "pending"
+			if m.Applied {
+				state = "applied " + m.AppliedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%3d  %-24s %s\n", m.Version, state, m.Description)
+		}
+		return nil
+	}
+
+	applied, err := app.Migrate(ctx, jobStorage)
+	for _, m := range applied {
+		fmt.Printf("Applied migration %d: %s\n", m.Version, m.Description)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("Schema is up to date")
+	}
+	return nil
+}
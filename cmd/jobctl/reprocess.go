@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage"
+)
+
+func runReprocess(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reprocess", flag.ContinueOnError)
+	from := flags.String("from", "", "only jobs posted on or after this date (YYYY-MM-DD)")
+	to := flags.String("to", "", "only jobs posted before this date (YYYY-MM-DD)")
+	category := flags.String("category", "", "only jobs in this category")
+	promptVersion := flags.String("prompt-version", "", "only jobs extracted with this prompt version")
+	extractionMethod := flags.String("extraction-method", "", "only jobs extracted with this method (llm, rules)")
+	failedOnly := flags.Bool("failed-only", false, "retry jobs whose processing failed, other filters are ignored")
+	dryRun := flags.Bool("dry-run", false, "list the selected jobs without processing them")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	filter := storage.JobFilter{Category: *category, PromptVersion: *promptVersion, ExtractionMethod: *extractionMethod}
+	var err error
+	if filter.PostedFrom, err = parseDateFlag("from", *from); err != nil {
+		return err
+	}
+	if filter.PostedTo, err = parseDateFlag("to", *to); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	jobProcessor, err := app.NewProcessor(cfg)
+	if err != nil {
+		return err
+	}
+
+	service := services.NewReprocessService(jobStorage, jobProcessor)
+	req := services.ReprocessRequest{Filter: filter, FailedOnly: *failedOnly, DryRun: *dryRun}
+
+	progress, err := service.Run(ctx, req, func(p services.ReprocessProgress) {
+		fmt.Fprintf(os.Stderr, "\r%d/%d processed, %d failed", p.Processed, p.Total, p.Failed)
+	})
+	if progress.Processed > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, url := range progress.URLs {
+			fmt.Println(url)
+		}
+		fmt.Printf("%d jobs would be reprocessed\n", progress.Total)
+		return nil
+	}
+
+	fmt.Printf("Reprocessed %d of %d jobs: %d updated, %d failed\n", progress.Processed, progress.Total, progress.Updated, progress.Failed)
+	return nil
+}
+
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s date: %w", name, err)
+	}
+	return t, nil
+}
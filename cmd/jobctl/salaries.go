@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+)
+
+func runParseSalaries(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("parse-salaries", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the salaries that would be converted without updating them")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	result, err := services.ParseStoredSalaries(ctx, jobStorage, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Converted"
+	if *dryRun {
+		verb = "Would convert"
+	}
+	fmt.Printf("%s %d text salaries: %d parsed, %d without amount, %d failed\n", verb, result.Total, result.Parsed, result.Unparsed, result.Failed)
+	return nil
+}
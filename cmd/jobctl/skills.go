@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"job-scraper/internal/app"
+	"job-scraper/internal/config"
+	"job-scraper/internal/logging"
+	"job-scraper/internal/services"
+)
+
+func runCanonicalizeSkills(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("canonicalize-skills", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the jobs whose skills would change without updating them")
+	unmapped := flags.Int("unmapped", 0, "afterwards list this many skills that are missing in the taxonomy")
+	logLevel := flags.String("log-level", "warn", "log level")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logging.InitLogger(*logLevel)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	taxonomy, err := app.NewTaxonomy()
+	if err != nil {
+		return err
+	}
+
+	jobStorage, err := app.NewStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer jobStorage.Close(context.Background())
+
+	service := services.NewSkillService(jobStorage, taxonomy)
+	result, err := service.Canonicalize(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("%d of %d jobs would change with taxonomy version %s\n", result.Changed, result.Total, result.TaxonomyVersion)
+	} else {
+		fmt.Printf("Canonicalized %d jobs with taxonomy version %s: %d changed, %d failed\n", result.Total, result.TaxonomyVersion, result.Changed, result.Failed)
+	}
+
+	if *unmapped > 0 {
+		skills, err := service.UnmappedSkills(ctx, *unmapped)
+		if err != nil {
+			return err
+		}
+		fmt.Println("\nUnmapped skills:")
+		for _, skill := range skills {
+			fmt.Printf("  %-40s %d\n", skill.Name, skill.Jobs)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"job-scraper/internal/app"
 	"job-scraper/internal/apperrors"
 	"os"
@@ -13,6 +14,9 @@ import (
 )
 
 func main() {
+	demo := flag.Bool("demo", false, "Run with in-memory sample jobs, without scrapers and LLM")
+	flag.Parse()
+
 	// Only try to load .env file if we're not in a container
 	if os.Getenv("JOBSCRAPER_IN_CONTAINER") != "true" {
 		if err := godotenv.Load(); err != nil {
@@ -24,6 +28,11 @@ func main() {
 		log.Warn().Err(err).Msg("Error loading .env file")
 	}
 
+	if *demo {
+		// Die Konfiguration liest den Demo-Modus aus der Umgebung
+		os.Setenv("DEMO_ENABLED", "true")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
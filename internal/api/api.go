@@ -7,6 +7,7 @@ import (
 
 	"job-scraper/internal/api/middleware"
 	"job-scraper/internal/processor"
+	"job-scraper/internal/processor/budget"
 	"job-scraper/internal/scraper"
 	"job-scraper/internal/services"
 	"job-scraper/internal/storage"
@@ -15,13 +16,16 @@ import (
 )
 
 type API struct {
-	router          *mux.Router
-	scrapers        map[string]scraper.Scraper
-	storage         storage.Storage
-	processor       processor.JobProcessor
-	scraperService  *services.ScraperService
-	runningScrapers *sync.Map
-	jobStatsService *services.JobStatisticsService
+	router           *mux.Router
+	scrapers         map[string]scraper.Scraper
+	storage          storage.Storage
+	processor        processor.JobProcessor
+	scraperService   *services.ScraperService
+	runningScrapers  *sync.Map
+	jobStatsService  *services.JobStatisticsService
+	reprocessService *services.ReprocessService
+	skillService     *services.SkillService
+	budget           *budget.Tracker
 }
 
 func NewAPI(
@@ -30,15 +34,21 @@ func NewAPI(
 	processor processor.JobProcessor,
 	scraperService *services.ScraperService,
 	jobStatsService *services.JobStatisticsService,
+	reprocessService *services.ReprocessService,
+	skillService *services.SkillService,
+	budget *budget.Tracker,
 ) *API {
 	api := &API{
-		router:          mux.NewRouter(),
-		scrapers:        scrapers,
-		storage:         storage,
-		processor:       processor,
-		scraperService:  scraperService,
-		runningScrapers: &sync.Map{},
-		jobStatsService: jobStatsService,
+		router:           mux.NewRouter(),
+		scrapers:         scrapers,
+		storage:          storage,
+		processor:        processor,
+		scraperService:   scraperService,
+		runningScrapers:  &sync.Map{},
+		jobStatsService:  jobStatsService,
+		reprocessService: reprocessService,
+		skillService:     skillService,
+		budget:           budget,
 	}
 	api.setupRoutes()
 	return api
@@ -63,9 +73,22 @@ func (a *API) setupRoutes() {
 	v1Router.HandleFunc("/scrape/{scraper}", a.handleScrape).Methods("POST")
 	v1Router.HandleFunc("/scrapers/status", a.handleScrapersStatus).Methods("GET")
 
+	// Processor routes
+	v1Router.HandleFunc("/processor/budget", a.getBudget).Methods("GET")
+
+	// Reprocessing routes
+	v1Router.HandleFunc("/reprocess", a.handleReprocess).Methods("POST")
+	v1Router.HandleFunc("/reprocess/status", a.handleReprocessStatus).Methods("GET")
+
+	// Skill taxonomy routes
+	v1Router.HandleFunc("/skills/taxonomy", a.getSkillTaxonomy).Methods("GET")
+	v1Router.HandleFunc("/skills/unmapped", a.getUnmappedSkills).Methods("GET")
+
 	// Job routes
 	v1Router.HandleFunc("/jobs", a.getJobs).Methods("GET")
+	v1Router.HandleFunc("/jobs/search", a.searchJobs).Methods("GET")
 	v1Router.HandleFunc("/jobs/{id}", a.getJobByID).Methods("GET")
+	v1Router.HandleFunc("/jobs/{id}/versions", a.getJobVersions).Methods("GET")
 	v1Router.HandleFunc("/jobs/urls", a.getJobUrls).Methods("GET")
 
 	// Statistics routes
@@ -78,6 +101,7 @@ func (a *API) setupRoutes() {
 	v1Router.HandleFunc("/stats/avg-salary-by-education", a.getAvgSalaryByEducation).Methods("GET")
 	v1Router.HandleFunc("/stats/job-postings-trend", a.getJobPostingsTrend).Methods("GET")
 	v1Router.HandleFunc("/stats/languages-by-location", a.getLanguagesByLocation).Methods("GET")
+	v1Router.HandleFunc("/stats/jobs-by-canton", a.getJobsByCanton).Methods("GET")
 	v1Router.HandleFunc("/stats/employment-types", a.getEmploymentTypes).Methods("GET")
 	v1Router.HandleFunc("/stats/remote-work-by-category", a.getRemoteWorkByCategory).Methods("GET")
 	v1Router.HandleFunc("/stats/technology-trends", a.getTechnologyTrends).Methods("GET")
@@ -94,6 +118,8 @@ func (a *API) setupRoutes() {
 	v1Router.HandleFunc("/stats/mustskills/{skill}", a.getMustSkillFrequencyPerDay).Methods("GET")
 	v1Router.HandleFunc("/stats/optionalskills/{skill}", a.getOptionalSkillFrequencyPerDay).Methods("GET")
 	v1Router.HandleFunc("/stats/job-categories-counts", a.getJobCategoryCounts).Methods("GET")
+	v1Router.HandleFunc("/stats/posting-lifetime/{groupBy}", a.getPostingLifetime).Methods("GET")
+	v1Router.HandleFunc("/stats/posting-lifetime-trend", a.getPostingLifetimeTrend).Methods("GET")
 
 	a.router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
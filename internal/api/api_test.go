@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestAPI(t *testing.T) (*API, *memory.Store) {
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+	api := NewAPI(nil, store, nil, services.NewScraperService(store, nil),
+		services.NewJobStatisticsService(store), nil, nil, nil)
+	return api, store
+}
+
+func get(t *testing.T, api *API, path string, response interface{}) int {
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if response != nil && recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+	}
+	return recorder.Code
+}
+
+func TestJobRoutes(t *testing.T) {
+	ctx := context.Background()
+	api, store := newTestAPI(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, title := range []string{"Go Developer", "Rust Developer"} {
+		require.NoError(t, store.SaveJob(ctx, models.Job{
+			URL:           "https://example.com/" + title,
+			Title:         title,
+			Company:       "Example AG",
+			PostingDate:   postingDate.AddDate(0, 0, i),
+			JobCategories: []string{"SOFTWARE ENGINEER"},
+			MustSkills:    []string{strings.TrimSuffix(title, " Developer")},
+			Source:        &models.SourcePayload{RawPayload: "<p>" + title + "</p>", ContentType: "text/html"},
+		}))
+	}
+
+	var page storage.JobPage
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/jobs?skill=rust", &page))
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, "Rust Developer", page.Jobs[0].Title)
+
+	var job models.Job
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/jobs/"+page.Jobs[0].ID.Hex(), &job))
+	assert.Equal(t, "Rust Developer", job.Title)
+
+	// Die Rohdaten bleiben in der Datenbank
+	var raw map[string]interface{}
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/jobs/"+page.Jobs[0].ID.Hex(), &raw))
+	assert.NotContains(t, raw, "source")
+
+	assert.Equal(t, http.StatusNotFound, get(t, api, "/api/v1/jobs/"+primitive.NewObjectID().Hex(), nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, api, "/api/v1/jobs?cursor=invalid", nil))
+
+	var results storage.SearchPage
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/jobs/search?q=developer+-rust", &results))
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "Go Developer", results.Results[0].Job.Title)
+}
+
+func TestStatsRoutes(t *testing.T) {
+	ctx := context.Background()
+	api, store := newTestAPI(t)
+
+	for i, skills := range [][]string{{"Go", "SQL"}, {"Go"}} {
+		require.NoError(t, store.SaveJob(ctx, models.Job{
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			Title:       "Developer",
+			PostingDate: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+			MustSkills:  skills,
+		}))
+	}
+
+	var skills []map[string]interface{}
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/stats/top-skills", &skills))
+	require.Len(t, skills, 2)
+	assert.Equal(t, "Go", skills[0]["_id"])
+	assert.EqualValues(t, 2, skills[0]["count"])
+
+	assert.Equal(t, http.StatusBadRequest, get(t, api, "/api/v1/stats/companies-by-size/huge", nil))
+}
+
+func TestPostingLifetimeRoutes(t *testing.T) {
+	ctx := context.Background()
+	api, store := newTestAPI(t)
+	postingDate := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// Der letzte Job ist noch offen und zählt nicht
+	for i, days := range []int{10, 40, 20, 0} {
+		job := models.Job{
+			URL:           fmt.Sprintf("https://example.com/%d", i),
+			Title:         "Developer",
+			PostingDate:   postingDate,
+			JobCategories: []string{"BACKEND_DEVELOPER"},
+		}
+		if days > 0 {
+			closedAt := postingDate.AddDate(0, 0, days)
+			job.ClosedAt = &closedAt
+		}
+		require.NoError(t, store.SaveJob(ctx, job))
+	}
+
+	var lifetimes []services.GroupLifetime
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/stats/posting-lifetime/category", &lifetimes))
+	require.Len(t, lifetimes, 1)
+	assert.Equal(t, "BACKEND_DEVELOPER", lifetimes[0].ID)
+	assert.InDelta(t, 20, lifetimes[0].MedianDays, 1e-9)
+	assert.InDelta(t, 36, lifetimes[0].P90Days, 1e-9)
+	assert.Equal(t, 3, lifetimes[0].ClosedJobs)
+
+	var trend []services.MonthLifetime
+	require.Equal(t, http.StatusOK, get(t, api, "/api/v1/stats/posting-lifetime-trend", &trend))
+	require.Len(t, trend, 2)
+	assert.Equal(t, "2024-09", trend[0].Month)
+	assert.Equal(t, 2, trend[0].ClosedJobs)
+	assert.InDelta(t, 15, trend[0].MedianDays, 1e-9)
+	assert.Equal(t, "2024-10", trend[1].Month)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, api, "/api/v1/stats/posting-lifetime/salary", nil))
+}
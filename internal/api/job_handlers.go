@@ -3,34 +3,140 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"job-scraper/internal/apperrors"
+	"job-scraper/internal/search"
+	"job-scraper/internal/storage"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 )
 
+// getJobs returns a page of jobs. Filters, sort order and page size are given as
+// query parameters, further pages are requested with the nextCursor of the response.
 func (a *API) getJobs(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 0 {
-		limit = 50 // Default limit
+	query, err := parseJobQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	jobs, err := a.storage.GetJobs(ctx)
+	page, err := a.storage.QueryJobs(r.Context(), query)
 	if err != nil {
+		if apperrors.HasCode(err, apperrors.ErrCodeValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Error().Err(err).Msg("Failed to get jobs")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	if len(jobs) > limit && limit > 0 {
-		jobs = jobs[:limit]
+	respondJSON(w, page)
+}
+
+// searchJobs runs a full-text search given by the q parameter, most relevant jobs
+// first. It accepts the filters of getJobs; sort is ignored.
+func (a *API) searchJobs(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	text := strings.TrimSpace(values.Get("q"))
+	if text == "" {
+		http.Error(w, "missing search text, use ?q=", http.StatusBadRequest)
+		return
+	}
+	values.Del("sort")
+	query, err := parseJobQuery(values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := a.storage.SearchJobs(r.Context(), storage.JobSearch{Text: text, JobQuery: query})
+	if err != nil {
+		if apperrors.HasCode(err, apperrors.ErrCodeValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Error().Err(err).Str("q", text).Msg("Failed to search jobs")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	terms := search.Terms(text)
+	response := SearchResponse{
+		Results:    make([]SearchHit, 0, len(page.Results)),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+	for _, result := range page.Results {
+		response.Results = append(response.Results, SearchHit{
+			SearchResult: result,
+			Title:        search.Highlight(result.Job.Title, terms),
+			Snippets:     search.Snippets(result.Job.Description, terms),
+		})
+	}
+	respondJSON(w, response)
+}
+
+// parseJobQuery reads the query parameters of GET /api/v1/jobs. Dates are given as
+// YYYY-MM-DD, postedTo is exclusive. sort names a field, prefixed with "-" for
+// descending order; the newest jobs come first by default.
+func parseJobQuery(values url.Values) (storage.JobQuery, error) {
+	query := storage.JobQuery{
+		Category:       values.Get("category"),
+		Skill:          values.Get("skill"),
+		SkillType:      values.Get("skillType"),
+		Company:        values.Get("company"),
+		Location:       values.Get("location"),
+		EmploymentType: values.Get("employmentType"),
+		Cursor:         values.Get("cursor"),
+		SortBy:         storage.SortPostingDate,
+		SortDescending: true,
+	}
+
+	switch query.SkillType {
+	case storage.SkillTypeAny, storage.SkillTypeMust, storage.SkillTypeOptional:
+	default:
+		return query, fmt.Errorf("invalid skillType %q, must be must or optional", query.SkillType)
 	}
 
-	respondJSON(w, jobs)
+	var err error
+	if remote := values.Get("remote"); remote != "" {
+		value, err := strconv.ParseBool(remote)
+		if err != nil {
+			return query, fmt.Errorf("invalid remote value %q", remote)
+		}
+		query.Remote = &value
+	}
+	if query.PostedFrom, err = parseDate(values.Get("postedFrom")); err != nil {
+		return query, fmt.Errorf("invalid postedFrom date: %w", err)
+	}
+	if query.PostedTo, err = parseDate(values.Get("postedTo")); err != nil {
+		return query, fmt.Errorf("invalid postedTo date: %w", err)
+	}
+	if minExperience := values.Get("minExperience"); minExperience != "" {
+		if query.MinExperience, err = strconv.Atoi(minExperience); err != nil || query.MinExperience < 0 {
+			return query, fmt.Errorf("invalid minExperience %q", minExperience)
+		}
+	}
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+	}
+
+	if sortBy := values.Get("sort"); sortBy != "" {
+		query.SortBy = strings.TrimPrefix(sortBy, "-")
+		query.SortDescending = strings.HasPrefix(sortBy, "-")
+		if !storage.IsSortField(query.SortBy) {
+			return query, fmt.Errorf("invalid sort field %q", query.SortBy)
+		}
+	}
+	return query, nil
 }
 
 func (a *API) getJobByID(w http.ResponseWriter, r *http.Request) {
@@ -53,6 +159,26 @@ func (a *API) getJobByID(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, job)
 }
 
+// getJobVersions returns the prior versions of a job with the changed fields, newest first
+func (a *API) getJobVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	versions, err := a.storage.GetJobVersions(ctx, id)
+	if err != nil {
+		var notFoundErr *apperrors.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Error().Err(err).Str("id", id).Msg("Failed to get job versions")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, versions)
+}
+
 func (a *API) getJobUrls(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+)
+
+func (a *API) getBudget(w http.ResponseWriter, r *http.Request) {
+	status := BudgetStatus{
+		Enabled:    a.budget != nil,
+		QueuedJobs: a.scraperService.QueueLength(),
+	}
+	if a.budget != nil {
+		state := a.budget.State()
+		status.State = &state
+	}
+	respondJSON(w, status)
+}
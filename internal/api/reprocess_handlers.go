@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (a *API) handleReprocess(w http.ResponseWriter, r *http.Request) {
+	var body ReprocessRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	req, err := body.toServiceRequest()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	progress, err := a.reprocessService.Start(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrReprocessingRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Error().Err(err).Msg("Failed to start reprocessing")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !req.DryRun {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(progress)
+}
+
+func (a *API) handleReprocessStatus(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, a.reprocessService.Progress())
+}
+
+func (r ReprocessRequest) toServiceRequest() (services.ReprocessRequest, error) {
+	req := services.ReprocessRequest{
+		Filter: storage.JobFilter{
+			Category:         r.Category,
+			PromptVersion:    r.PromptVersion,
+			ExtractionMethod: r.ExtractionMethod,
+		},
+		FailedOnly: r.FailedOnly,
+		DryRun:     r.DryRun,
+	}
+
+	var err error
+	if req.Filter.PostedFrom, err = parseDate(r.From); err != nil {
+		return req, fmt.Errorf("invalid from date: %w", err)
+	}
+	if req.Filter.PostedTo, err = parseDate(r.To); err != nil {
+		return req, fmt.Errorf("invalid to date: %w", err)
+	}
+	return req, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"job-scraper/internal/services"
+
+	"github.com/rs/zerolog/log"
+)
+
+// UnmappedSkillsResponse lists the stored skills that are missing in the taxonomy
+type UnmappedSkillsResponse struct {
+	TaxonomyVersion string                   `json:"taxonomyVersion"`
+	Skills          []services.UnmappedSkill `json:"skills"`
+}
+
+func (a *API) getUnmappedSkills(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	unmapped, err := a.skillService.UnmappedSkills(r.Context(), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get unmapped skills")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, UnmappedSkillsResponse{
+		TaxonomyVersion: a.skillService.Taxonomy().Version(),
+		Skills:          unmapped,
+	})
+}
+
+func (a *API) getSkillTaxonomy(w http.ResponseWriter, r *http.Request) {
+	taxonomy := a.skillService.Taxonomy()
+	respondJSON(w, map[string]interface{}{
+		"version": taxonomy.Version(),
+		"skills":  taxonomy.Skills(),
+	})
+}
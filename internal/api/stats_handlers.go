@@ -2,13 +2,18 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/services"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 )
 
 func (a *API) getTopJobCategories(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetTopJobCategories()
+	result, err := a.jobStatsService.GetTopJobCategories(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get top job categories")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -18,7 +23,7 @@ func (a *API) getTopJobCategories(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getAvgExperienceByCategory(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetAvgExperienceByCategory()
+	result, err := a.jobStatsService.GetAvgExperienceByCategory(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get average experience by category")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -28,7 +33,7 @@ func (a *API) getAvgExperienceByCategory(w http.ResponseWriter, r *http.Request)
 }
 
 func (a *API) getRemoteVsOnsite(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetRemoteVsOnsite()
+	result, err := a.jobStatsService.GetRemoteVsOnsite(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get remote vs onsite data")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -38,7 +43,7 @@ func (a *API) getRemoteVsOnsite(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getTopSkills(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetTopSkills()
+	result, err := a.jobStatsService.GetTopSkills(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get top skills")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -48,7 +53,7 @@ func (a *API) getTopSkills(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getTopOptionalSkills(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetTopOptionalSkills()
+	result, err := a.jobStatsService.GetTopOptionalSkills(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get top optional skills")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -58,7 +63,7 @@ func (a *API) getTopOptionalSkills(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getBenefitsByCompanySize(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetBenefitsByCompanySize()
+	result, err := a.jobStatsService.GetBenefitsByCompanySize(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get benefits by company size")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -68,7 +73,7 @@ func (a *API) getBenefitsByCompanySize(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getAvgSalaryByEducation(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetAvgSalaryByEducation()
+	result, err := a.jobStatsService.GetAvgSalaryByEducation(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get average salary by education")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -78,7 +83,7 @@ func (a *API) getAvgSalaryByEducation(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getJobPostingsTrend(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobPostingsTrend()
+	result, err := a.jobStatsService.GetJobPostingsTrend(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job postings trend")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -88,7 +93,7 @@ func (a *API) getJobPostingsTrend(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getLanguagesByLocation(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetLanguagesByLocation()
+	result, err := a.jobStatsService.GetLanguagesByLocation(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get languages by location")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -97,8 +102,18 @@ func (a *API) getLanguagesByLocation(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, result)
 }
 
+func (a *API) getJobsByCanton(w http.ResponseWriter, r *http.Request) {
+	result, err := a.jobStatsService.GetJobsByCanton(statsFilter(r))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get jobs by canton")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, result)
+}
+
 func (a *API) getEmploymentTypes(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetEmploymentTypes()
+	result, err := a.jobStatsService.GetEmploymentTypes(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get employment types")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -108,7 +123,7 @@ func (a *API) getEmploymentTypes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getRemoteWorkByCategory(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetRemoteWorkByCategory()
+	result, err := a.jobStatsService.GetRemoteWorkByCategory(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get remote work by category")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -118,7 +133,7 @@ func (a *API) getRemoteWorkByCategory(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getTechnologyTrends(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetTechnologyTrends()
+	result, err := a.jobStatsService.GetTechnologyTrends(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get technology trends")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -128,7 +143,7 @@ func (a *API) getTechnologyTrends(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getJobRequirementsByLocation(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobRequirementsByLocation()
+	result, err := a.jobStatsService.GetJobRequirementsByLocation(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job requirements by location")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -138,7 +153,7 @@ func (a *API) getJobRequirementsByLocation(w http.ResponseWriter, r *http.Reques
 }
 
 func (a *API) getRemoteVsOnsiteByIndustry(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetRemoteVsOnsiteByIndustry()
+	result, err := a.jobStatsService.GetRemoteVsOnsiteByIndustry(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get remote vs onsite by industry")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -148,7 +163,7 @@ func (a *API) getRemoteVsOnsiteByIndustry(w http.ResponseWriter, r *http.Request
 }
 
 func (a *API) getJobCategoriesByCompanySize(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobCategoriesByCompanySize()
+	result, err := a.jobStatsService.GetJobCategoriesByCompanySize(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job categories by company size")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -158,7 +173,7 @@ func (a *API) getJobCategoriesByCompanySize(w http.ResponseWriter, r *http.Reque
 }
 
 func (a *API) getSkillsByExperienceLevel(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetSkillsByExperienceLevel()
+	result, err := a.jobStatsService.GetSkillsByExperienceLevel(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get skills by experience level")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -168,7 +183,7 @@ func (a *API) getSkillsByExperienceLevel(w http.ResponseWriter, r *http.Request)
 }
 
 func (a *API) getCompaniesBySize(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetCompaniesBySize()
+	result, err := a.jobStatsService.GetCompaniesBySize(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get companies by size")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -180,8 +195,12 @@ func (a *API) getCompaniesBySize(w http.ResponseWriter, r *http.Request) {
 func (a *API) getCompaniesBySizeAndType(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sizeType := vars["sizeType"]
-	result, err := a.jobStatsService.GetCompaniesBySizeAndType(sizeType)
+	result, err := a.jobStatsService.GetCompaniesBySizeAndType(statsFilter(r), sizeType)
 	if err != nil {
+		if apperrors.HasCode(err, apperrors.ErrCodeValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Error().Err(err).Str("sizeType", sizeType).Msg("Failed to get companies by size and type")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -190,7 +209,7 @@ func (a *API) getCompaniesBySizeAndType(w http.ResponseWriter, r *http.Request)
 }
 
 func (a *API) getCompanySizeDistribution(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetCompanySizeDistribution()
+	result, err := a.jobStatsService.GetCompanySizeDistribution(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get company size distribution")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -200,7 +219,7 @@ func (a *API) getCompanySizeDistribution(w http.ResponseWriter, r *http.Request)
 }
 
 func (a *API) getJobPostingsPerDay(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobPostingsPerDay()
+	result, err := a.jobStatsService.GetJobPostingsPerDay(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job postings per day")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -210,7 +229,7 @@ func (a *API) getJobPostingsPerDay(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getJobPostingsPerMonth(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobPostingsPerMonth()
+	result, err := a.jobStatsService.GetJobPostingsPerMonth(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job postings per month")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -220,7 +239,7 @@ func (a *API) getJobPostingsPerMonth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) getJobPostingsPerCompany(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobPostingsPerCompany()
+	result, err := a.jobStatsService.GetJobPostingsPerCompany(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job postings per company")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -232,7 +251,7 @@ func (a *API) getJobPostingsPerCompany(w http.ResponseWriter, r *http.Request) {
 func (a *API) getMustSkillFrequencyPerDay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	skill := vars["skill"]
-	result, err := a.jobStatsService.GetMustSkillFrequencyPerDay(skill)
+	result, err := a.jobStatsService.GetMustSkillFrequencyPerDay(statsFilter(r), skill)
 	if err != nil {
 		log.Error().Err(err).Str("skill", skill).Msg("Failed to get must skill frequency per day")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -244,7 +263,7 @@ func (a *API) getMustSkillFrequencyPerDay(w http.ResponseWriter, r *http.Request
 func (a *API) getOptionalSkillFrequencyPerDay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	skill := vars["skill"]
-	result, err := a.jobStatsService.GetOptionalSkillFrequencyPerDay(skill)
+	result, err := a.jobStatsService.GetOptionalSkillFrequencyPerDay(statsFilter(r), skill)
 	if err != nil {
 		log.Error().Err(err).Str("skill", skill).Msg("Failed to get optional skill frequency per day")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -254,7 +273,7 @@ func (a *API) getOptionalSkillFrequencyPerDay(w http.ResponseWriter, r *http.Req
 }
 
 func (a *API) getJobCategoryCounts(w http.ResponseWriter, r *http.Request) {
-	result, err := a.jobStatsService.GetJobCategoryCounts()
+	result, err := a.jobStatsService.GetJobCategoryCounts(statsFilter(r))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get job category counts")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -262,3 +281,41 @@ func (a *API) getJobCategoryCounts(w http.ResponseWriter, r *http.Request) {
 	}
 	respondJSON(w, result)
 }
+
+func (a *API) getPostingLifetime(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupBy := vars["groupBy"]
+	result, err := a.jobStatsService.GetPostingLifetime(statsFilter(r), groupBy)
+	if err != nil {
+		if apperrors.HasCode(err, apperrors.ErrCodeValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Error().Err(err).Str("groupBy", groupBy).Msg("Failed to get posting lifetime")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, result)
+}
+
+func (a *API) getPostingLifetimeTrend(w http.ResponseWriter, r *http.Request) {
+	result, err := a.jobStatsService.GetPostingLifetimeTrend(statsFilter(r))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get posting lifetime trend")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, result)
+}
+
+// statsFilter reads the optional filter parameters of the statistics endpoints,
+// e.g. ?language=fr to restrict a statistic to postings written in French or
+// ?excludeDuplicates=true to count reposted jobs once
+func statsFilter(r *http.Request) services.StatsFilter {
+	query := r.URL.Query()
+	excludeDuplicates, _ := strconv.ParseBool(query.Get("excludeDuplicates"))
+	return services.StatsFilter{
+		PostingLanguage:   strings.ToLower(strings.TrimSpace(query.Get("language"))),
+		ExcludeDuplicates: excludeDuplicates,
+	}
+}
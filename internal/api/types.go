@@ -1,8 +1,46 @@
 package api
 
+import (
+	"job-scraper/internal/processor/budget"
+	"job-scraper/internal/storage"
+)
+
 // ScraperStatus repräsentiert den Status eines laufenden Scrapers
 type ScraperStatus struct {
 	Name   string `json:"name"`
 	Status string `json:"status"`
 	Jobs   int    `json:"jobs"`
 }
+
+// BudgetStatus beschreibt den aktuellen Zustand des LLM-Budgets
+type BudgetStatus struct {
+	Enabled    bool `json:"enabled"`
+	QueuedJobs int  `json:"queuedJobs"`
+	*budget.State
+}
+
+// ReprocessRequest ist der Request-Body für POST /api/v1/reprocess.
+// Datumsangaben im Format YYYY-MM-DD, "to" ist exklusiv.
+type ReprocessRequest struct {
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Category         string `json:"category"`
+	PromptVersion    string `json:"promptVersion"`
+	ExtractionMethod string `json:"extractionMethod"`
+	FailedOnly       bool   `json:"failedOnly"`
+	DryRun           bool   `json:"dryRun"`
+}
+
+// SearchHit ist ein Treffer der Volltextsuche mit hervorgehobenen Suchbegriffen
+type SearchHit struct {
+	storage.SearchResult
+	Title    string   `json:"title"`    // Titel als escaptes HTML mit <em>-markierten Treffern
+	Snippets []string `json:"snippets"` // Ausschnitte der Beschreibung um die Treffer, ebenfalls HTML
+}
+
+// SearchResponse ist die Antwort von GET /api/v1/jobs/search
+type SearchResponse struct {
+	Results    []SearchHit `json:"results"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
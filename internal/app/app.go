@@ -10,7 +10,10 @@ import (
 	"job-scraper/internal/api"
 	"job-scraper/internal/apperrors"
 	"job-scraper/internal/config"
+	"job-scraper/internal/geo"
+	"job-scraper/internal/langdetect"
 	"job-scraper/internal/processor"
+	"job-scraper/internal/processor/budget"
 	"job-scraper/internal/scheduler"
 	"job-scraper/internal/services"
 	"job-scraper/internal/storage"
@@ -23,7 +26,9 @@ type App struct {
 	storage        storage.Storage
 	scheduler      *scheduler.Scheduler
 	processor      processor.JobProcessor
+	budget         *budget.Tracker
 	scraperService *services.ScraperService
+	lifecycle      *services.LifecycleService // nil if disabled
 	api            *api.API
 	server         *http.Server
 }
@@ -41,32 +46,87 @@ func New(ctx context.Context) (*App, error) {
 		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "Failed to initialize storage", err)
 	}
 
+	if err := initMigrations(ctx, cfg, storage); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "Failed to apply schema migrations", err)
+	}
+
+	if cfg.Demo.Enabled {
+		if err := seedDemoJobs(ctx, storage); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "Failed to store demo jobs", err)
+		}
+	}
+
 	scrapers := initScrapers(cfg)
 	initMetrics(storage)
 
+	budgetTracker := initBudget(cfg)
+
+	extractionCache, err := initExtractionCache(ctx, cfg, storage)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "Failed to initialize extraction cache", err)
+	}
+
+	taxonomy, err := initTaxonomy()
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeConfig, "Failed to load skill taxonomy", err)
+	}
+
+	gazetteer, err := geo.Load()
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeInitialization, "Failed to load gazetteer", err)
+	}
+
+	detector, err := langdetect.New()
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeInitialization, "Failed to load language detector", err)
+	}
+
 	// Initialisiere den Prozessor basierend auf der Konfiguration
-	processor, err := initProcessor(cfg)
+	processor, err := initProcessor(cfg, processorDeps{
+		tracker:   budgetTracker,
+		cache:     extractionCache,
+		taxonomy:  taxonomy,
+		gazetteer: gazetteer,
+		detector:  detector,
+	})
 	if err != nil {
 		return nil, apperrors.NewBaseError(apperrors.ErrCodeProcessing, "Failed to initialize processor", err)
 	}
 
 	scraperService := services.NewScraperService(storage, processor)
+	if cfg.Dedup.Enabled {
+		scraperService.SetDuplicateDetection(services.DuplicateDetection{
+			Threshold: cfg.Dedup.Threshold,
+			Window:    cfg.Dedup.Window,
+		})
+	}
 
 	sched, err := initScheduler(ctx, scraperService, scrapers, cfg)
 	if err != nil {
 		return nil, apperrors.NewBaseError(apperrors.ErrCodeInitialization, "Failed to initialize scheduler", err)
 	}
 
+	var lifecycle *services.LifecycleService
+	if cfg.Lifecycle.Enabled {
+		lifecycle = NewLifecycleService(cfg, storage)
+	}
+
 	jobStatsService := services.NewJobStatisticsService(storage)
 
-	apiHandler := api.NewAPI(scrapers, storage, processor, scraperService, jobStatsService)
+	reprocessService := services.NewReprocessService(storage, processor)
+
+	skillService := services.NewSkillService(storage, taxonomy)
+
+	apiHandler := api.NewAPI(scrapers, storage, processor, scraperService, jobStatsService, reprocessService, skillService, budgetTracker)
 
 	return &App{
 		cfg:            cfg,
 		storage:        storage,
 		scheduler:      sched,
 		processor:      processor,
+		budget:         budgetTracker,
 		scraperService: scraperService,
+		lifecycle:      lifecycle,
 		api:            apiHandler,
 		server: &http.Server{
 			Addr:    fmt.Sprintf(":%d", cfg.API.Port),
@@ -93,6 +153,16 @@ func (a *App) Run(ctx context.Context) error {
 	// Start scheduler
 	go a.scheduler.Start(ctx)
 
+	// Retry jobs that were queued because of an exhausted LLM budget
+	if a.budget != nil {
+		go a.scraperService.RunQueueWorker(ctx, a.cfg.Budget.RetryInterval)
+	}
+
+	// Close jobs whose posting has expired or was taken offline
+	if a.lifecycle != nil {
+		go a.lifecycle.Run(ctx, a.cfg.Lifecycle.Interval)
+	}
+
 	// Wait for context cancellation
 	<-ctx.Done()
 	return nil
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"job-scraper/internal/config"
+	"job-scraper/internal/processor/budget"
+)
+
+// initBudget creates the LLM budget tracker, or returns nil if budgeting is disabled
+func initBudget(cfg *config.Config) *budget.Tracker {
+	if !cfg.Budget.Enabled {
+		return nil
+	}
+
+	return budget.NewTracker(budget.Config{
+		DailyTokens:          cfg.Budget.DailyTokens,
+		MonthlyTokens:        cfg.Budget.MonthlyTokens,
+		DailyCost:            cfg.Budget.DailyCost,
+		MonthlyCost:          cfg.Budget.MonthlyCost,
+		PromptTokenPrice:     cfg.Budget.PromptTokenPrice,
+		CompletionTokenPrice: cfg.Budget.CompletionTokenPrice,
+	})
+}
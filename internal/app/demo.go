@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// demoJobs sind bereits extrahierte Beispiel-Jobs für den Demo-Modus
+//
+//go:embed demo_jobs.json
+var demoJobs []byte
+
+// seedDemoJobs stores the sample jobs of the demo mode, unless the storage already
+// holds jobs from a snapshot. The dates are moved, so the newest job was posted today.
+func seedDemoJobs(ctx context.Context, s storage.Storage) error {
+	count, err := s.GetTotalJobCount(ctx)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	var jobs []models.Job
+	if err := json.Unmarshal(demoJobs, &jobs); err != nil {
+		return fmt.Errorf("invalid demo jobs: %w", err)
+	}
+
+	var latest time.Time
+	for _, job := range jobs {
+		if job.PostingDate.After(latest) {
+			latest = job.PostingDate
+		}
+	}
+	days := int(time.Now().UTC().Truncate(24*time.Hour).Sub(latest).Hours() / 24)
+
+	for _, job := range jobs {
+		job.PostingDate = job.PostingDate.AddDate(0, 0, days)
+		job.ExpirationDate = job.ExpirationDate.AddDate(0, 0, days)
+		if job.ClosedAt != nil {
+			closedAt := job.ClosedAt.AddDate(0, 0, days)
+			job.ClosedAt = &closedAt
+		}
+		if err := s.SaveJob(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Int("jobs", len(jobs)).Msg("Stored demo jobs")
+	return nil
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedDemoJobs(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+
+	require.NoError(t, seedDemoJobs(ctx, store))
+	total, err := store.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, total)
+
+	// Der neueste Job wurde heute ausgeschrieben
+	page, err := store.QueryJobs(ctx, storage.JobQuery{SortDescending: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour), page.Jobs[0].PostingDate.UTC().Truncate(24*time.Hour))
+
+	// Ein geladener Snapshot wird nicht erneut befüllt
+	require.NoError(t, seedDemoJobs(ctx, store))
+	count, err := store.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, total, count)
+}
@@ -0,0 +1,17 @@
+package app
+
+import (
+	"net/http"
+
+	"job-scraper/internal/config"
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage"
+)
+
+// NewLifecycleService creates the pass over the open jobs, also for command line tools
+func NewLifecycleService(cfg *config.Config, jobStorage storage.Storage) *services.LifecycleService {
+	return services.NewLifecycleService(jobStorage, &http.Client{Timeout: cfg.Lifecycle.Timeout}, services.LifecycleConfig{
+		MissingAfter: cfg.Lifecycle.MissingAfter,
+		MaxChecks:    cfg.Lifecycle.MaxChecks,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"job-scraper/internal/metrics"
 	"job-scraper/internal/metrics/domains"
 	"job-scraper/internal/storage"
-	"job-scraper/internal/storage/mongodb"
 	"net"
 	"net/http"
 	"time"
@@ -29,23 +28,11 @@ func trackConnections(storage storage.Storage) {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		// Versuche, den konkreten MongoDB-Client zu erhalten
-		if mongoClient, ok := storage.(*mongodb.Client); ok {
+		if mongoClient := unwrapMongoClient(storage); mongoClient != nil {
 			if client := mongoClient.GetMongoClient(); client != nil {
-				// Verwende den Mongo-Client für die Statistiken
 				stats := float64(client.NumberSessionsInProgress())
 				domains.DBConnectionsActive.Set(stats)
 			}
-		} else if decorator, ok := storage.(*mongodb.MetricsDecorator); ok {
-			// Versuche den ursprünglichen Client aus dem Decorator zu bekommen
-			if originalStorage := decorator.GetOriginalStorage(); originalStorage != nil {
-				if mongoClient, ok := originalStorage.(*mongodb.Client); ok {
-					if client := mongoClient.GetMongoClient(); client != nil {
-						stats := float64(client.NumberSessionsInProgress())
-						domains.DBConnectionsActive.Set(stats)
-					}
-				}
-			}
 		}
 	}
 }
@@ -3,43 +3,208 @@ package app
 import (
 	"fmt"
 	"job-scraper/internal/config"
+	"job-scraper/internal/geo"
+	"job-scraper/internal/langdetect"
 	"job-scraper/internal/processor"
+	"job-scraper/internal/processor/budget"
+	"job-scraper/internal/processor/chain"
+	"job-scraper/internal/processor/cleaner"
 	"job-scraper/internal/processor/openai"
+	"job-scraper/internal/processor/postprocess"
+	"job-scraper/internal/processor/router"
+	"job-scraper/internal/processor/rules"
+	"job-scraper/internal/skills"
 	// Future processor implementations:
 	// "job-scraper/internal/processor/claude"
 	// "job-scraper/internal/processor/gpt4all"
 	// etc.
+
+	"github.com/rs/zerolog/log"
 )
 
-// initProcessor initializes the appropriate job processor based on the configuration
+// processorDeps bündelt die Abhängigkeiten, die die Stages der Chain gemeinsam nutzen
+type processorDeps struct {
+	tracker   *budget.Tracker
+	cache     openai.ExtractionCache
+	taxonomy  *skills.Taxonomy
+	gazetteer *geo.Gazetteer
+	detector  *langdetect.Detector
+}
+
+// initProcessor builds the processor chain configured in processor.chain
 // Returns a JobProcessor interface implementation and an error if initialization fails
-func initProcessor(cfg *config.Config) (processor.JobProcessor, error) {
-	switch cfg.Processor.Type {
+func initProcessor(cfg *config.Config, deps processorDeps) (processor.JobProcessor, error) {
+	stages := make([]chain.Stage, 0, len(cfg.Processor.Chain))
+	for _, stageCfg := range cfg.Processor.Chain {
+		policy, err := chain.ParsePolicy(stageCfg.OnError)
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %w", stageCfg.Stage, err)
+		}
+
+		stageProcessor, err := initStage(cfg, stageCfg.Stage, deps)
+		if err != nil {
+			return nil, err
+		}
+
+		stage := chain.Stage{Name: stageCfg.Stage, Processor: stageProcessor, OnError: policy}
+		if stageCfg.Fallback != "" {
+			if stage.Fallback, err = initStage(cfg, stageCfg.Fallback, deps); err != nil {
+				return nil, fmt.Errorf("fallback of stage %s: %w", stageCfg.Stage, err)
+			}
+		}
+
+		stages = append(stages, stage)
+	}
+
+	return chain.New(stages...), nil
+}
+
+// initStage creates the processor of a single chain stage
+func initStage(cfg *config.Config, name string, deps processorDeps) (processor.JobProcessor, error) {
+	switch name {
+	case "clean":
+		return cleaner.NewStage(cleaner.Config{
+			MaxTokens: cfg.Preprocessing.MaxTokens,
+			Overflow:  cfg.Preprocessing.Overflow,
+		}), nil
+	case "llm":
+		llmProcessor, err := initLLMProcessor(cfg, deps)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Preprocessing.Overflow == cleaner.OverflowChunk {
+			return cleaner.NewChunker(llmProcessor, cfg.Preprocessing.MaxTokens), nil
+		}
+		return llmProcessor, nil
+	case "rules":
+		return rules.NewProcessor(), nil
+	case "validate_categories":
+		return processor.Func(postprocess.ValidateCategories), nil
+	case "normalize_skills":
+		return postprocess.NewSkillNormalizer(deps.taxonomy), nil
+	case "parse_salary":
+		return processor.Func(postprocess.ParseSalary), nil
+	case "normalize_location":
+		return postprocess.NewLocationNormalizer(deps.gazetteer), nil
+	case "detect_language":
+		return postprocess.NewLanguageDetector(deps.detector), nil
+	default:
+		return nil, fmt.Errorf("unknown processor stage: %s", name)
+	}
+}
+
+// initLLMProcessor builds the router over the providers configured in processor.providers
+func initLLMProcessor(cfg *config.Config, deps processorDeps) (processor.JobProcessor, error) {
+	providers := make([]router.Provider, 0, len(cfg.Processor.Providers))
+	for _, providerCfg := range cfg.Processor.Providers {
+		providerProcessor, err := initProvider(cfg, providerCfg, deps)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", providerCfg.Name, err)
+		}
+		providers = append(providers, router.Provider{
+			Name:      providerCfg.Name,
+			Processor: providerProcessor,
+			Weight:    providerCfg.Weight,
+		})
+	}
+
+	return router.New(providers...)
+}
+
+// initProvider initializes the processor of a single provider
+func initProvider(cfg *config.Config, providerCfg config.ProviderConfig, deps processorDeps) (processor.JobProcessor, error) {
+	switch providerCfg.Type {
 	case "openai":
-		return initOpenAIProcessor(cfg)
+		tracker := deps.tracker
+		if !providerCfg.Metered {
+			tracker = nil
+		}
+		return initOpenAIProcessor(cfg, providerCfg, tracker, deps.cache)
 	// Future processor types:
 	// case "claude":
 	//     return initClaudeProcessor(cfg)
 	// case "gemini":
 	//     return initGeminiProcessor(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported processor type: %s", cfg.Processor.Type)
+		return nil, fmt.Errorf("unsupported processor type: %s", providerCfg.Type)
 	}
 }
 
-// initOpenAIProcessor initializes an OpenAI processor with the provided configuration
+// initOpenAIProcessor initializes a processor for an OpenAI-compatible API
+// Settings not given in the provider configuration are taken from the openai section
+// If a budget tracker is given, the processor reports its token usage and is guarded by the budget, cache hits excepted
+// If a cache is given, extraction results are cached by content hash
 // Returns a configured OpenAI processor instance and an error if initialization fails
-func initOpenAIProcessor(cfg *config.Config) (processor.JobProcessor, error) {
+func initOpenAIProcessor(cfg *config.Config, providerCfg config.ProviderConfig, tracker *budget.Tracker, cache openai.ExtractionCache) (processor.JobProcessor, error) {
 	openaiConfig := openai.Config{
-		APIURL:      cfg.OpenAI.APIURL,
+		Provider:    providerCfg.Name,
+		APIURL:      firstNonEmpty(providerCfg.APIURL, cfg.OpenAI.APIURL),
 		APIKey:      cfg.OpenAI.APIKey,
-		Model:       cfg.OpenAI.Model,
+		Model:       firstNonEmpty(providerCfg.Model, cfg.OpenAI.Model),
 		Temperature: cfg.OpenAI.Temperature,
 		MaxTokens:   cfg.OpenAI.MaxTokens,
 		TopP:        cfg.OpenAI.TopP,
 		FreqPenalty: cfg.OpenAI.FreqPenalty,
 		PresPenalty: cfg.OpenAI.PresPenalty,
 	}
+	// Ein eigener Endpoint bekommt nie den OpenAI-Key
+	if providerCfg.APIURL != "" {
+		openaiConfig.APIKey = providerCfg.APIKey
+	}
+
 	promptRepo := openai.NewFilePromptRepository()
-	return openai.NewProcessor(openaiConfig, promptRepo), nil
+	openaiProcessor := openai.NewProcessor(openaiConfig, promptRepo)
+	if cache != nil {
+		openaiProcessor.SetCache(cache)
+	}
+	if tracker == nil {
+		return openaiProcessor, nil
+	}
+
+	openaiProcessor.SetUsageRecorder(tracker)
+	openaiProcessor.SetBudget(tracker)
+	return openaiProcessor, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// initTaxonomy loads the skill taxonomy used to canonicalize extracted skills
+func initTaxonomy() (*skills.Taxonomy, error) {
+	taxonomy, err := skills.Load(skills.DefaultPath())
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("version", taxonomy.Version()).Int("skills", len(taxonomy.Skills())).Msg("Loaded skill taxonomy")
+	return taxonomy, nil
+}
+
+// NewProcessor builds the configured job processor for command line tools.
+// It runs without extraction cache, so every job is sent to the LLM.
+// The configured budget applies to the lifetime of the process.
+func NewProcessor(cfg *config.Config) (processor.JobProcessor, error) {
+	taxonomy, err := initTaxonomy()
+	if err != nil {
+		return nil, err
+	}
+	gazetteer, err := geo.Load()
+	if err != nil {
+		return nil, err
+	}
+	detector, err := langdetect.New()
+	if err != nil {
+		return nil, err
+	}
+	return initProcessor(cfg, processorDeps{tracker: initBudget(cfg), taxonomy: taxonomy, gazetteer: gazetteer, detector: detector})
+}
+
+// NewTaxonomy loads the skill taxonomy for command line tools
+func NewTaxonomy() (*skills.Taxonomy, error) {
+	return initTaxonomy()
 }
@@ -11,6 +11,11 @@ import (
 )
 
 func initScrapers(cfg *config.Config) map[string]scraper.Scraper {
+	// Im Demo-Modus wird nichts gescrapt
+	if cfg.Demo.Enabled {
+		return map[string]scraper.Scraper{}
+	}
+
 	client := &http.Client{}
 
 	scraperCfg := cfg.Scrapers[scraper.JobsChScraperName]
@@ -2,17 +2,116 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"job-scraper/internal/config"
+	"job-scraper/internal/processor/openai"
 	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
 	"job-scraper/internal/storage/mongodb"
+	"job-scraper/internal/storage/postgres"
+	"job-scraper/internal/storage/sqlite"
+
+	"github.com/rs/zerolog/log"
 )
 
 func initStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
-	baseStorage, err := mongodb.NewClient(ctx, cfg.MongoDB.URI, cfg.MongoDB.Database)
-	if err != nil {
-		return nil, err
+	var baseStorage storage.Storage
+	switch cfg.Storage.Type {
+	case config.StorageSQLite:
+		sqliteClient, err := sqlite.NewClient(ctx, cfg.SQLite.Path)
+		if err != nil {
+			return nil, err
+		}
+		// Die SQLite-Datei gehört der Anwendung, das Schema ist immer aktuell
+		if _, err := sqliteClient.Migrate(ctx); err != nil {
+			sqliteClient.Close(ctx)
+			return nil, err
+		}
+		baseStorage = sqliteClient
+	case config.StorageMemory:
+		memoryStore, err := memory.NewStore(cfg.Memory.SnapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		baseStorage = memoryStore
+	case config.StoragePostgres:
+		postgresClient, err := postgres.NewClient(ctx, cfg.Postgres.URI, cfg.Postgres.MaxConns)
+		if err != nil {
+			return nil, err
+		}
+		baseStorage = postgresClient
+	default:
+		mongoClient, err := mongodb.NewClient(ctx, cfg.MongoDB.URI, cfg.MongoDB.Database)
+		if err != nil {
+			return nil, err
+		}
+		baseStorage = mongoClient
 	}
 
 	// wrape the base storage to the metricsdecorator
-	return mongodb.NewMetricsDecorator(baseStorage), nil
+	return storage.NewMetricsDecorator(baseStorage), nil
+}
+
+// NewStorage opens the configured storage for command line tools
+func NewStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
+	return initStorage(ctx, cfg)
+}
+
+// initMigrations applies the pending schema migrations if migrate_on_startup is set
+// for the MongoDB or PostgreSQL storage. SQLite is always migrated when opened.
+func initMigrations(ctx context.Context, cfg *config.Config, s storage.Storage) error {
+	var migrateOnStartup bool
+	switch cfg.Storage.Type {
+	case config.StorageMongoDB:
+		migrateOnStartup = cfg.MongoDB.MigrateOnStartup
+	case config.StoragePostgres:
+		migrateOnStartup = cfg.Postgres.MigrateOnStartup
+	default:
+		return nil
+	}
+	if !migrateOnStartup {
+		log.Info().Msg("Schema migrations on startup disabled, run jobctl migrate to apply them")
+		return nil
+	}
+	_, err := Migrate(ctx, s)
+	return err
+}
+
+// Migrate applies the pending schema migrations and returns the applied ones
+func Migrate(ctx context.Context, s storage.Storage) ([]storage.MigrationStatus, error) {
+	migrator, ok := storage.Unwrap(s).(storage.Migrator)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support schema migrations")
+	}
+	return migrator.Migrate(ctx)
+}
+
+// MigrationStatus lists the schema migrations and whether they have been applied
+func MigrationStatus(ctx context.Context, s storage.Storage) ([]storage.MigrationStatus, error) {
+	migrator, ok := storage.Unwrap(s).(storage.Migrator)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support schema migrations")
+	}
+	return migrator.MigrationStatus(ctx)
+}
+
+// unwrapMongoClient returns the underlying MongoDB client, or nil if the storage is not backed by MongoDB
+func unwrapMongoClient(s storage.Storage) *mongodb.Client {
+	mongoClient, _ := storage.Unwrap(s).(*mongodb.Client)
+	return mongoClient
+}
+
+// initExtractionCache creates the extraction cache, or returns nil if caching is disabled
+func initExtractionCache(ctx context.Context, cfg *config.Config, s storage.Storage) (openai.ExtractionCache, error) {
+	if !cfg.ExtractionCache.Enabled {
+		return nil, nil
+	}
+
+	mongoClient := unwrapMongoClient(s)
+	if mongoClient == nil {
+		log.Warn().Msg("Extraction cache requires MongoDB storage, caching disabled")
+		return nil, nil
+	}
+
+	return mongodb.NewExtractionCache(ctx, mongoClient, cfg.ExtractionCache.TTL)
 }
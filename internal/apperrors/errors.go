@@ -1,7 +1,9 @@
 package apperrors
 
 import (
+	"errors"
 	"fmt"
+	"time"
 )
 
 const (
@@ -17,6 +19,8 @@ const (
 	ErrCodeScheduler      = "SCHEDULER_ERROR"
 	ErrCodeParser         = "PARSER_ERROR"
 	ErrCodeInternal       = "INTERNAL_ERROR"
+	ErrCodeBudgetExceeded = "BUDGET_EXCEEDED"
+	ErrCodeRateLimited    = "RATE_LIMITED"
 )
 
 // BaseError ist der Basis-Fehlertyp
@@ -45,6 +49,21 @@ func (e *BaseError) Unwrap() error {
 	return e.Err
 }
 
+// HasCode reports whether any BaseError in the chain of err has the given code
+func HasCode(err error, code string) bool {
+	for err != nil {
+		var baseErr *BaseError
+		if !errors.As(err, &baseErr) {
+			return false
+		}
+		if baseErr.Code == code {
+			return true
+		}
+		err = baseErr.Err
+	}
+	return false
+}
+
 // NotFoundError für nicht gefundene Ressourcen
 type NotFoundError struct {
 	*BaseError
@@ -87,3 +106,22 @@ func NewProcessingError(jobID string, message string, err error) *ProcessingErro
 		JobID:     jobID,
 	}
 }
+
+// BudgetExceededError signalisiert ein erschöpftes LLM-Budget
+type BudgetExceededError struct {
+	*BaseError
+	Window   string
+	ResetsAt time.Time
+}
+
+func NewBudgetExceededError(window string, resetsAt time.Time) *BudgetExceededError {
+	return &BudgetExceededError{
+		BaseError: NewBaseError(
+			ErrCodeBudgetExceeded,
+			fmt.Sprintf("%s LLM budget exhausted, resets at %s", window, resetsAt.Format(time.RFC3339)),
+			nil,
+		),
+		Window:   window,
+		ResetsAt: resetsAt,
+	}
+}
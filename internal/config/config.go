@@ -19,17 +19,64 @@ type ScraperConfig struct {
 	MaxPages     int    `mapstructure:"max_pages"`
 }
 
+// StageConfig beschreibt eine Stage der Prozessor-Chain
+type StageConfig struct {
+	Stage    string `mapstructure:"stage"`
+	OnError  string `mapstructure:"on_error"` // "abort", "skip" or "continue"
+	Fallback string `mapstructure:"fallback"` // stage that processes the job if this stage fails
+}
+
+// ProviderConfig beschreibt einen LLM-Provider des Routers. Leere Felder
+// werden aus der openai-Sektion übernommen.
+type ProviderConfig struct {
+	Name    string `mapstructure:"name"`
+	Type    string `mapstructure:"type"` // "openai" for any OpenAI-compatible API
+	APIURL  string `mapstructure:"api_url"`
+	APIKey  string `mapstructure:"api_key"`
+	Model   string `mapstructure:"model"`
+	Weight  int    `mapstructure:"weight"`  // share of jobs routed to this provider first
+	Metered bool   `mapstructure:"metered"` // counts against the LLM budget
+}
+
+// Storage-Backends
+const (
+	StorageMongoDB  = "mongodb"
+	StorageSQLite   = "sqlite"
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
 	API struct {
 		Port int
 	}
+	Storage struct {
+		Type string // StorageMongoDB, StorageSQLite, StoragePostgres or StorageMemory
+	}
+	Memory struct {
+		SnapshotPath string // JSON file the jobs are kept in across restarts, none if empty
+	}
+	Demo struct {
+		Enabled bool // in-memory storage with sample jobs, without scrapers and LLM calls
+	}
+	SQLite struct {
+		Path string // database file, created if missing
+	}
+	Postgres struct {
+		URI              string
+		MaxConns         int  // size of the connection pool, 0 uses the pgx default
+		MigrateOnStartup bool // apply pending schema migrations when the application starts
+	}
 	MongoDB struct {
-		URI      string
-		Database string
+		URI              string
+		Database         string
+		MigrateOnStartup bool // apply pending schema migrations when the application starts
 	}
 	Scrapers  map[string]*ScraperConfig
 	Processor struct {
-		Type string // "openai", "claude", "gpt4all", etc.
+		Type      string // "openai", "claude", "gpt4all", etc.
+		Chain     []StageConfig
+		Providers []ProviderConfig
 	}
 	OpenAI struct {
 		APIKey      string
@@ -42,6 +89,36 @@ type Config struct {
 		FreqPenalty float64
 		PresPenalty float64
 	}
+	Preprocessing struct {
+		MaxTokens int    // estimated tokens, 0 disables the limit
+		Overflow  string // "truncate" or "chunk"
+	}
+	ExtractionCache struct {
+		Enabled bool
+		TTL     time.Duration
+	}
+	Dedup struct {
+		Enabled   bool
+		Threshold float64       // minimum SimHash similarity of a near-duplicate
+		Window    time.Duration // how far back originals are searched, 0 searches all
+	}
+	Lifecycle struct {
+		Enabled      bool
+		Interval     time.Duration // time between two passes over the open jobs
+		MissingAfter time.Duration // jobs not seen by a scraper for this long are checked online
+		MaxChecks    int           // online checks per pass, 0 checks all
+		Timeout      time.Duration // of an online check
+	}
+	Budget struct {
+		Enabled              bool
+		DailyTokens          int64
+		MonthlyTokens        int64
+		DailyCost            float64
+		MonthlyCost          float64
+		PromptTokenPrice     float64 // USD per 1M prompt tokens
+		CompletionTokenPrice float64 // USD per 1M completion tokens
+		RetryInterval        time.Duration
+	}
 	Logging struct {
 		Level string
 		File  string
@@ -72,14 +149,30 @@ func LoadConfig() (*Config, error) {
 
 	config := &Config{}
 
+	// Storage configuration
+	viper.SetDefault("storage.type", StorageMongoDB)
+	config.Storage.Type = viper.GetString("storage.type")
+	config.SQLite.Path = viper.GetString("sqlite.path")
+	config.Memory.SnapshotPath = viper.GetString("memory.snapshot_path")
+	config.Postgres.URI = viper.GetString("postgres.uri")
+	config.Postgres.MaxConns = viper.GetInt("postgres.max_conns")
+	viper.SetDefault("postgres.migrate_on_startup", true)
+	config.Postgres.MigrateOnStartup = viper.GetBool("postgres.migrate_on_startup")
+
 	// MongoDB configuration
 	config.MongoDB.URI = viper.GetString("mongodb.uri")
 	config.MongoDB.Database = viper.GetString("mongodb.database")
+	viper.SetDefault("mongodb.migrate_on_startup", true)
+	config.MongoDB.MigrateOnStartup = viper.GetBool("mongodb.migrate_on_startup")
 
-	// Validiere MongoDB URI
-	if !strings.HasPrefix(config.MongoDB.URI, "mongodb://") &&
-		!strings.HasPrefix(config.MongoDB.URI, "mongodb+srv://") {
-		return nil, fmt.Errorf("invalid mongodb URI format")
+	// Demo mode
+	config.Demo.Enabled = viper.GetBool("demo.enabled")
+	if config.Demo.Enabled {
+		config.Storage.Type = StorageMemory
+	}
+
+	if err := validateStorage(config); err != nil {
+		return nil, err
 	}
 
 	// API configuration
@@ -93,6 +186,32 @@ func LoadConfig() (*Config, error) {
 	if config.Processor.Type == "" {
 		config.Processor.Type = "openai"
 	}
+	if err := viper.UnmarshalKey("processor.chain", &config.Processor.Chain); err != nil {
+		return nil, fmt.Errorf("invalid processor chain: %w", err)
+	}
+	if err := viper.UnmarshalKey("processor.providers", &config.Processor.Providers); err != nil {
+		return nil, fmt.Errorf("invalid processor providers: %w", err)
+	}
+	for i := range config.Processor.Providers {
+		provider := &config.Processor.Providers[i]
+		provider.APIURL = os.ExpandEnv(provider.APIURL)
+		provider.APIKey = os.ExpandEnv(provider.APIKey)
+		if provider.Type == "" {
+			provider.Type = "openai"
+		}
+	}
+	if len(config.Processor.Providers) == 0 {
+		config.Processor.Providers = []ProviderConfig{
+			{Name: config.Processor.Type, Type: config.Processor.Type, Weight: 100, Metered: true},
+		}
+	}
+	if len(config.Processor.Chain) == 0 {
+		config.Processor.Chain = []StageConfig{
+			{Stage: "clean", OnError: "continue"},
+			{Stage: "llm", OnError: "abort"},
+			{Stage: "parse_salary", OnError: "continue"},
+		}
+	}
 
 	// OpenAI configuration
 	config.OpenAI.APIKey = viper.GetString("openai.api_key")
@@ -105,6 +224,57 @@ func LoadConfig() (*Config, error) {
 	config.OpenAI.FreqPenalty = viper.GetFloat64("openai.frequency_penalty")
 	config.OpenAI.PresPenalty = viper.GetFloat64("openai.presence_penalty")
 
+	// Preprocessing configuration
+	config.Preprocessing.MaxTokens = viper.GetInt("preprocessing.max_tokens")
+	config.Preprocessing.Overflow = viper.GetString("preprocessing.overflow")
+	if config.Preprocessing.Overflow == "" {
+		config.Preprocessing.Overflow = "truncate"
+	}
+	if config.Preprocessing.Overflow != "truncate" && config.Preprocessing.Overflow != "chunk" {
+		return nil, fmt.Errorf("invalid preprocessing.overflow: %s", config.Preprocessing.Overflow)
+	}
+
+	// Extraction cache configuration
+	config.ExtractionCache.Enabled = viper.GetBool("extraction_cache.enabled")
+	config.ExtractionCache.TTL = viper.GetDuration("extraction_cache.ttl")
+
+	// Near-duplicate detection configuration
+	config.Dedup.Enabled = viper.GetBool("dedup.enabled")
+	config.Dedup.Threshold = viper.GetFloat64("dedup.threshold")
+	if config.Dedup.Threshold == 0 {
+		config.Dedup.Threshold = 0.9
+	}
+	if config.Dedup.Threshold < 0 || config.Dedup.Threshold > 1 {
+		return nil, fmt.Errorf("invalid dedup.threshold: %v", config.Dedup.Threshold)
+	}
+	config.Dedup.Window = viper.GetDuration("dedup.window")
+
+	// Job lifecycle configuration
+	config.Lifecycle.Enabled = viper.GetBool("lifecycle.enabled")
+	viper.SetDefault("lifecycle.interval", 6*time.Hour)
+	config.Lifecycle.Interval = viper.GetDuration("lifecycle.interval")
+	viper.SetDefault("lifecycle.missing_after", 48*time.Hour)
+	config.Lifecycle.MissingAfter = viper.GetDuration("lifecycle.missing_after")
+	config.Lifecycle.MaxChecks = viper.GetInt("lifecycle.max_checks")
+	viper.SetDefault("lifecycle.timeout", 10*time.Second)
+	config.Lifecycle.Timeout = viper.GetDuration("lifecycle.timeout")
+	if config.Lifecycle.Interval <= 0 || config.Lifecycle.MissingAfter < 0 || config.Lifecycle.MaxChecks < 0 {
+		return nil, fmt.Errorf("invalid lifecycle configuration")
+	}
+
+	// Budget configuration
+	config.Budget.Enabled = viper.GetBool("budget.enabled")
+	config.Budget.DailyTokens = viper.GetInt64("budget.daily_tokens")
+	config.Budget.MonthlyTokens = viper.GetInt64("budget.monthly_tokens")
+	config.Budget.DailyCost = viper.GetFloat64("budget.daily_cost")
+	config.Budget.MonthlyCost = viper.GetFloat64("budget.monthly_cost")
+	config.Budget.PromptTokenPrice = viper.GetFloat64("budget.prompt_token_price")
+	config.Budget.CompletionTokenPrice = viper.GetFloat64("budget.completion_token_price")
+	config.Budget.RetryInterval = viper.GetDuration("budget.retry_interval")
+	if config.Budget.RetryInterval <= 0 {
+		config.Budget.RetryInterval = time.Minute
+	}
+
 	// Logging configuration
 	config.Logging.Level = viper.GetString("logging.level")
 	config.Logging.File = viper.GetString("logging.file")
@@ -159,9 +329,30 @@ func LoadConfig() (*Config, error) {
 		config.Scrapers[scraperName] = cfg
 	}
 
+	if config.Demo.Enabled {
+		applyDemoMode(config)
+	}
+
 	return config, nil
 }
 
+// applyDemoMode schaltet alles ab, was externe Dienste braucht: Die Scraper laufen
+// nicht, Jobs werden mit Regeln statt mit dem LLM extrahiert, nichts wird gecacht
+// und die Inserate werden nicht online geprüft
+func applyDemoMode(config *Config) {
+	config.Scrapers = make(map[string]*ScraperConfig)
+	for i := range config.Processor.Chain {
+		stage := &config.Processor.Chain[i]
+		if stage.Stage == "llm" {
+			stage.Stage = "rules"
+			stage.Fallback = ""
+		}
+	}
+	config.ExtractionCache.Enabled = false
+	config.Budget.Enabled = false
+	config.Lifecycle.Enabled = false
+}
+
 func loadScraperConfig(config *Config) error {
 	config.Scrapers = make(map[string]*ScraperConfig)
 
@@ -197,10 +388,8 @@ func loadScraperConfig(config *Config) error {
 }
 
 func validateConfig(config *Config) error {
-	// Validiere MongoDB URI Format
-	if !strings.HasPrefix(config.MongoDB.URI, "mongodb://") &&
-		!strings.HasPrefix(config.MongoDB.URI, "mongodb+srv://") {
-		return fmt.Errorf("invalid mongodb URI format")
+	if err := validateStorage(config); err != nil {
+		return err
 	}
 
 	// Validiere Port Ranges
@@ -284,3 +473,31 @@ type RequiredConfigError struct {
 func (e *RequiredConfigError) Error() string {
 	return fmt.Sprintf("required configuration field missing: %s", e.Field)
 }
+
+// validateStorage prüft die Einstellungen des gewählten Storage-Backends
+func validateStorage(config *Config) error {
+	switch config.Storage.Type {
+	case StorageMongoDB:
+		// Validiere MongoDB URI Format
+		if !strings.HasPrefix(config.MongoDB.URI, "mongodb://") &&
+			!strings.HasPrefix(config.MongoDB.URI, "mongodb+srv://") {
+			return fmt.Errorf("invalid mongodb URI format")
+		}
+	case StorageSQLite:
+		if strings.TrimSpace(config.SQLite.Path) == "" {
+			return fmt.Errorf("sqlite.path is required for sqlite storage")
+		}
+	case StoragePostgres:
+		if !strings.HasPrefix(config.Postgres.URI, "postgres://") &&
+			!strings.HasPrefix(config.Postgres.URI, "postgresql://") {
+			return fmt.Errorf("invalid postgres URI format")
+		}
+		if config.Postgres.MaxConns < 0 {
+			return fmt.Errorf("postgres.max_conns must not be negative")
+		}
+	case StorageMemory:
+	default:
+		return fmt.Errorf("unknown storage type: %s", config.Storage.Type)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const description = `Für unseren Kunden, eine Privatbank in Zürich, suchen wir per sofort oder nach Vereinbarung
+einen erfahrenen Senior Java Developer (80-100%). Sie entwickeln und betreiben Backend-Services mit
+Java 17, Spring Boot und Kafka, arbeiten eng mit dem Product Owner zusammen und übernehmen Verantwortung
+für Architekturentscheide. Sie bringen mehrere Jahre Erfahrung in der Softwareentwicklung mit, kennen
+Docker und Kubernetes und sprechen fliessend Deutsch und gut Englisch. Wir bieten flexible Arbeitszeiten,
+Homeoffice und ein modernes Büro an zentraler Lage.`
+
+func TestComputeNearDuplicates(t *testing.T) {
+	original := Compute("Senior Java Developer (80-100%)", "Muster Consulting AG", description)
+
+	repost := Compute("Senior Java Developer 80 - 100 %", "MUSTER CONSULTING AG", description+"\nJetzt bewerben!")
+	assert.GreaterOrEqual(t, original.Similarity(repost), DefaultThreshold)
+
+	other := Compute("Pflegefachperson HF", "Spital Bern", `Das Spital sucht eine diplomierte Pflegefachperson HF
+für die Station Innere Medizin. Sie betreuen Patientinnen und Patienten ganzheitlich, arbeiten im
+Schichtbetrieb und bringen Berufserfahrung in der Akutpflege mit. Wir bieten Weiterbildungen und gute
+Sozialleistungen.`)
+	assert.Less(t, original.Similarity(other), DefaultThreshold)
+}
+
+func TestParseFingerprint(t *testing.T) {
+	fingerprint := Compute("Go Developer", "Example AG", description)
+
+	parsed, err := ParseFingerprint(fingerprint.String())
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, parsed)
+	assert.Len(t, fingerprint.String(), 16)
+
+	_, err = ParseFingerprint("not a fingerprint")
+	assert.Error(t, err)
+}
+
+func TestIndexMatch(t *testing.T) {
+	index, err := NewIndex(0.9, 30*24*time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now()
+	original := primitive.NewObjectID()
+	index.Add(original, Fingerprint(0xffff0000ffff0000), now.Add(-24*time.Hour))
+	index.Add(primitive.NewObjectID(), Fingerprint(0x0000ffff0000ffff), now.Add(-60*24*time.Hour))
+
+	id, similarity, ok := index.Match(Fingerprint(0xffff0000ffff0003), now)
+	assert.True(t, ok)
+	assert.Equal(t, original, id)
+	assert.InDelta(t, 62.0/64, similarity, 1e-9)
+
+	// Das zweite Original liegt ausserhalb des Zeitfensters
+	_, _, ok = index.Match(Fingerprint(0x0000ffff0000ffff), now)
+	assert.False(t, ok)
+
+	_, err = NewIndex(1.5, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package dedup
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultThreshold is the minimum similarity of a duplicate if none is configured
+const DefaultThreshold = 0.9
+
+type entry struct {
+	id          primitive.ObjectID
+	fingerprint Fingerprint
+	seenAt      time.Time
+}
+
+// Index holds the fingerprints of the original postings and finds near-duplicates
+// of new ones. Only originals are added, so duplicates always link to the first posting.
+type Index struct {
+	threshold float64
+	window    time.Duration
+
+	mu      sync.RWMutex
+	entries []entry
+}
+
+// NewIndex creates an index that reports postings with at least the given similarity
+// as duplicates. With a window > 0 only originals seen within the window are considered.
+func NewIndex(threshold float64, window time.Duration) (*Index, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("invalid similarity threshold %v, must be in (0, 1]", threshold)
+	}
+	return &Index{threshold: threshold, window: window}, nil
+}
+
+// Add registers the fingerprint of an original posting
+func (i *Index) Add(id primitive.ObjectID, fingerprint Fingerprint, seenAt time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.entries = append(i.entries, entry{id: id, fingerprint: fingerprint, seenAt: seenAt})
+}
+
+// Match returns the most similar original of a posting seen at the given time.
+// It reports false if no original reaches the threshold.
+func (i *Index) Match(fingerprint Fingerprint, at time.Time) (primitive.ObjectID, float64, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var best entry
+	bestSimilarity := 0.0
+	for _, e := range i.entries {
+		if i.window > 0 && (at.Sub(e.seenAt) > i.window || e.seenAt.After(at)) {
+			continue
+		}
+		if similarity := fingerprint.Similarity(e.fingerprint); similarity > bestSimilarity {
+			best, bestSimilarity = e, similarity
+		}
+	}
+
+	if bestSimilarity < i.threshold {
+		return primitive.NilObjectID, bestSimilarity, false
+	}
+	return best.id, bestSimilarity, true
+}
+
+// Len returns the number of originals in the index
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.entries)
+}
@@ -0,0 +1,100 @@
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// shingleSize ist die Anzahl Wörter pro Merkmal des SimHash
+const shingleSize = 3
+
+var folder = strings.NewReplacer(
+	"ä", "a", "à", "a", "â", "a", "á", "a",
+	"ö", "o", "ô", "o", "ó", "o",
+	"ü", "u", "û", "u", "ù", "u",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i", "ç", "c", "ß", "ss",
+)
+
+// Fingerprint is the 64 bit SimHash of a posting. Similar postings have
+// fingerprints that differ in few bits.
+type Fingerprint uint64
+
+// String returns the fingerprint as 16 hex digits, the form stored with the job
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%016x", uint64(f))
+}
+
+// ParseFingerprint parses a fingerprint stored with a job
+func ParseFingerprint(s string) (Fingerprint, error) {
+	value, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fingerprint %q: %w", s, err)
+	}
+	return Fingerprint(value), nil
+}
+
+// Similarity returns the share of equal bits, 1 for identical fingerprints
+func (f Fingerprint) Similarity(other Fingerprint) float64 {
+	return 1 - float64(bits.OnesCount64(uint64(f^other)))/64
+}
+
+// Compute returns the SimHash of title, company and description. The texts are
+// normalized first, so case, diacritics, punctuation and whitespace do not matter.
+func Compute(title, company, description string) Fingerprint {
+	var weights [64]int
+	for _, text := range []string{title, company, description} {
+		for _, feature := range shingles(normalize(text)) {
+			h := hash(feature)
+			for bit := 0; bit < 64; bit++ {
+				if h&(1<<bit) != 0 {
+					weights[bit]++
+				} else {
+					weights[bit]--
+				}
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return Fingerprint(fingerprint)
+}
+
+// normalize returns the lowercase words of a text without diacritics and punctuation
+func normalize(text string) []string {
+	return strings.FieldsFunc(folder.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// shingles returns the overlapping word sequences of a text. Short texts like
+// titles are used as a whole.
+func shingles(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+
+	result := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		result = append(result, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return result
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,71 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"job-scraper/internal/models"
+)
+
+// Case ist ein handannotiertes Beispiel des Golden-Datasets
+type Case struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Expected    models.Job `json:"-"`
+
+	// labelled contains the JSON names of the fields that were annotated
+	labelled map[string]bool
+}
+
+func (c *Case) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID          string          `json:"id"`
+		Description string          `json:"description"`
+		Expected    json.RawMessage `json:"expected"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Expected, &fields); err != nil {
+		return fmt.Errorf("case %s: invalid expected fields: %w", raw.ID, err)
+	}
+
+	var expected models.Job
+	if err := json.Unmarshal(raw.Expected, &expected); err != nil {
+		return fmt.Errorf("case %s: invalid expected fields: %w", raw.ID, err)
+	}
+
+	c.ID = raw.ID
+	c.Description = raw.Description
+	c.Expected = expected
+	c.labelled = make(map[string]bool, len(fields))
+	for name := range fields {
+		c.labelled[name] = true
+	}
+	return nil
+}
+
+// IsLabelled reports whether the given field was annotated in the golden dataset
+func (c *Case) IsLabelled(field string) bool {
+	return c.labelled[field]
+}
+
+// LoadDataset reads a golden dataset from a JSON file containing an array of cases
+func LoadDataset(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading dataset: %w", err)
+	}
+
+	var cases []Case
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("error parsing dataset: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("dataset %s contains no cases", path)
+	}
+	return cases, nil
+}
@@ -0,0 +1,307 @@
+package evaluation
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/salary"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Report contains the results of an evaluation run. All maps are serialized with
+// sorted keys, so two reports can be compared with a plain diff.
+type Report struct {
+	GeneratedAt       time.Time             `json:"generatedAt"`
+	Label             string                `json:"label,omitempty"`
+	PromptVersion     string                `json:"promptVersion,omitempty"`
+	Cases             int                   `json:"cases"`
+	Errors            int                   `json:"errors"`
+	ParseFailures     int                   `json:"parseFailures"`
+	ErrorRate         float64               `json:"errorRate"`
+	ParseFailureRate  float64               `json:"parseFailureRate"`
+	Fields            map[string]FieldScore `json:"fields"`
+	Categories        SetScore              `json:"categories"`
+	CategoryBreakdown map[string]SetScore   `json:"categoryBreakdown"`
+	MustSkills        SetScore              `json:"mustSkills"`
+	OptionalSkills    SetScore              `json:"optionalSkills"`
+	Skills            SetScore              `json:"skills"`
+	Results           []CaseResult          `json:"results"`
+}
+
+// FieldScore is the accuracy of a single scalar field over all cases in which it was labelled
+type FieldScore struct {
+	Labelled int     `json:"labelled"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// SetScore contains micro-averaged precision, recall and F1 of a set-valued field
+type SetScore struct {
+	TruePositives  int     `json:"truePositives"`
+	FalsePositives int     `json:"falsePositives"`
+	FalseNegatives int     `json:"falseNegatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// CaseResult describes the outcome of a single case
+type CaseResult struct {
+	ID           string   `json:"id"`
+	Error        string   `json:"error,omitempty"`
+	ParseFailure bool     `json:"parseFailure,omitempty"`
+	Mismatches   []string `json:"mismatches,omitempty"`
+}
+
+type fieldSpec struct {
+	name  string
+	equal func(expected, actual models.Job) bool
+}
+
+var scalarFields = []fieldSpec{
+	{"title", stringField(func(j models.Job) string { return j.Title })},
+	{"company", stringField(func(j models.Job) string { return j.Company })},
+	{"location", stringField(func(j models.Job) string { return j.Location })},
+	{"employmentType", stringField(func(j models.Job) string { return j.EmploymentType })},
+	{"educationLevel", stringField(func(j models.Job) string { return j.EducationLevel })},
+	{"salary", salaryField},
+	{"workCulture", stringField(func(j models.Job) string { return j.WorkCulture })},
+	{"postingDate", dateField(func(j models.Job) time.Time { return j.PostingDate })},
+	{"expirationDate", dateField(func(j models.Job) time.Time { return j.ExpirationDate })},
+	{"yearsOfExperience", func(e, a models.Job) bool { return e.YearsOfExperience == a.YearsOfExperience }},
+	{"companySize", func(e, a models.Job) bool { return e.CompanySize == a.CompanySize }},
+	{"remote", func(e, a models.Job) bool { return e.Remote == a.Remote }},
+	{"isActive", func(e, a models.Job) bool { return e.IsActive == a.IsActive }},
+	{"languages", func(e, a models.Job) bool {
+		_, fp, fn := compareSets(e.Languages, a.Languages)
+		return fp == 0 && fn == 0
+	}},
+}
+
+// Run processes every case with the given processor and scores the results
+func Run(ctx context.Context, p processor.JobProcessor, cases []Case, label string) (*Report, error) {
+	report := &Report{
+		GeneratedAt:       time.Now().UTC(),
+		Label:             label,
+		Cases:             len(cases),
+		Fields:            make(map[string]FieldScore),
+		CategoryBreakdown: make(map[string]SetScore),
+	}
+
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		actual, err := p.Process(ctx, models.Job{URL: "evaluation://" + c.ID, Description: c.Description})
+		result := CaseResult{ID: c.ID}
+		if err != nil {
+			log.Warn().Err(err).Str("case", c.ID).Msg("Evaluation case failed")
+			result.Error = err.Error()
+			result.ParseFailure = isParseFailure(err)
+			report.Errors++
+			if result.ParseFailure {
+				report.ParseFailures++
+			}
+			// A failed case scores as if nothing was extracted
+			actual = models.Job{}
+		} else if report.PromptVersion == "" {
+			report.PromptVersion = actual.PromptVersion
+		}
+
+		result.Mismatches = report.score(c, actual)
+		report.Results = append(report.Results, result)
+	}
+
+	report.finalize()
+	return report, nil
+}
+
+func (r *Report) score(c Case, actual models.Job) []string {
+	var mismatches []string
+
+	for _, field := range scalarFields {
+		if !c.IsLabelled(field.name) {
+			continue
+		}
+		score := r.Fields[field.name]
+		score.Labelled++
+		if field.equal(c.Expected, actual) {
+			score.Correct++
+		} else {
+			mismatches = append(mismatches, field.name)
+		}
+		r.Fields[field.name] = score
+	}
+
+	if c.IsLabelled("jobCategories") {
+		r.Categories.add(compareSets(c.Expected.JobCategories, actual.JobCategories))
+		for category, counts := range perItem(c.Expected.JobCategories, actual.JobCategories) {
+			score := r.CategoryBreakdown[category]
+			score.add(counts[0], counts[1], counts[2])
+			r.CategoryBreakdown[category] = score
+		}
+	}
+	if c.IsLabelled("mustSkills") {
+		r.MustSkills.add(compareSets(c.Expected.MustSkills, actual.MustSkills))
+	}
+	if c.IsLabelled("optionalSkills") {
+		r.OptionalSkills.add(compareSets(c.Expected.OptionalSkills, actual.OptionalSkills))
+	}
+	if c.IsLabelled("mustSkills") || c.IsLabelled("optionalSkills") {
+		expected := append(append([]string{}, c.Expected.MustSkills...), c.Expected.OptionalSkills...)
+		extracted := append(append([]string{}, actual.MustSkills...), actual.OptionalSkills...)
+		r.Skills.add(compareSets(expected, extracted))
+	}
+
+	return mismatches
+}
+
+func (r *Report) finalize() {
+	if r.Cases > 0 {
+		r.ErrorRate = float64(r.Errors) / float64(r.Cases)
+		r.ParseFailureRate = float64(r.ParseFailures) / float64(r.Cases)
+	}
+
+	for name, score := range r.Fields {
+		if score.Labelled > 0 {
+			score.Accuracy = float64(score.Correct) / float64(score.Labelled)
+		}
+		r.Fields[name] = score
+	}
+
+	r.Categories.finalize()
+	r.MustSkills.finalize()
+	r.OptionalSkills.finalize()
+	r.Skills.finalize()
+	for category, score := range r.CategoryBreakdown {
+		score.finalize()
+		r.CategoryBreakdown[category] = score
+	}
+}
+
+func (s *SetScore) add(tp, fp, fn int) {
+	s.TruePositives += tp
+	s.FalsePositives += fp
+	s.FalseNegatives += fn
+}
+
+func (s *SetScore) finalize() {
+	if s.TruePositives+s.FalsePositives > 0 {
+		s.Precision = float64(s.TruePositives) / float64(s.TruePositives+s.FalsePositives)
+	}
+	if s.TruePositives+s.FalseNegatives > 0 {
+		s.Recall = float64(s.TruePositives) / float64(s.TruePositives+s.FalseNegatives)
+	}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+}
+
+// isParseFailure reports whether the processor failed because the model output could not be parsed
+func isParseFailure(err error) bool {
+	return apperrors.HasCode(err, apperrors.ErrCodeParser)
+}
+
+func compareSets(expected, actual []string) (tp, fp, fn int) {
+	expectedSet := normalizeSet(expected)
+	actualSet := normalizeSet(actual)
+
+	for item := range actualSet {
+		if expectedSet[item] {
+			tp++
+		} else {
+			fp++
+		}
+	}
+	for item := range expectedSet {
+		if !actualSet[item] {
+			fn++
+		}
+	}
+	return tp, fp, fn
+}
+
+// perItem returns the tp/fp/fn counts per item
+func perItem(expected, actual []string) map[string][3]int {
+	expectedSet := normalizeSet(expected)
+	actualSet := normalizeSet(actual)
+
+	counts := make(map[string][3]int)
+	for item := range actualSet {
+		c := counts[item]
+		if expectedSet[item] {
+			c[0]++
+		} else {
+			c[1]++
+		}
+		counts[item] = c
+	}
+	for item := range expectedSet {
+		if !actualSet[item] {
+			c := counts[item]
+			c[2]++
+			counts[item] = c
+		}
+	}
+	return counts
+}
+
+func normalizeSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if normalized := normalize(item); normalized != "" {
+			set[normalized] = true
+		}
+	}
+	return set
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func stringField(get func(models.Job) string) func(expected, actual models.Job) bool {
+	return func(expected, actual models.Job) bool {
+		return normalize(get(expected)) == normalize(get(actual))
+	}
+}
+
+// salaryField compares the parsed amounts, so "CHF 100'000 - 120'000" matches "100-120k"
+func salaryField(expected, actual models.Job) bool {
+	e, a := structuredSalary(expected.Salary), structuredSalary(actual.Salary)
+	if e == nil || a == nil {
+		return e == nil && a == nil
+	}
+	return e.Min == a.Min && e.Max == a.Max && e.Currency == a.Currency && e.Period == a.Period
+}
+
+// structuredSalary parses salaries that are only given as text, e.g. in the golden dataset
+func structuredSalary(s *models.Salary) *models.Salary {
+	if s == nil || s.IsStructured() {
+		return s
+	}
+	return salary.FromText(s.Text)
+}
+
+func dateField(get func(models.Job) time.Time) func(expected, actual models.Job) bool {
+	return func(expected, actual models.Job) bool {
+		return get(expected).UTC().Format("2006-01-02") == get(actual).UTC().Format("2006-01-02")
+	}
+}
+
+// SortedFieldNames returns the names of the scored fields in a stable order
+func (r *Report) SortedFieldNames() []string {
+	names := make([]string, 0, len(r.Fields))
+	for name := range r.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,88 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProcessor struct {
+	results map[string]models.Job
+}
+
+func (p *fakeProcessor) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	result, ok := p.results[job.Description]
+	if !ok {
+		return job, apperrors.NewProcessingError("", "failed to process job",
+			apperrors.NewBaseError(apperrors.ErrCodeParser, "Failed to parse job data", nil))
+	}
+	return result, nil
+}
+
+func TestRun(t *testing.T) {
+	var cases []Case
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "a", "description": "first", "expected": {
+			"title": "Go Developer", "remote": true,
+			"jobCategories": ["BACKEND_DEVELOPER"],
+			"mustSkills": ["Go", "SQL"], "optionalSkills": ["Kafka"]}},
+		{"id": "b", "description": "second", "expected": {
+			"title": "Data Engineer", "jobCategories": ["DATA_ENGINEER"]}}
+	]`), &cases))
+
+	processor := &fakeProcessor{results: map[string]models.Job{
+		"first": {
+			Title:          " go developer ",
+			Remote:         false,
+			JobCategories:  []string{"BACKEND_DEVELOPER", "DEVOPS_ENGINEER"},
+			MustSkills:     []string{"Go"},
+			OptionalSkills: []string{"kafka", "Docker"},
+			PromptVersion:  "2",
+		},
+	}}
+
+	report, err := Run(context.Background(), processor, cases, "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Cases)
+	assert.Equal(t, 1, report.ParseFailures)
+	assert.Equal(t, 0.5, report.ParseFailureRate)
+	assert.Equal(t, "2", report.PromptVersion)
+
+	assert.Equal(t, FieldScore{Labelled: 2, Correct: 1, Accuracy: 0.5}, report.Fields["title"])
+	assert.Equal(t, FieldScore{Labelled: 1, Correct: 0, Accuracy: 0}, report.Fields["remote"])
+	assert.NotContains(t, report.Fields, "company")
+
+	// categories: tp=1 (BACKEND), fp=1 (DEVOPS), fn=1 (DATA_ENGINEER of the failed case)
+	assert.Equal(t, 1, report.Categories.TruePositives)
+	assert.InDelta(t, 0.5, report.Categories.Precision, 1e-9)
+	assert.InDelta(t, 0.5, report.Categories.Recall, 1e-9)
+	assert.Equal(t, 1, report.CategoryBreakdown["devops_engineer"].FalsePositives)
+
+	// skills: expected {go, sql, kafka}, extracted {go, kafka, docker}
+	assert.InDelta(t, 2.0/3.0, report.Skills.Precision, 1e-9)
+	assert.InDelta(t, 2.0/3.0, report.Skills.Recall, 1e-9)
+	assert.InDelta(t, 2.0/3.0, report.Skills.F1, 1e-9)
+
+	assert.Equal(t, []string{"remote"}, report.Results[0].Mismatches)
+	assert.True(t, report.Results[1].ParseFailure)
+}
+
+func TestLoadDataset(t *testing.T) {
+	cases, err := LoadDataset("../../testdata/evaluation/golden.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	for _, c := range cases {
+		assert.NotEmpty(t, c.ID)
+		assert.NotEmpty(t, c.Description)
+		assert.True(t, c.IsLabelled("jobCategories"), c.ID)
+		assert.NoError(t, models.ValidateJobCategories(c.Expected.JobCategories), c.ID)
+	}
+}
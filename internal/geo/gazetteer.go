@@ -0,0 +1,301 @@
+package geo
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"job-scraper/internal/models"
+)
+
+// CountrySwitzerland is the ISO code stored for places found in the gazetteer
+const CountrySwitzerland = "CH"
+
+// maxNameWords ist die maximale Anzahl Wörter eines Ortsnamens
+const maxNameWords = 4
+
+//go:embed data/*.csv
+var dataFiles embed.FS
+
+var (
+	postalCodeRe = regexp.MustCompile(`\b([1-9]\d{3})\b`)
+	separatorRe  = regexp.MustCompile(`[/;|,+\n]|\s(?:&|und|and|et|oder|or|sowie)\s|\s[-–]\s`)
+	cantonRe     = regexp.MustCompile(`^(?:kanton|kt\.?|canton(?: de| du)?|cantone)\s+(.+)$`)
+	noiseRe      = regexp.MustCompile(`\b(?:remote|home ?office|homeoffice|hybrid|mobile[ -]?office|télétravail|schweiz|switzerland|suisse|svizzera|ch|region|raum|area|umgebung|grossraum|greater|bei|near|oder|or|und|and)\b`)
+	folder       = strings.NewReplacer(
+		"ä", "a", "à", "a", "â", "a", "á", "a",
+		"ö", "o", "ô", "o", "ó", "o",
+		"ü", "u", "û", "u", "ù", "u",
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"î", "i", "ï", "i", "ç", "c",
+		".", " ", "-", " ", "'", " ", "’", " ", "(", " ", ")", " ",
+	)
+	transliterator = strings.NewReplacer("ae", "a", "oe", "o", "ue", "u")
+)
+
+type municipality struct {
+	place       models.Place
+	postalCodes [][2]int
+}
+
+type canton struct {
+	code  string
+	place models.Place
+}
+
+// Gazetteer resolves free-text locations to Swiss municipalities and cantons.
+// The data is bundled with the binary, no network access is needed.
+type Gazetteer struct {
+	municipalities []*municipality
+	byName         map[string]*municipality
+	cantons        map[string]*canton
+	cantonNames    map[string]*canton
+}
+
+// Load reads the bundled dataset of municipalities and cantons
+func Load() (*Gazetteer, error) {
+	g := &Gazetteer{
+		byName:      make(map[string]*municipality),
+		cantons:     make(map[string]*canton),
+		cantonNames: make(map[string]*canton),
+	}
+
+	cantonRows, err := readCSV("data/cantons.csv")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range cantonRows {
+		lat, lon, err := parseCoordinates(row[2], row[3])
+		if err != nil {
+			return nil, fmt.Errorf("canton %s: %w", row[0], err)
+		}
+		c := &canton{
+			code:  row[0],
+			place: models.Place{Canton: row[0], Country: CountrySwitzerland, Lat: lat, Lon: lon},
+		}
+		g.cantons[row[0]] = c
+		for _, name := range strings.Split(row[1], "|") {
+			g.cantonNames[key(name)] = c
+		}
+	}
+
+	rows, err := readCSV("data/municipalities.csv")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if _, ok := g.cantons[row[3]]; !ok {
+			return nil, fmt.Errorf("municipality %s: unknown canton %s", row[0], row[3])
+		}
+		lat, lon, err := parseCoordinates(row[4], row[5])
+		if err != nil {
+			return nil, fmt.Errorf("municipality %s: %w", row[0], err)
+		}
+		postalCodes, err := parsePostalCodes(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("municipality %s: %w", row[0], err)
+		}
+
+		m := &municipality{
+			place:       models.Place{City: row[0], Canton: row[3], Country: CountrySwitzerland, Lat: lat, Lon: lon},
+			postalCodes: postalCodes,
+		}
+		g.municipalities = append(g.municipalities, m)
+
+		names := []string{row[0]}
+		if row[1] != "" {
+			names = append(names, strings.Split(row[1], "|")...)
+		}
+		for _, name := range names {
+			k := key(name)
+			if other, exists := g.byName[k]; exists && other != m {
+				return nil, fmt.Errorf("name %q of %s is already used by %s", name, row[0], other.place.City)
+			}
+			g.byName[k] = m
+		}
+	}
+
+	return g, nil
+}
+
+// Resolve returns the places named in a location text like "8001 Zürich / Remote"
+// or "Bern, Basel oder Lausanne". Parts that cannot be resolved are ignored.
+func (g *Gazetteer) Resolve(text string) []models.Place {
+	var places []models.Place
+	seen := make(map[string]bool)
+
+	for _, part := range separatorRe.Split(text, -1) {
+		place, ok := g.resolvePart(part)
+		if !ok {
+			continue
+		}
+		id := place.City + "|" + place.Canton
+		if !seen[id] {
+			seen[id] = true
+			places = append(places, place)
+		}
+	}
+	return places
+}
+
+// Canton returns the canton for a code like "ZH" or a name like "Waadt"
+func (g *Gazetteer) Canton(name string) (models.Place, bool) {
+	if c, ok := g.cantons[strings.ToUpper(strings.TrimSpace(name))]; ok {
+		return c.place, true
+	}
+	if c, ok := g.cantonNames[key(name)]; ok {
+		return c.place, true
+	}
+	return models.Place{}, false
+}
+
+func (g *Gazetteer) resolvePart(part string) (models.Place, bool) {
+	part = strings.TrimSpace(part)
+	if part == "" {
+		return models.Place{}, false
+	}
+
+	// Kantonskürzel wie "ZH" oder "Kanton Zürich"
+	if c, ok := g.cantons[part]; ok {
+		return c.place, true
+	}
+	if m := cantonRe.FindStringSubmatch(strings.ToLower(part)); m != nil {
+		return g.Canton(m[1])
+	}
+
+	var byPostalCode *municipality
+	postalCode := postalCodeRe.FindString(part)
+	if postalCode != "" {
+		byPostalCode = g.lookupPostalCode(postalCode)
+	}
+
+	name := postalCodeRe.ReplaceAllString(part, " ")
+	if m := g.lookupName(name); m != nil {
+		place := m.place
+		if postalCode != "" && m.hasPostalCode(postalCode) {
+			place.PostalCode = postalCode
+		}
+		return place, true
+	}
+	if byPostalCode != nil {
+		place := byPostalCode.place
+		place.PostalCode = postalCode
+		return place, true
+	}
+
+	if c, ok := g.cantonNames[cleanKey(name)]; ok {
+		return c.place, true
+	}
+	return models.Place{}, false
+}
+
+// lookupName tries the whole text first and then the longest word sequences,
+// so "Zürich Oerlikon" and "Raum Bern" both resolve
+func (g *Gazetteer) lookupName(text string) *municipality {
+	for _, k := range []string{key(text), cleanKey(text), transliterator.Replace(cleanKey(text))} {
+		if m, ok := g.byName[k]; ok {
+			return m
+		}
+	}
+
+	words := strings.Fields(cleanKey(text))
+	for size := min(maxNameWords, len(words)); size > 0; size-- {
+		for start := 0; start+size <= len(words); start++ {
+			candidate := strings.Join(words[start:start+size], " ")
+			if m, ok := g.byName[candidate]; ok {
+				return m
+			}
+			if m, ok := g.byName[transliterator.Replace(candidate)]; ok {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Gazetteer) lookupPostalCode(code string) *municipality {
+	for _, m := range g.municipalities {
+		if m.hasPostalCode(code) {
+			return m
+		}
+	}
+	return nil
+}
+
+func (m *municipality) hasPostalCode(code string) bool {
+	value, err := strconv.Atoi(code)
+	if err != nil {
+		return false
+	}
+	for _, r := range m.postalCodes {
+		if value >= r[0] && value <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// key normalizes a name for lookups: lowercase, without diacritics and punctuation
+func key(name string) string {
+	return strings.Join(strings.Fields(folder.Replace(strings.ToLower(name))), " ")
+}
+
+// cleanKey additionally removes words like "Remote" or "Schweiz"
+func cleanKey(text string) string {
+	return strings.Join(strings.Fields(noiseRe.ReplaceAllString(key(text), " ")), " ")
+}
+
+func readCSV(name string) ([][]string, error) {
+	file, err := dataFiles.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading gazetteer data: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("%s contains no data", name)
+	}
+	return rows[1:], nil
+}
+
+func parseCoordinates(lat, lon string) (float64, float64, error) {
+	latValue, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude %q", lat)
+	}
+	lonValue, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude %q", lon)
+	}
+	return latValue, lonValue, nil
+}
+
+// parsePostalCodes parses lists like "1000-1007|1010"
+func parsePostalCodes(value string) ([][2]int, error) {
+	var ranges [][2]int
+	for _, part := range strings.Split(value, "|") {
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		fromValue, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid postal code %q", part)
+		}
+		toValue, err := strconv.Atoi(to)
+		if err != nil || toValue < fromValue {
+			return nil, fmt.Errorf("invalid postal code range %q", part)
+		}
+		ranges = append(ranges, [2]int{fromValue, toValue})
+	}
+	return ranges, nil
+}
@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	g, err := Load()
+	require.NoError(t, err)
+
+	tests := []struct {
+		text    string
+		cities  []string
+		cantons []string
+	}{
+		{"Zürich", []string{"Zürich"}, []string{"ZH"}},
+		{"Zurich, CH", []string{"Zürich"}, []string{"ZH"}},
+		{"8001 Zürich / Remote", []string{"Zürich"}, []string{"ZH"}},
+		{"Zuerich", []string{"Zürich"}, []string{"ZH"}},
+		{"Bern, Basel oder Lausanne", []string{"Bern", "Basel", "Lausanne"}, []string{"BE", "BS", "VD"}},
+		{"Genf", []string{"Genève"}, []string{"GE"}},
+		{"St.Gallen", []string{"St. Gallen"}, []string{"SG"}},
+		{"Muri bei Bern", []string{"Muri bei Bern"}, []string{"BE"}},
+		{"Zürich Oerlikon und Winterthur", []string{"Zürich", "Winterthur"}, []string{"ZH", "ZH"}},
+		{"Kanton Aargau", []string{""}, []string{"AG"}},
+		{"Grossraum Zug", []string{"Zug"}, []string{"ZG"}},
+		{"Homeoffice", nil, nil},
+		{"München, Deutschland", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			places := g.Resolve(tt.text)
+
+			var cities, cantons []string
+			for _, place := range places {
+				cities = append(cities, place.City)
+				cantons = append(cantons, place.Canton)
+				assert.Equal(t, CountrySwitzerland, place.Country)
+				assert.NotZero(t, place.Lat)
+				assert.NotZero(t, place.Lon)
+			}
+			assert.Equal(t, tt.cities, cities)
+			assert.Equal(t, tt.cantons, cantons)
+		})
+	}
+}
+
+func TestResolvePostalCode(t *testing.T) {
+	g, err := Load()
+	require.NoError(t, err)
+
+	places := g.Resolve("1008 Prilly")
+	require.Len(t, places, 1)
+	assert.Equal(t, "1008", places[0].PostalCode)
+
+	// Unbekannter Name, bekannte Postleitzahl
+	places = g.Resolve("CH-8400 Oberwinterthur")
+	require.Len(t, places, 1)
+	assert.Equal(t, "Winterthur", places[0].City)
+	assert.Equal(t, "8400", places[0].PostalCode)
+
+	// 8099 ist keine Postleitzahl von Zürich
+	assert.Empty(t, g.Resolve("8099 Nirgendwo"))
+}
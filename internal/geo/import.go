@@ -0,0 +1,244 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Spalten des amtlichen Ortschaftenverzeichnisses, mit den Varianten früherer Ausgaben
+var localityColumns = map[string][]string{
+	"locality":     {"Ortschaftsname"},
+	"postalCode":   {"PLZ", "PLZ4"},
+	"municipality": {"Gemeindename"},
+	"bfs":          {"BFS-Nr", "BFS-Nr."},
+	"canton":       {"Kantonskürzel", "Kantonskuerzel"},
+	"lon":          {"E"},
+	"lat":          {"N"},
+}
+
+type importedMunicipality struct {
+	bfs         int
+	name        string
+	canton      string
+	postalCodes []int
+	localities  []string
+	lat, lon    []float64 // coordinates of the localities
+	center      int       // index of the locality named like the municipality, -1 if none
+}
+
+// ImportLocalities converts the official directory of Swiss localities with postal
+// codes (swisstopo, "Amtliches Ortschaftenverzeichnis", CSV with WGS84 coordinates)
+// into the format of data/municipalities.csv and returns the number of municipalities.
+// Localities named differently than their municipality become aliases. The aliases
+// of the bundled data, e.g. exonyms like "Genf", are kept. Names used by several
+// municipalities are left out, as they cannot be resolved.
+func ImportLocalities(r io.Reader, w io.Writer) (int, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading localities: %w", err)
+	}
+	columns, err := localityIndexes(header)
+	if err != nil {
+		return 0, err
+	}
+
+	byBFS := make(map[int]*importedMunicipality)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading localities: %w", err)
+		}
+		if len(row) < len(header) {
+			return 0, fmt.Errorf("line %d: expected %d columns, got %d", line, len(header), len(row))
+		}
+
+		bfs, err := strconv.Atoi(strings.TrimSpace(row[columns["bfs"]]))
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid BFS number %q", line, row[columns["bfs"]])
+		}
+		postalCode, err := strconv.Atoi(strings.TrimSpace(row[columns["postalCode"]]))
+		if err != nil || postalCode < 1000 || postalCode > 9999 {
+			return 0, fmt.Errorf("line %d: invalid postal code %q", line, row[columns["postalCode"]])
+		}
+		lat, lon, err := parseCoordinates(strings.TrimSpace(row[columns["lat"]]), strings.TrimSpace(row[columns["lon"]]))
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		m, ok := byBFS[bfs]
+		if !ok {
+			m = &importedMunicipality{
+				bfs:    bfs,
+				name:   strings.TrimSpace(row[columns["municipality"]]),
+				canton: strings.TrimSpace(row[columns["canton"]]),
+				center: -1,
+			}
+			byBFS[bfs] = m
+		}
+		locality := strings.TrimSpace(row[columns["locality"]])
+		if m.center < 0 && key(locality) == key(m.name) {
+			m.center = len(m.lat)
+		}
+		m.localities = append(m.localities, locality)
+		m.lat = append(m.lat, lat)
+		m.lon = append(m.lon, lon)
+		if !slices.Contains(m.postalCodes, postalCode) {
+			m.postalCodes = append(m.postalCodes, postalCode)
+		}
+	}
+	if len(byBFS) == 0 {
+		return 0, fmt.Errorf("localities contain no data")
+	}
+
+	municipalities := make([]*importedMunicipality, 0, len(byBFS))
+	for _, m := range byBFS {
+		municipalities = append(municipalities, m)
+	}
+	slices.SortFunc(municipalities, func(a, b *importedMunicipality) int { return a.bfs - b.bfs })
+
+	aliases, err := bundledAliases()
+	if err != nil {
+		return 0, err
+	}
+	owners := nameOwners(municipalities, aliases)
+
+	out := csv.NewWriter(w)
+	out.Comma = ';'
+	if err := out.Write([]string{"name", "aliases", "postal_codes", "canton", "lat", "lon"}); err != nil {
+		return 0, err
+	}
+	for _, m := range municipalities {
+		lat, lon := m.coordinates()
+		if err := out.Write([]string{
+			m.name,
+			strings.Join(m.aliases(aliases[key(m.name)], owners), "|"),
+			formatPostalCodes(m.postalCodes),
+			m.canton,
+			strconv.FormatFloat(lat, 'f', 4, 64),
+			strconv.FormatFloat(lon, 'f', 4, 64),
+		}); err != nil {
+			return 0, err
+		}
+	}
+	out.Flush()
+	return len(municipalities), out.Error()
+}
+
+// localityIndexes finds the columns of the directory by their names
+func localityIndexes(header []string) (map[string]int, error) {
+	indexes := make(map[string]int, len(localityColumns))
+	for field, names := range localityColumns {
+		for i, column := range header {
+			column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+			if slices.Contains(names, column) {
+				indexes[field] = i
+				break
+			}
+		}
+		if _, ok := indexes[field]; !ok {
+			return nil, fmt.Errorf("localities have no column %s", names[0])
+		}
+	}
+	return indexes, nil
+}
+
+// bundledAliases returns the aliases of the bundled municipalities by name key
+func bundledAliases() (map[string][]string, error) {
+	rows, err := readCSV("data/municipalities.csv")
+	if err != nil {
+		return nil, err
+	}
+	aliases := make(map[string][]string, len(rows))
+	for _, row := range rows {
+		if row[1] != "" {
+			aliases[key(row[0])] = strings.Split(row[1], "|")
+		}
+	}
+	return aliases, nil
+}
+
+// nameOwners maps every name key to the municipalities using it. Official names
+// belong to their municipality only, aliases are shared if several use them.
+func nameOwners(municipalities []*importedMunicipality, aliases map[string][]string) map[string][]int {
+	owners := make(map[string][]int)
+	add := func(name string, bfs int) {
+		k := key(name)
+		if !slices.Contains(owners[k], bfs) {
+			owners[k] = append(owners[k], bfs)
+		}
+	}
+	for _, m := range municipalities {
+		for _, locality := range m.localities {
+			add(locality, m.bfs)
+		}
+		for _, alias := range aliases[key(m.name)] {
+			add(alias, m.bfs)
+		}
+	}
+	for _, m := range municipalities {
+		owners[key(m.name)] = []int{m.bfs}
+	}
+	return owners
+}
+
+// aliases returns the names of the municipality other than its official name
+// that no other municipality uses
+func (m *importedMunicipality) aliases(bundled []string, owners map[string][]int) []string {
+	var result []string
+	seen := map[string]bool{key(m.name): true}
+	for _, name := range append(slices.Clone(bundled), m.localities...) {
+		k := key(name)
+		if seen[k] || !slices.Equal(owners[k], []int{m.bfs}) {
+			continue
+		}
+		seen[k] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// coordinates returns the position of the locality named like the municipality,
+// the center of its localities otherwise
+func (m *importedMunicipality) coordinates() (float64, float64) {
+	if m.center >= 0 {
+		return m.lat[m.center], m.lon[m.center]
+	}
+	var lat, lon float64
+	for i := range m.lat {
+		lat += m.lat[i]
+		lon += m.lon[i]
+	}
+	return lat / float64(len(m.lat)), lon / float64(len(m.lon))
+}
+
+// formatPostalCodes writes exact postal codes, consecutive codes as range, e.g. "3000-3005|3008"
+func formatPostalCodes(codes []int) string {
+	codes = slices.Clone(codes)
+	slices.Sort(codes)
+
+	var parts []string
+	for i := 0; i < len(codes); {
+		j := i
+		for j+1 < len(codes) && codes[j+1] == codes[j]+1 {
+			j++
+		}
+		part := strconv.Itoa(codes[i])
+		if j > i {
+			part += "-" + strconv.Itoa(codes[j])
+		}
+		parts = append(parts, part)
+		i = j + 1
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,48 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Auszug im Format des amtlichen Ortschaftenverzeichnisses
+const localitiesSample = "\ufeffOrtschaftsname;PLZ;Zusatzziffer;Gemeindename;BFS-Nr;Kantonskürzel;E;N;Sprache;Validity\n" +
+	"Bern;3011;0;Bern;351;BE;7.4474;46.9480;de;2008-07-01\n" +
+	"Bern;3012;0;Bern;351;BE;7.4300;46.9600;de;2008-07-01\n" +
+	"Bern;3013;0;Bern;351;BE;7.4550;46.9560;de;2008-07-01\n" +
+	"Bern;3018;0;Bern;351;BE;7.3900;46.9350;de;2008-07-01\n" +
+	"Wabern;3084;0;Köniz;355;BE;7.4500;46.9280;de;2008-07-01\n" +
+	"Liebefeld;3097;0;Köniz;355;BE;7.4200;46.9300;de;2008-07-01\n" +
+	"Oberdorf;3098;2;Köniz;355;BE;7.4000;46.9200;de;2008-07-01\n" +
+	"Oberdorf SO;4515;0;Oberdorf (SO);2553;SO;7.5000;47.2300;de;2008-07-01\n" +
+	"Oberdorf;4515;1;Oberdorf (SO);2553;SO;7.5100;47.2400;de;2008-07-01\n" +
+	"Genève;1204;0;Genève;6621;GE;6.1432;46.2044;fr;2008-07-01\n" +
+	"Genève;1201;0;Genève;6621;GE;6.1420;46.2100;fr;2008-07-01\n" +
+	"Genève;1202;0;Genève;6621;GE;6.1480;46.2200;fr;2008-07-01\n" +
+	"Genève;1202;0;Genève;6621;GE;6.1480;46.2200;fr;2008-07-01\n"
+
+func TestImportLocalities(t *testing.T) {
+	var out strings.Builder
+	count, err := ImportLocalities(strings.NewReader(localitiesSample), &out)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	assert.Equal(t, []string{
+		"name;aliases;postal_codes;canton;lat;lon",
+		// Die Aliase der mitgelieferten Daten bleiben erhalten
+		"Bern;Berne|Berna;3011-3013|3018;BE;46.9480;7.4474",
+		// Ohne Ortschaft gleichen Namens liegt die Gemeinde im Mittel ihrer Ortschaften,
+		// "Oberdorf" kommt in zwei Gemeinden vor und wird kein Alias
+		"Köniz;Liebefeld|Wabern;3084|3097-3098;BE;46.9260;7.4233",
+		"Oberdorf (SO);;4515;SO;47.2300;7.5000",
+		"Genève;Genf|Geneva|Ginevra;1201-1202|1204;GE;46.2044;6.1432",
+	}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+}
+
+func TestImportLocalitiesMissingColumn(t *testing.T) {
+	_, err := ImportLocalities(strings.NewReader("Ortschaftsname;PLZ;Gemeindename;Kantonskürzel;E;N\nBern;3011;Bern;BE;7.4474;46.9480\n"), &strings.Builder{})
+	assert.ErrorContains(t, err, "BFS-Nr")
+}
@@ -0,0 +1,160 @@
+package langdetect
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Sprachen der Trainingskorpora, als ISO-639-1-Code
+const (
+	German  = "de"
+	French  = "fr"
+	English = "en"
+	Italian = "it"
+)
+
+const (
+	// minNGrams is the minimum number of trigrams needed for a reliable result
+	minNGrams = 20
+	// maxTextLength begrenzt den analysierten Text, der Anfang genügt für die Erkennung
+	maxTextLength = 5000
+	// minMargin is the minimum average log-likelihood difference per n-gram between
+	// the best and the second best language
+	minMargin = 0.05
+)
+
+//go:embed corpus/*.txt
+var corpusFiles embed.FS
+
+type profile struct {
+	language string
+	logProb  map[string]float64
+	unseen   float64
+}
+
+// Detector identifies the language of a text with character n-gram profiles.
+// The profiles are built from the bundled corpora, no network access is needed.
+type Detector struct {
+	profiles []profile
+}
+
+// New builds the language profiles from the bundled corpora
+func New() (*Detector, error) {
+	entries, err := corpusFiles.ReadDir("corpus")
+	if err != nil {
+		return nil, fmt.Errorf("error reading language corpora: %w", err)
+	}
+
+	d := &Detector{}
+	for _, entry := range entries {
+		content, err := corpusFiles.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading language corpus %s: %w", entry.Name(), err)
+		}
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		d.profiles = append(d.profiles, buildProfile(language, string(content)))
+	}
+	if len(d.profiles) == 0 {
+		return nil, fmt.Errorf("no language corpora found")
+	}
+
+	sort.Slice(d.profiles, func(i, j int) bool { return d.profiles[i].language < d.profiles[j].language })
+	return d, nil
+}
+
+// Languages returns the codes of the detectable languages
+func (d *Detector) Languages() []string {
+	languages := make([]string, 0, len(d.profiles))
+	for _, p := range d.profiles {
+		languages = append(languages, p.language)
+	}
+	return languages
+}
+
+// Detect returns the ISO 639-1 code of the language the text is written in.
+// It returns an empty string if the text is too short or ambiguous.
+func (d *Detector) Detect(text string) string {
+	if len(text) > maxTextLength {
+		text = text[:maxTextLength]
+	}
+
+	grams := nGrams(text)
+	if len(grams) < minNGrams {
+		return ""
+	}
+
+	best, second := math.Inf(-1), math.Inf(-1)
+	language := ""
+	for _, p := range d.profiles {
+		score := 0.0
+		for _, gram := range grams {
+			if lp, ok := p.logProb[gram]; ok {
+				score += lp
+			} else {
+				score += p.unseen
+			}
+		}
+		switch {
+		case score > best:
+			best, second = score, best
+			language = p.language
+		case score > second:
+			second = score
+		}
+	}
+
+	if (best-second)/float64(len(grams)) < minMargin {
+		return ""
+	}
+	return language
+}
+
+// buildProfile computes add-one smoothed log probabilities of the n-grams of a corpus
+func buildProfile(language, corpus string) profile {
+	counts := make(map[string]int)
+	total := 0
+	for _, gram := range nGrams(corpus) {
+		counts[gram]++
+		total++
+	}
+
+	// Platz für ungesehene N-Gramme im Vokabular
+	vocabulary := float64(len(counts) + 1)
+	p := profile{
+		language: language,
+		logProb:  make(map[string]float64, len(counts)),
+		unseen:   math.Log(1 / (float64(total) + vocabulary)),
+	}
+	for gram, count := range counts {
+		p.logProb[gram] = math.Log((float64(count) + 1) / (float64(total) + vocabulary))
+	}
+	return p
+}
+
+// nGrams returns the character bigrams and trigrams of the words in the text.
+// Words are padded with spaces, so prefixes and suffixes are distinguishable.
+// Digits, punctuation and URLs are ignored.
+func nGrams(text string) []string {
+	var grams []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		word = strings.Trim(word, "'")
+		if word == "" || strings.HasPrefix(word, "http") || strings.HasPrefix(word, "www") {
+			continue
+		}
+
+		runes := []rune(" " + word + " ")
+		for n := 2; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				grams = append(grams, string(runes[i:i+n]))
+			}
+		}
+	}
+	return grams
+}
@@ -0,0 +1,133 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"de", "en", "fr", "it"}, d.Languages())
+
+	tests := []struct {
+		language string
+		text     string
+	}{
+		{German, "Für unseren Kunden, eine Bank in Basel, suchen wir einen erfahrenen Java Entwickler (m/w/d). Sie entwickeln Backend-Services mit Spring Boot und betreuen die Schnittstellen zu den Umsystemen."},
+		{French, "Pour notre client, une banque à Genève, nous cherchons un développeur Java expérimenté. Vous développez des services backend avec Spring Boot et assurez le suivi des interfaces."},
+		{English, "For our client, a bank in Basel, we are looking for an experienced Java developer. You will build backend services with Spring Boot and look after the interfaces to other systems."},
+		{Italian, "Per il nostro cliente, una banca a Lugano, cerchiamo uno sviluppatore Java con esperienza. Svilupperà servizi backend con Spring Boot e si occuperà delle interfacce con gli altri sistemi."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			assert.Equal(t, tt.language, d.Detect(tt.text))
+		})
+	}
+}
+
+// Die Inserate stammen nicht aus den Trainingskorpora und enthalten, wie auf den
+// Jobportalen üblich, Aufzählungen, Fachbegriffe und englische Jobtitel
+func TestDetectPostings(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		language string
+		text     string
+	}{
+		{"german bullet list", German, `Senior DevOps Engineer (w/m/d) 80-100%
+Deine Aufgaben
+- Du betreibst unsere Kubernetes-Cluster auf Azure und automatisierst Deployments mit Terraform und ArgoCD
+- Du überwachst die Plattform mit Prometheus und Grafana und kümmerst dich um Incidents
+- Du unterstützt die Entwicklungsteams bei CI/CD-Pipelines
+Das bringst du mit
+- Mehrjährige Erfahrung im Betrieb von Linux-Systemen
+- Gute Deutsch- und Englischkenntnisse
+Wir bieten dir 25 Ferientage, ein GA und flexible Arbeitszeiten.`},
+		{"german logistics", German, "Chauffeur Kat. C/CE 100% – Region Bern. Sie beliefern unsere Kunden in der ganzen Deutschschweiz, laden und entladen das Fahrzeug selbstständig und erledigen die nötigen Lieferpapiere. Sie besitzen den Fahrausweis Kat. C/CE mit CZV und haben einen einwandfreien Leumund."},
+		{"french bullet list", French, `Ingénieur·e logiciel embarqué (H/F/D)
+Vos missions :
+• Concevoir et développer le firmware de nos capteurs en C/C++
+• Rédiger les spécifications et les plans de test
+• Participer aux revues de code et à l'intégration continue
+Votre profil :
+• Master en électronique ou en informatique
+• Trois ans d'expérience minimum dans l'embarqué
+Lieu de travail : Yverdon-les-Bains, télétravail partiel possible.`},
+		{"french care", French, "Aide-soignant·e à 80% pour notre EMS de Sion. Vous accompagnez les résidents dans les actes de la vie quotidienne, participez aux animations et transmettez vos observations à l'équipe infirmière. Certificat de la Croix-Rouge exigé."},
+		{"english startup", English, `About us: we're a Zurich-based fintech building the next generation of payment infrastructure.
+What you'll do: design and ship APIs used by thousands of merchants, own services from design to production, mentor junior engineers.
+What we're looking for: 5+ years of backend experience (Go, Kotlin or Java), solid understanding of distributed systems, fluent English. German is a plus but not required.
+Perks: equity, 5 weeks of vacation, remote-friendly, annual team retreat.`},
+		{"italian retail", Italian, "Addetta/o alla vendita 50% per il nostro negozio di Locarno. Accoglie i clienti, li consiglia nella scelta dei prodotti e si occupa del riordino degli scaffali. Richiediamo esperienza nella vendita, buona conoscenza del tedesco e disponibilità a lavorare il sabato."},
+		{"italian it", Italian, `Sviluppatore/trice Full Stack (React, Node.js)
+Mansioni principali:
+- sviluppo di nuove funzionalità per la nostra piattaforma di e-commerce
+- manutenzione del codice esistente e correzione degli errori
+- collaborazione con il team di design e con il product owner
+Requisiti: diploma SUP in informatica o titolo equivalente, almeno due anni di esperienza, ottime conoscenze di italiano e inglese.`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.language, d.Detect(tt.text))
+		})
+	}
+}
+
+func TestDetectShortPostings(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		language string
+		text     string
+	}{
+		{German, "Wir suchen per sofort eine Pflegefachfrau HF für unsere Abteilung."},
+		{French, "Nous recherchons un mécanicien poids lourds pour notre garage."},
+		{English, "We are hiring a part-time barista for our new café in Bern."},
+		{Italian, "Cerchiamo un muratore con esperienza per cantieri in Ticino."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			assert.Equal(t, tt.language, d.Detect(tt.text))
+		})
+	}
+}
+
+// Inserate mischen oft Sprachen, massgebend ist die Sprache des Fliesstexts
+func TestDetectMixedPostings(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		language string
+		text     string
+	}{
+		{"german with english title and skills", German, "Product Owner Digital Banking (80-100%). In dieser Rolle verantworten Sie das Backlog unseres Mobile-Banking-Teams, priorisieren die User Stories gemeinsam mit den Stakeholdern und stellen sicher, dass jedes Release einen echten Mehrwert für unsere Kundinnen und Kunden bringt. Skills: Scrum, Jira, Confluence, Stakeholder Management."},
+		{"english with german benefits", English, "As a Site Reliability Engineer you will keep our platform fast and available, automate everything that can be automated and lead the response when things go wrong. You have strong Linux skills and experience with Kubernetes. Wir bieten: 13. Monatslohn, Halbtax."},
+		{"french with english skills", French, "Pour renforcer notre équipe à Lausanne, nous recherchons un Data Scientist senior. Vous construisez des modèles de prévision de la demande et les mettez en production avec nos ingénieurs. Stack : Python, pandas, scikit-learn, Airflow, Snowflake. Anglais courant, allemand un atout."},
+		{"italian with english title", Italian, "Customer Success Manager – Lugano. Sarà il punto di riferimento dei nostri clienti dopo la firma del contratto, li accompagnerà nell'introduzione della soluzione e individuerà nuove opportunità di crescita. Lavorerà a stretto contatto con i team di vendita e di supporto."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.language, d.Detect(tt.text))
+		})
+	}
+}
+
+func TestDetectShortText(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	assert.Equal(t, "", d.Detect("Java, Go"))
+	assert.Equal(t, "", d.Detect(""))
+}
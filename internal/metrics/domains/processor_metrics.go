@@ -14,7 +14,7 @@ var (
 			Help:      "Duration of processing operations",
 			Buckets:   []float64{0.5, 1, 2, 5, 10, 20},
 		},
-		[]string{"processor", "status"},
+		[]string{"provider", "status"},
 	)
 
 	ProcessorErrors = promauto.NewCounterVec(
@@ -24,7 +24,7 @@ var (
 			Name:      "errors_total",
 			Help:      "Total number of processor errors",
 		},
-		[]string{"processor", "error_type"},
+		[]string{"provider", "error_type"},
 	)
 
 	OpenAITokensUsed = promauto.NewCounterVec(
@@ -36,4 +36,174 @@ var (
 		},
 		[]string{"model", "operation"},
 	)
+
+	BudgetExhausted = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "budget_exhausted",
+			Help:      "Whether the LLM budget of a window is exhausted (1) or not (0)",
+		},
+		[]string{"window"},
+	)
+
+	BudgetTokensUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "budget_tokens_used",
+			Help:      "Tokens used in the current budget window",
+		},
+		[]string{"window"},
+	)
+
+	BudgetCostUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "budget_cost_used_usd",
+			Help:      "Estimated LLM cost in USD in the current budget window",
+		},
+		[]string{"window"},
+	)
+
+	BudgetCostTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "llm_cost_usd_total",
+			Help:      "Total estimated LLM cost in USD",
+		},
+		[]string{"model"},
+	)
+
+	QueuedJobs = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "queued_jobs",
+			Help:      "Number of jobs waiting for the LLM budget to reset",
+		},
+	)
+
+	ExtractionCacheHits = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "extraction_cache_hits_total",
+			Help:      "Total number of extraction results served from the cache",
+		},
+		[]string{"processor"},
+	)
+
+	ExtractionCacheMisses = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "extraction_cache_misses_total",
+			Help:      "Total number of extraction cache misses",
+		},
+		[]string{"processor"},
+	)
+
+	PreprocessingTokensSaved = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "preprocessing_tokens_saved",
+			Help:      "Estimated number of tokens saved per job by the preprocessing",
+			Buckets:   []float64{0, 100, 250, 500, 1000, 2500, 5000, 10000},
+		},
+	)
+
+	PreprocessingOverflows = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "preprocessing_overflows_total",
+			Help:      "Total number of job descriptions exceeding the token limit",
+		},
+		[]string{"overflow"},
+	)
+
+	ProcessorStageDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "stage_duration_seconds",
+			Help:      "Duration of the processor chain stages",
+			Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 20},
+		},
+		[]string{"stage", "status"},
+	)
+
+	ProcessorStageErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "stage_errors_total",
+			Help:      "Total number of failed processor chain stages",
+		},
+		[]string{"stage", "policy"},
+	)
+
+	ProcessorStageFallbacks = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "stage_fallbacks_total",
+			Help:      "Total number of jobs processed by the fallback of a failed stage",
+		},
+		[]string{"stage"},
+	)
+
+	ProcessorRoutedJobs = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "routed_jobs_total",
+			Help:      "Total number of jobs handled per provider, as primary or as fallback",
+		},
+		[]string{"provider", "route"},
+	)
+
+	SkillsNormalized = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "skills_normalized_total",
+			Help:      "Total number of extracted skills by taxonomy lookup result",
+		},
+		[]string{"result"},
+	)
+
+	SalariesParsed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "salaries_parsed_total",
+			Help:      "Total number of jobs by salary parsing result: parsed, text only or missing",
+		},
+		[]string{"result"},
+	)
+
+	LocationsNormalized = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "locations_normalized_total",
+			Help:      "Total number of jobs by gazetteer lookup result of their location",
+		},
+		[]string{"result"},
+	)
+
+	LanguagesDetected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "processor",
+			Name:      "languages_detected_total",
+			Help:      "Total number of jobs by detected posting language, unknown if undetermined",
+		},
+		[]string{"language"},
+	)
 )
@@ -36,4 +36,33 @@ var (
 		},
 		[]string{"scraper", "error_type"},
 	)
+
+	DuplicatesDetected = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "scraper",
+			Name:      "duplicates_total",
+			Help:      "Total number of scraped jobs linked to an earlier near-duplicate posting",
+		},
+	)
+
+	ClosedJobs = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "lifecycle",
+			Name:      "closed_jobs_total",
+			Help:      "Total number of jobs closed by the lifecycle pass",
+		},
+		[]string{"reason"},
+	)
+
+	LifecycleChecks = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "jobscraper",
+			Subsystem: "lifecycle",
+			Name:      "checks_total",
+			Help:      "Total number of online checks of job postings",
+		},
+		[]string{"result"},
+	)
 )
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// FailedJob records a scraped job whose processing failed, so it can be reprocessed later
+type FailedJob struct {
+	URL           string         `bson:"_id" json:"url"`
+	Description   string         `bson:"description" json:"description"`
+	Source        *SourcePayload `bson:"source,omitempty" json:"source,omitempty"`
+	Error         string         `bson:"error" json:"error"`
+	Attempts      int            `bson:"attempts" json:"attempts"`
+	FirstFailedAt time.Time      `bson:"firstFailedAt" json:"firstFailedAt"`
+	LastFailedAt  time.Time      `bson:"lastFailedAt" json:"lastFailedAt"`
+}
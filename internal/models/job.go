@@ -6,26 +6,53 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Verfahren, mit denen die Felder eines Jobs extrahiert wurden
+const (
+	ExtractionMethodLLM   = "llm"
+	ExtractionMethodRules = "rules"
+)
+
+// Job is a posting with its extracted fields. IsActive, ClosedAt and LastSeenAt are
+// kept up to date by the scraper and the lifecycle pass, see services.LifecycleService.
 type Job struct {
-	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	URL               string             `bson:"url" json:"url"`
-	Title             string             `bson:"title" json:"title"`
-	Description       string             `bson:"description" json:"description"`
-	Company           string             `bson:"company" json:"company"`
-	Location          string             `bson:"location" json:"location"`
-	EmploymentType    string             `bson:"employmentType" json:"employmentType"`
-	PostingDate       time.Time          `bson:"postingDate" json:"postingDate"`
-	ExpirationDate    time.Time          `bson:"expirationDate" json:"expirationDate"`
-	IsActive          bool               `bson:"isActive" json:"isActive"`
-	JobCategories     []string           `bson:"jobCategories" json:"jobCategories"`
-	MustSkills        []string           `bson:"mustSkills" json:"mustSkills"`
-	OptionalSkills    []string           `bson:"optionalSkills" json:"optionalSkills"`
-	Salary            string             `bson:"salary" json:"salary"`
-	YearsOfExperience int                `bson:"yearsOfExperience" json:"yearsOfExperience"`
-	EducationLevel    string             `bson:"educationLevel" json:"educationLevel"`
-	Benefits          []string           `bson:"benefits" json:"benefits"`
-	CompanySize       int                `bson:"companySize" json:"companySize"`
-	WorkCulture       string             `bson:"workCulture" json:"workCulture"`
-	Remote            bool               `bson:"remote" json:"remote"`
-	Languages         []string           `bson:"languages" json:"languages"`
+	ID                primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	URL               string              `bson:"url" json:"url"`
+	Title             string              `bson:"title" json:"title"`
+	Description       string              `bson:"description" json:"description"`
+	Company           string              `bson:"company" json:"company"`
+	Location          string              `bson:"location" json:"location"`
+	Locations         []Place             `bson:"locations,omitempty" json:"locations,omitempty"`
+	EmploymentType    string              `bson:"employmentType" json:"employmentType"`
+	PostingDate       time.Time           `bson:"postingDate" json:"postingDate"`
+	ExpirationDate    time.Time           `bson:"expirationDate" json:"expirationDate"`
+	IsActive          bool                `bson:"isActive" json:"isActive"`
+	ClosedAt          *time.Time          `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+	LastSeenAt        *time.Time          `bson:"lastSeenAt,omitempty" json:"lastSeenAt,omitempty"`
+	JobCategories     []string            `bson:"jobCategories" json:"jobCategories"`
+	MustSkills        []string            `bson:"mustSkills" json:"mustSkills"`
+	OptionalSkills    []string            `bson:"optionalSkills" json:"optionalSkills"`
+	Salary            *Salary             `bson:"salary,omitempty" json:"salary,omitempty"`
+	YearsOfExperience int                 `bson:"yearsOfExperience" json:"yearsOfExperience"`
+	EducationLevel    string              `bson:"educationLevel" json:"educationLevel"`
+	Benefits          []string            `bson:"benefits" json:"benefits"`
+	CompanySize       int                 `bson:"companySize" json:"companySize"`
+	WorkCulture       string              `bson:"workCulture" json:"workCulture"`
+	Remote            bool                `bson:"remote" json:"remote"`
+	Languages         []string            `bson:"languages" json:"languages"`
+	PostingLanguage   string              `bson:"postingLanguage,omitempty" json:"postingLanguage,omitempty"`
+	PromptVersion     string              `bson:"promptVersion,omitempty" json:"promptVersion,omitempty"`
+	ExtractionMethod  string              `bson:"extractionMethod,omitempty" json:"extractionMethod,omitempty"`
+	Provider          string              `bson:"provider,omitempty" json:"provider,omitempty"`
+	SkillTaxonomy     string              `bson:"skillTaxonomy,omitempty" json:"skillTaxonomy,omitempty"`
+	Fingerprint       string              `bson:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	DuplicateOf       *primitive.ObjectID `bson:"duplicateOf,omitempty" json:"duplicateOf,omitempty"`
+	Source            *SourcePayload      `bson:"source,omitempty" json:"-"`
+}
+
+// SourcePayload is the unmodified response a job was extracted from
+type SourcePayload struct {
+	RawPayload  string    `bson:"rawPayload" json:"rawPayload"`
+	ContentType string    `bson:"contentType" json:"contentType"`
+	FetchedAt   time.Time `bson:"fetchedAt" json:"fetchedAt"`
+	HTTPStatus  int       `bson:"httpStatus" json:"httpStatus"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldChange is a field of a job that changed between two versions.
+// Old and New are omitted for fields like the raw source that are too large to repeat.
+type FieldChange struct {
+	Field string      `bson:"field" json:"field"`
+	Old   interface{} `bson:"old,omitempty" json:"old,omitempty"`
+	New   interface{} `bson:"new,omitempty" json:"new,omitempty"`
+}
+
+// JobVersion is a prior version of a job, kept when a re-scraped posting changed.
+// Job holds the job as it was before the change, Changes the fields that differ
+// from the version that replaced it.
+type JobVersion struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	JobID      primitive.ObjectID `bson:"jobId" json:"jobId"`
+	URL        string             `bson:"url" json:"url"`
+	Version    int                `bson:"version" json:"version"`
+	ReplacedAt time.Time          `bson:"replacedAt" json:"replacedAt"`
+	Changes    []FieldChange      `bson:"changes" json:"changes"`
+	Job        Job                `bson:"job" json:"job"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Place is a place of work resolved with the gazetteer
+type Place struct {
+	City       string  `bson:"city,omitempty" json:"city,omitempty"`
+	PostalCode string  `bson:"postalCode,omitempty" json:"postalCode,omitempty"`
+	Canton     string  `bson:"canton,omitempty" json:"canton,omitempty"`
+	Country    string  `bson:"country" json:"country"`
+	Lat        float64 `bson:"lat" json:"lat"`
+	Lon        float64 `bson:"lon" json:"lon"`
+}
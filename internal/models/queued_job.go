@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// QueuedJob is a scraped job waiting for the LLM budget to reset. The queue is
+// stored, so it survives a restart.
+type QueuedJob struct {
+	URL      string    `bson:"_id" json:"url"`
+	Job      Job       `bson:"job" json:"job"`
+	QueuedAt time.Time `bson:"queuedAt" json:"queuedAt"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Zeiträume, auf die sich eine Lohnangabe bezieht
+const (
+	SalaryPeriodYear  = "year"
+	SalaryPeriodMonth = "month"
+	SalaryPeriodWeek  = "week"
+	SalaryPeriodDay   = "day"
+	SalaryPeriodHour  = "hour"
+)
+
+// Lohnbasis
+const (
+	SalaryBasisGross = "gross"
+	SalaryBasisNet   = "net"
+)
+
+// Salary is a structured salary range. AnnualMinCHF and AnnualMaxCHF hold the
+// range converted to a yearly amount in CHF and are used for statistics.
+// Text keeps the salary as stated in the posting.
+type Salary struct {
+	Min          float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max          float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Currency     string  `bson:"currency,omitempty" json:"currency,omitempty"`
+	Period       string  `bson:"period,omitempty" json:"period,omitempty"`
+	Basis        string  `bson:"basis,omitempty" json:"basis,omitempty"`
+	AnnualMinCHF float64 `bson:"annualMinChf,omitempty" json:"annualMinChf,omitempty"`
+	AnnualMaxCHF float64 `bson:"annualMaxChf,omitempty" json:"annualMaxChf,omitempty"`
+	Text         string  `bson:"text,omitempty" json:"text,omitempty"`
+}
+
+// salaryFields verhindert die Rekursion beim Dekodieren
+type salaryFields Salary
+
+// IsStructured reports whether the salary has been parsed into an amount
+func (s *Salary) IsStructured() bool {
+	return s != nil && (s.Min > 0 || s.Max > 0)
+}
+
+// UnmarshalBSONValue also accepts the plain strings stored before salaries were structured.
+// Such values are kept in Text only.
+func (s *Salary) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*s = Salary{}
+		return nil
+	case bsontype.String:
+		var text string
+		if err := bson.UnmarshalValue(t, data, &text); err != nil {
+			return err
+		}
+		*s = Salary{Text: text}
+		return nil
+	case bsontype.EmbeddedDocument:
+		var fields salaryFields
+		if err := bson.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		*s = Salary(fields)
+		return nil
+	default:
+		return fmt.Errorf("cannot decode salary from BSON type %s", t)
+	}
+}
+
+// UnmarshalJSON accepts a salary object or, like in golden datasets and LLM
+// responses, the salary as plain text
+func (s *Salary) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Salary{Text: text}
+		return nil
+	}
+
+	var fields salaryFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*s = Salary(fields)
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSalaryDecodesLegacyString(t *testing.T) {
+	data, err := bson.Marshal(bson.M{"title": "Go Developer", "salary": "CHF 100'000 - 120'000"})
+	assert.NoError(t, err)
+
+	var job Job
+	assert.NoError(t, bson.Unmarshal(data, &job))
+	assert.Equal(t, &Salary{Text: "CHF 100'000 - 120'000"}, job.Salary)
+}
+
+func TestSalaryRoundTrip(t *testing.T) {
+	salary := &Salary{Min: 8000, Max: 9000, Currency: "CHF", Period: SalaryPeriodMonth, AnnualMinCHF: 96000, AnnualMaxCHF: 108000}
+	data, err := bson.Marshal(Job{Salary: salary})
+	assert.NoError(t, err)
+
+	var job Job
+	assert.NoError(t, bson.Unmarshal(data, &job))
+	assert.Equal(t, salary, job.Salary)
+
+	data, err = bson.Marshal(Job{})
+	assert.NoError(t, err)
+	job = Job{}
+	assert.NoError(t, bson.Unmarshal(data, &job))
+	assert.Nil(t, job.Salary)
+}
+
+func TestSalaryUnmarshalJSON(t *testing.T) {
+	var job Job
+	assert.NoError(t, json.Unmarshal([]byte(`{"salary": "80-90k"}`), &job))
+	assert.Equal(t, &Salary{Text: "80-90k"}, job.Salary)
+
+	job = Job{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"salary": {"min": 80000, "currency": "EUR"}}`), &job))
+	assert.Equal(t, &Salary{Min: 80000, Currency: "EUR"}, job.Salary)
+}
@@ -6,6 +6,7 @@ import (
 	"job-scraper/internal/apperrors"
 	"job-scraper/internal/models"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -95,15 +96,29 @@ func parseBool(v interface{}) bool {
 	}
 }
 
-func parseSalary(v interface{}) string {
+// parseSalary keeps the salary as returned by the LLM. It is parsed into a range
+// by the parse_salary stage of the processor chain.
+func parseSalary(v interface{}) *models.Salary {
 	switch value := v.(type) {
 	case string:
-		return value
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		return &models.Salary{Text: value}
 	case float64:
-		return fmt.Sprintf("%.2f", value)
-	case int:
-		return strconv.Itoa(value)
+		return &models.Salary{Text: strconv.FormatFloat(value, 'f', -1, 64)}
+	case map[string]interface{}:
+		// Strukturierte Antwort: die Felder wie beim Golden-Dataset dekodieren
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		var s models.Salary
+		if err := json.Unmarshal(data, &s); err != nil || (!s.IsStructured() && s.Text == "") {
+			return nil
+		}
+		return &s
 	default:
-		return ""
+		return nil
 	}
 }
@@ -0,0 +1,199 @@
+package budget
+
+import (
+	"sync"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/processor"
+)
+
+const (
+	WindowDaily   = "daily"
+	WindowMonthly = "monthly"
+)
+
+// Config defines the token and cost limits per window. A limit of zero disables it.
+// Prices are given in USD per one million tokens.
+type Config struct {
+	DailyTokens          int64
+	MonthlyTokens        int64
+	DailyCost            float64
+	MonthlyCost          float64
+	PromptTokenPrice     float64
+	CompletionTokenPrice float64
+}
+
+// WindowState is the externally visible state of a single budget window
+type WindowState struct {
+	Window     string    `json:"window"`
+	TokensUsed int64     `json:"tokensUsed"`
+	TokenLimit int64     `json:"tokenLimit"`
+	CostUsed   float64   `json:"costUsed"`
+	CostLimit  float64   `json:"costLimit"`
+	Exhausted  bool      `json:"exhausted"`
+	ResetsAt   time.Time `json:"resetsAt"`
+}
+
+// State is a snapshot of all budget windows
+type State struct {
+	Exhausted bool          `json:"exhausted"`
+	Windows   []WindowState `json:"windows"`
+}
+
+type window struct {
+	name       string
+	tokenLimit int64
+	costLimit  float64
+	tokensUsed int64
+	costUsed   float64
+	start      time.Time
+	startOf    func(time.Time) time.Time
+	next       func(time.Time) time.Time
+}
+
+// Tracker keeps track of the LLM spend in a daily and a monthly window.
+// Windows are aligned to UTC calendar days and months and reset automatically.
+type Tracker struct {
+	mu      sync.Mutex
+	config  Config
+	windows []*window
+	now     func() time.Time
+}
+
+func NewTracker(config Config) *Tracker {
+	t := &Tracker{
+		config: config,
+		now:    time.Now,
+	}
+
+	now := t.now().UTC()
+	t.windows = []*window{
+		{
+			name:       WindowDaily,
+			tokenLimit: config.DailyTokens,
+			costLimit:  config.DailyCost,
+			startOf:    startOfDay,
+			next:       func(start time.Time) time.Time { return start.AddDate(0, 0, 1) },
+		},
+		{
+			name:       WindowMonthly,
+			tokenLimit: config.MonthlyTokens,
+			costLimit:  config.MonthlyCost,
+			startOf:    startOfMonth,
+			next:       func(start time.Time) time.Time { return start.AddDate(0, 1, 0) },
+		},
+	}
+	for _, w := range t.windows {
+		w.start = w.startOf(now)
+	}
+	t.updateMetrics()
+
+	return t
+}
+
+// RecordUsage implements processor.UsageRecorder
+func (t *Tracker) RecordUsage(usage processor.Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.roll()
+	cost := t.cost(usage)
+	for _, w := range t.windows {
+		w.tokensUsed += int64(usage.TotalTokens())
+		w.costUsed += cost
+	}
+
+	domains.BudgetCostTotal.WithLabelValues(usage.Model).Add(cost)
+	t.updateMetrics()
+}
+
+// Check returns a BudgetExceededError if any window is exhausted
+func (t *Tracker) Check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.roll()
+	for _, w := range t.windows {
+		if w.exhausted() {
+			return apperrors.NewBudgetExceededError(w.name, w.next(w.start))
+		}
+	}
+	return nil
+}
+
+// State returns a snapshot of the current budget usage
+func (t *Tracker) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.roll()
+	state := State{}
+	for _, w := range t.windows {
+		ws := WindowState{
+			Window:     w.name,
+			TokensUsed: w.tokensUsed,
+			TokenLimit: w.tokenLimit,
+			CostUsed:   w.costUsed,
+			CostLimit:  w.costLimit,
+			Exhausted:  w.exhausted(),
+			ResetsAt:   w.next(w.start),
+		}
+		state.Exhausted = state.Exhausted || ws.Exhausted
+		state.Windows = append(state.Windows, ws)
+	}
+	return state
+}
+
+// roll resets windows whose period has ended. Caller must hold the lock.
+func (t *Tracker) roll() {
+	now := t.now().UTC()
+	changed := false
+	for _, w := range t.windows {
+		if start := w.startOf(now); !start.Equal(w.start) {
+			w.start = start
+			w.tokensUsed = 0
+			w.costUsed = 0
+			changed = true
+		}
+	}
+	if changed {
+		t.updateMetrics()
+	}
+}
+
+func (t *Tracker) cost(usage processor.Usage) float64 {
+	return float64(usage.PromptTokens)*t.config.PromptTokenPrice/1e6 +
+		float64(usage.CompletionTokens)*t.config.CompletionTokenPrice/1e6
+}
+
+func (t *Tracker) updateMetrics() {
+	for _, w := range t.windows {
+		exhausted := 0.0
+		if w.exhausted() {
+			exhausted = 1
+		}
+		domains.BudgetExhausted.WithLabelValues(w.name).Set(exhausted)
+		domains.BudgetTokensUsed.WithLabelValues(w.name).Set(float64(w.tokensUsed))
+		domains.BudgetCostUsed.WithLabelValues(w.name).Set(w.costUsed)
+	}
+}
+
+func (w *window) exhausted() bool {
+	if w.tokenLimit > 0 && w.tokensUsed >= w.tokenLimit {
+		return true
+	}
+	if w.costLimit > 0 && w.costUsed >= w.costLimit {
+		return true
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,56 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/processor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_ExhaustsAndResets(t *testing.T) {
+	now := time.Date(2024, 10, 20, 23, 0, 0, 0, time.UTC)
+	tracker := NewTracker(Config{DailyTokens: 1000, MonthlyTokens: 5000})
+	tracker.now = func() time.Time { return now }
+
+	assert.NoError(t, tracker.Check())
+
+	tracker.RecordUsage(processor.Usage{Model: "test", PromptTokens: 800, CompletionTokens: 200})
+
+	err := tracker.Check()
+	require.Error(t, err)
+	var budgetErr *apperrors.BudgetExceededError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, WindowDaily, budgetErr.Window)
+	assert.Equal(t, time.Date(2024, 10, 21, 0, 0, 0, 0, time.UTC), budgetErr.ResetsAt)
+
+	// The next day the daily window starts over, the monthly one keeps counting
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, tracker.Check())
+
+	state := tracker.State()
+	assert.False(t, state.Exhausted)
+	assert.Equal(t, int64(0), state.Windows[0].TokensUsed)
+	assert.Equal(t, int64(1000), state.Windows[1].TokensUsed)
+}
+
+func TestTracker_CostLimit(t *testing.T) {
+	tracker := NewTracker(Config{
+		MonthlyCost:          1,
+		PromptTokenPrice:     0.5,
+		CompletionTokenPrice: 1.5,
+	})
+
+	tracker.RecordUsage(processor.Usage{Model: "test", PromptTokens: 1_000_000, CompletionTokens: 200_000})
+	assert.NoError(t, tracker.Check())
+
+	tracker.RecordUsage(processor.Usage{Model: "test", PromptTokens: 400_000})
+
+	var budgetErr *apperrors.BudgetExceededError
+	require.ErrorAs(t, tracker.Check(), &budgetErr)
+	assert.Equal(t, WindowMonthly, budgetErr.Window)
+	assert.InDelta(t, 1.0, tracker.State().Windows[1].CostUsed, 1e-9)
+}
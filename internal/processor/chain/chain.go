@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Policy legt fest, wie die Chain auf den Fehler einer Stage reagiert
+type Policy string
+
+const (
+	// PolicyAbort stops the chain and fails the job
+	PolicyAbort Policy = "abort"
+	// PolicySkip discards the output of the failed stage and continues with its input
+	PolicySkip Policy = "skip"
+	// PolicyContinue keeps the output of the failed stage and continues
+	PolicyContinue Policy = "continue"
+)
+
+// ParsePolicy converts a configured policy name. An empty name defaults to PolicyAbort.
+func ParsePolicy(name string) (Policy, error) {
+	switch Policy(name) {
+	case "":
+		return PolicyAbort, nil
+	case PolicyAbort, PolicySkip, PolicyContinue:
+		return Policy(name), nil
+	default:
+		return "", fmt.Errorf("unknown failure policy: %s", name)
+	}
+}
+
+// Stage is a named step of the chain. If the stage fails and a fallback is
+// configured, the fallback processes the input of the stage instead.
+type Stage struct {
+	Name      string
+	Processor processor.JobProcessor
+	OnError   Policy
+	Fallback  processor.JobProcessor
+}
+
+// Chain runs its stages in order, passing the output of each stage to the next one.
+// Without a fallback, an exhausted LLM budget always aborts the chain, so the job can be queued.
+type Chain struct {
+	stages []Stage
+}
+
+func New(stages ...Stage) *Chain {
+	return &Chain{stages: stages}
+}
+
+func (c *Chain) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	for _, stage := range c.stages {
+		start := time.Now()
+		result, err := stage.Processor.Process(ctx, job)
+		duration := time.Since(start).Seconds()
+
+		if err == nil {
+			domains.ProcessorStageDuration.WithLabelValues(stage.Name, "success").Observe(duration)
+			job = result
+			continue
+		}
+
+		domains.ProcessorStageDuration.WithLabelValues(stage.Name, "error").Observe(duration)
+
+		if stage.Fallback != nil && ctx.Err() == nil {
+			fallbackResult, fallbackErr := stage.Fallback.Process(ctx, job)
+			if fallbackErr == nil {
+				domains.ProcessorStageFallbacks.WithLabelValues(stage.Name).Inc()
+				log.Warn().
+					Err(err).
+					Str("stage", stage.Name).
+					Str("job_url", job.URL).
+					Msg("Processor stage failed, used fallback")
+				job = fallbackResult
+				continue
+			}
+			log.Warn().Err(fallbackErr).Str("stage", stage.Name).Str("job_url", job.URL).Msg("Fallback of processor stage failed")
+		}
+
+		domains.ProcessorStageErrors.WithLabelValues(stage.Name, string(stage.OnError)).Inc()
+
+		var budgetErr *apperrors.BudgetExceededError
+		if stage.OnError == PolicyAbort || errors.As(err, &budgetErr) || ctx.Err() != nil {
+			return job, err
+		}
+
+		log.Warn().
+			Err(err).
+			Str("stage", stage.Name).
+			Str("policy", string(stage.OnError)).
+			Str("job_url", job.URL).
+			Msg("Processor stage failed")
+
+		if stage.OnError == PolicyContinue {
+			job = result
+		}
+	}
+
+	return job, nil
+}
+
+// Stages returns the names of the configured stages in order
+func (c *Chain) Stages() []string {
+	names := make([]string, 0, len(c.stages))
+	for _, stage := range c.stages {
+		names = append(names, stage.Name)
+	}
+	return names
+}
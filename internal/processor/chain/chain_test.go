@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendTitle(suffix string) processor.Func {
+	return func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.Title += suffix
+		return job, nil
+	}
+}
+
+func failing(suffix string, err error) processor.Func {
+	return func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.Title += suffix
+		return job, err
+	}
+}
+
+func TestChain_Policies(t *testing.T) {
+	stageErr := errors.New("stage failed")
+
+	tests := []struct {
+		policy  Policy
+		title   string
+		wantErr bool
+	}{
+		{PolicyAbort, "a-b", true},
+		{PolicySkip, "a-c", false},
+		{PolicyContinue, "a-b-c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			c := New(
+				Stage{Name: "first", Processor: appendTitle("a"), OnError: PolicyAbort},
+				Stage{Name: "failing", Processor: failing("-b", stageErr), OnError: tt.policy},
+				Stage{Name: "last", Processor: appendTitle("-c"), OnError: PolicyAbort},
+			)
+
+			job, err := c.Process(context.Background(), models.Job{})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, stageErr)
+				assert.Equal(t, "a", job.Title)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.title, job.Title)
+		})
+	}
+}
+
+func TestChain_BudgetErrorAlwaysAborts(t *testing.T) {
+	budgetErr := apperrors.NewBudgetExceededError("daily", time.Now())
+	c := New(
+		Stage{Name: "llm", Processor: failing("", budgetErr), OnError: PolicyContinue},
+		Stage{Name: "last", Processor: appendTitle("-c"), OnError: PolicyAbort},
+	)
+
+	_, err := c.Process(context.Background(), models.Job{})
+
+	var target *apperrors.BudgetExceededError
+	assert.ErrorAs(t, err, &target)
+}
+
+func TestChain_Fallback(t *testing.T) {
+	budgetErr := apperrors.NewBudgetExceededError("daily", time.Now())
+	c := New(
+		Stage{Name: "llm", Processor: failing("-llm", budgetErr), OnError: PolicyAbort, Fallback: appendTitle("-rules")},
+		Stage{Name: "last", Processor: appendTitle("-c"), OnError: PolicyAbort},
+	)
+
+	job, err := c.Process(context.Background(), models.Job{Title: "a"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a-rules-c", job.Title)
+}
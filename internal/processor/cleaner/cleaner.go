@@ -0,0 +1,206 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken is the rule of thumb for OpenAI tokenizers on mixed German/English text
+const charsPerToken = 4
+
+var (
+	invisibleBlockRe = regexp.MustCompile(`(?is)<(script|style|head|noscript|svg)\b[^>]*>.*?</(script|style|head|noscript|svg)\s*>`)
+	commentRe        = regexp.MustCompile(`(?s)<!--.*?-->`)
+	listItemRe       = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	blockTagRe       = regexp.MustCompile(`(?i)</?(br|p|div|ul|ol|li|h[1-6]|tr|table|section|article|header|footer|blockquote)\b[^>]*>`)
+	tagRe            = regexp.MustCompile(`<[^>]*>`)
+	urlRe            = regexp.MustCompile(`^(https?://|www\.)\S+$`)
+	identifierRe     = regexp.MustCompile(`^[0-9a-fA-F-]{16,}$`)
+)
+
+// Clean converts a raw job posting into plain text. JSON payloads are flattened
+// into "key: value" lines, HTML markup is stripped, entities are decoded and
+// whitespace is collapsed.
+func Clean(raw string) string {
+	if text, ok := flattenJSON(raw); ok {
+		return text
+	}
+	return StripHTML(raw)
+}
+
+// StripHTML removes markup from an HTML fragment and keeps the block structure as line breaks
+func StripHTML(s string) string {
+	s = invisibleBlockRe.ReplaceAllString(s, " ")
+	s = commentRe.ReplaceAllString(s, " ")
+	s = listItemRe.ReplaceAllString(s, "\n- ")
+	s = blockTagRe.ReplaceAllString(s, "\n")
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return CollapseWhitespace(s)
+}
+
+// CollapseWhitespace collapses runs of whitespace within lines and removes empty lines
+func CollapseWhitespace(s string) string {
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// EstimateTokens returns a rough token count for the given text
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + charsPerToken - 1) / charsPerToken
+}
+
+// Truncate shortens the text to about maxTokens tokens, preferably at a line or word boundary
+func Truncate(s string, maxTokens int) string {
+	if maxTokens <= 0 || EstimateTokens(s) <= maxTokens {
+		return s
+	}
+
+	runes := []rune(s)
+	cut := string(runes[:maxTokens*charsPerToken])
+	if i := strings.LastIndex(cut, "\n"); i > len(cut)/2 {
+		return cut[:i]
+	}
+	if i := strings.LastIndexAny(cut, " \t"); i > len(cut)/2 {
+		return cut[:i]
+	}
+	return cut
+}
+
+// Chunk splits the text into parts of at most maxTokens tokens along line boundaries.
+// Lines that are longer than a chunk are split with Truncate.
+func Chunk(s string, maxTokens int) []string {
+	if maxTokens <= 0 || EstimateTokens(s) <= maxTokens {
+		return []string{s}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		for EstimateTokens(line) > maxTokens {
+			flush()
+			head := Truncate(line, maxTokens)
+			chunks = append(chunks, head)
+			line = strings.TrimSpace(line[len(head):])
+		}
+		if current.Len() > 0 && EstimateTokens(current.String())+EstimateTokens(line)+1 > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return chunks
+}
+
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+}
+
+// flattenJSON walks a JSON document in document order and renders every string
+// value as a "key: value" line. URLs, identifiers and repeated values are dropped.
+func flattenJSON(raw string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	if !json.Valid([]byte(trimmed)) {
+		return "", false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var stack []*jsonFrame
+	var lines []string
+	seen := make(map[string]bool)
+
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].expectKey = true
+		}
+	}
+	currentKey := func() string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].object {
+				return stack[i].key
+			}
+		}
+		return ""
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				stack = append(stack, &jsonFrame{object: t == '{', expectKey: true})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+		case string:
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+				stack[n-1].key = t
+				stack[n-1].expectKey = false
+				continue
+			}
+			if value := StripHTML(t); !isBoilerplate(value) && !seen[value] {
+				seen[value] = true
+				lines = append(lines, formatLine(currentKey(), value))
+			}
+			valueDone()
+		default:
+			// Zahlen, Booleans und null sind IDs oder Flags und tragen keinen Text
+			valueDone()
+		}
+	}
+
+	return strings.Join(lines, "\n"), true
+}
+
+func formatLine(key, value string) string {
+	if key == "" {
+		return value
+	}
+	if strings.Contains(value, "\n") {
+		return key + ":\n" + value
+	}
+	return key + ": " + value
+}
+
+func isBoilerplate(value string) bool {
+	return value == "" || urlRe.MatchString(value) || identifierRe.MatchString(value)
+}
@@ -0,0 +1,81 @@
+package cleaner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripHTML(t *testing.T) {
+	input := `<html><head><style>p { color: red; }</style></head><body>
+		<h2>Ihre Aufgaben</h2><p>Entwicklung   von&nbsp;Microservices &amp; APIs</p>
+		<ul><li>Go</li><li>Kubernetes</li></ul><!-- tracking --><script>track()</script></body></html>`
+
+	assert.Equal(t, "Ihre Aufgaben\nEntwicklung von Microservices & APIs\n- Go\n- Kubernetes", StripHTML(input))
+}
+
+func TestClean_FlattensJSON(t *testing.T) {
+	input := `{
+		"job_id": "3f1b2c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d",
+		"title": "Go Developer",
+		"company": {"name": "Acme AG", "url": "https://acme.example"},
+		"template_text": "<p>Wir suchen <b>Verstärkung</b>.</p><ul><li>Go</li></ul>",
+		"skills": ["Go", "Go", "Docker"],
+		"is_active": true,
+		"salary": null
+	}`
+
+	assert.Equal(t,
+		"title: Go Developer\nname: Acme AG\ntemplate_text:\nWir suchen Verstärkung.\n- Go\nskills: Go\nskills: Docker",
+		Clean(input))
+}
+
+func TestTruncateAndChunk(t *testing.T) {
+	text := strings.Repeat("word ", 50) + "\n" + strings.Repeat("more ", 50)
+
+	truncated := Truncate(text, 20)
+	assert.LessOrEqual(t, EstimateTokens(truncated), 20)
+	assert.True(t, strings.HasPrefix(text, truncated))
+
+	chunks := Chunk(text, 40)
+	assert.Greater(t, len(chunks), 1)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, EstimateTokens(chunk), 40)
+	}
+	assert.Equal(t, strings.Fields(text), strings.Fields(strings.Join(chunks, " ")))
+}
+
+type recordingProcessor struct {
+	descriptions []string
+}
+
+func (p *recordingProcessor) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	p.descriptions = append(p.descriptions, job.Description)
+	return models.Job{
+		URL:        job.URL,
+		Title:      "Go Developer",
+		MustSkills: []string{strings.Fields(job.Description)[0]},
+	}, nil
+}
+
+func TestStageAndChunker(t *testing.T) {
+	inner := &recordingProcessor{}
+	stage := NewStage(Config{MaxTokens: 10, Overflow: OverflowChunk})
+	chunker := NewChunker(inner, 10)
+
+	description := "<p>Kubernetes " + strings.Repeat("x", 20) + "</p><p>Terraform " + strings.Repeat("y", 20) + "</p>"
+	cleaned, err := stage.Process(context.Background(), models.Job{URL: "https://example.com/1", Description: description})
+	assert.NoError(t, err)
+	assert.NotContains(t, cleaned.Description, "<p>")
+
+	job, err := chunker.Process(context.Background(), cleaned)
+
+	assert.NoError(t, err)
+	assert.Len(t, inner.descriptions, 2)
+	assert.Equal(t, "https://example.com/1", job.URL)
+	assert.Equal(t, []string{"Kubernetes", "Terraform"}, job.MustSkills)
+}
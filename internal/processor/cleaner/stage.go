@@ -0,0 +1,170 @@
+package cleaner
+
+import (
+	"context"
+	"strings"
+
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// OverflowTruncate cuts descriptions that exceed the token limit
+	OverflowTruncate = "truncate"
+	// OverflowChunk processes every part separately and merges the results
+	OverflowChunk = "chunk"
+)
+
+// Config controls the preprocessing. A MaxTokens of zero disables the limit.
+type Config struct {
+	MaxTokens int
+	Overflow  string
+}
+
+// Stage normalizes the job description. With OverflowTruncate, descriptions
+// exceeding the token limit are truncated; with OverflowChunk they are left
+// for the Chunker in front of the LLM.
+type Stage struct {
+	config Config
+}
+
+func NewStage(config Config) *Stage {
+	if config.Overflow == "" {
+		config.Overflow = OverflowTruncate
+	}
+	return &Stage{config: config}
+}
+
+func (s *Stage) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	original := job.Description
+	cleaned := Clean(original)
+	if cleaned == "" {
+		cleaned = original
+	}
+
+	if s.config.MaxTokens > 0 && EstimateTokens(cleaned) > s.config.MaxTokens {
+		domains.PreprocessingOverflows.WithLabelValues(s.config.Overflow).Inc()
+		if s.config.Overflow == OverflowTruncate {
+			cleaned = Truncate(cleaned, s.config.MaxTokens)
+		}
+	}
+	report(job.URL, EstimateTokens(original), EstimateTokens(cleaned))
+
+	job.Description = cleaned
+	return job, nil
+}
+
+// Chunker splits descriptions exceeding the token limit into parts, runs the
+// wrapped processor on every part and merges the results
+type Chunker struct {
+	processor processor.JobProcessor
+	maxTokens int
+}
+
+func NewChunker(processor processor.JobProcessor, maxTokens int) processor.JobProcessor {
+	return &Chunker{processor: processor, maxTokens: maxTokens}
+}
+
+func (c *Chunker) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	chunks := Chunk(job.Description, c.maxTokens)
+	if len(chunks) == 1 {
+		return c.processor.Process(ctx, job)
+	}
+
+	var merged models.Job
+	for i, chunk := range chunks {
+		part := job
+		part.Description = chunk
+
+		result, err := c.processor.Process(ctx, part)
+		if err != nil {
+			return job, err
+		}
+		if i == 0 {
+			merged = result
+			continue
+		}
+		merged = mergeJobs(merged, result)
+	}
+
+	log.Debug().Str("job_url", job.URL).Int("chunks", len(chunks)).Msg("Processed job description in chunks")
+	return merged, nil
+}
+
+func report(url string, before, after int) {
+	saved := before - after
+	if saved < 0 {
+		saved = 0
+	}
+	domains.PreprocessingTokensSaved.Observe(float64(saved))
+	log.Debug().
+		Str("job_url", url).
+		Int("tokens_before", before).
+		Int("tokens_after", after).
+		Int("tokens_saved", saved).
+		Msg("Preprocessed job description")
+}
+
+// mergeJobs fills the empty fields of base with the values of next and unites all lists
+func mergeJobs(base, next models.Job) models.Job {
+	base.Title = firstNonEmpty(base.Title, next.Title)
+	base.Description = firstNonEmpty(base.Description, next.Description)
+	base.Company = firstNonEmpty(base.Company, next.Company)
+	base.Location = firstNonEmpty(base.Location, next.Location)
+	base.EmploymentType = firstNonEmpty(base.EmploymentType, next.EmploymentType)
+	if base.Salary == nil {
+		base.Salary = next.Salary
+	}
+	base.EducationLevel = firstNonEmpty(base.EducationLevel, next.EducationLevel)
+	base.WorkCulture = firstNonEmpty(base.WorkCulture, next.WorkCulture)
+	base.PostingLanguage = firstNonEmpty(base.PostingLanguage, next.PostingLanguage)
+
+	if base.PostingDate.IsZero() {
+		base.PostingDate = next.PostingDate
+	}
+	if base.ExpirationDate.IsZero() {
+		base.ExpirationDate = next.ExpirationDate
+	}
+	if base.CompanySize == 0 {
+		base.CompanySize = next.CompanySize
+	}
+	if next.YearsOfExperience > base.YearsOfExperience {
+		base.YearsOfExperience = next.YearsOfExperience
+	}
+	base.IsActive = base.IsActive || next.IsActive
+	base.Remote = base.Remote || next.Remote
+
+	base.JobCategories = union(base.JobCategories, next.JobCategories)
+	base.MustSkills = union(base.MustSkills, next.MustSkills)
+	base.OptionalSkills = union(base.OptionalSkills, next.OptionalSkills)
+	base.Benefits = union(base.Benefits, next.Benefits)
+	base.Languages = union(base.Languages, next.Languages)
+
+	return base
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, item := range append(append([]string{}, a...), b...) {
+		key := strings.ToLower(strings.TrimSpace(item))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
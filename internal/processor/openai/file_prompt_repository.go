@@ -1,12 +1,87 @@
 package openai
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
 )
 
+const frontMatterDelimiter = "---"
+
+// Prompt is a parsed prompt template together with its front-matter metadata
+type Prompt struct {
+	Name        string
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+	template    *template.Template
+}
+
+// PromptData contains the variables that are injected into a prompt template
+type PromptData struct {
+	Description string
+	Categories  []string
+	Today       string
+}
+
+// ParsePrompt parses a prompt file consisting of a YAML front matter and a text/template body
+func ParsePrompt(name string, content []byte) (*Prompt, error) {
+	frontMatter, body, err := splitFrontMatter(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing prompt %s: %w", name, err)
+	}
+
+	prompt := &Prompt{Name: name}
+	if err := yaml.Unmarshal([]byte(frontMatter), prompt); err != nil {
+		return nil, fmt.Errorf("error parsing front matter of prompt %s: %w", name, err)
+	}
+	if prompt.Version == "" {
+		return nil, fmt.Errorf("prompt %s has no version in its front matter", name)
+	}
+
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template of prompt %s: %w", name, err)
+	}
+	prompt.template = tmpl
+
+	return prompt, nil
+}
+
+// Render executes the prompt template with the given data
+func (p *Prompt) Render(data PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := p.template.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error rendering prompt %s: %w", p.Name, err)
+	}
+	return buf.String(), nil
+}
+
+func splitFrontMatter(content string) (string, string, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, frontMatterDelimiter) {
+		return "", "", fmt.Errorf("missing front matter")
+	}
+
+	rest := strings.TrimPrefix(content, frontMatterDelimiter)
+	end := strings.Index(rest, "\n"+frontMatterDelimiter)
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated front matter")
+	}
+
+	frontMatter := rest[:end]
+	body := rest[end+len(frontMatterDelimiter)+1:]
+	return frontMatter, strings.TrimPrefix(body, "\n"), nil
+}
+
 type FilePromptRepository struct {
 	baseDir string
 }
@@ -27,11 +102,11 @@ func NewFilePromptRepository() *FilePromptRepository {
 	return &FilePromptRepository{baseDir: baseDir}
 }
 
-func (r *FilePromptRepository) GetPrompt(name string) (string, error) {
-	filename := filepath.Join(r.baseDir, name+".txt")
+func (r *FilePromptRepository) GetPrompt(name string) (*Prompt, error) {
+	filename := filepath.Join(r.baseDir, name+".tmpl")
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return "", fmt.Errorf("error reading prompt file: %w", err)
+		return nil, fmt.Errorf("error reading prompt file: %w", err)
 	}
-	return string(content), nil
+	return ParsePrompt(name, content)
 }
@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrompt(t *testing.T) {
+	content := "---\nversion: \"3\"\ndescription: test\n---\nRaise salary by 10% for: {{.Description}}\nToday: {{.Today}}\n"
+
+	prompt, err := ParsePrompt("test", []byte(content))
+	require.NoError(t, err)
+	assert.Equal(t, "3", prompt.Version)
+	assert.Equal(t, "test", prompt.Description)
+
+	rendered, err := prompt.Render(PromptData{Description: "Go Developer", Today: "2024-10-20"})
+	require.NoError(t, err)
+	assert.Equal(t, "Raise salary by 10% for: Go Developer\nToday: 2024-10-20\n", rendered)
+}
+
+func TestParsePrompt_RequiresVersion(t *testing.T) {
+	_, err := ParsePrompt("test", []byte("---\ndescription: test\n---\nbody"))
+	assert.Error(t, err)
+
+	_, err = ParsePrompt("test", []byte("no front matter"))
+	assert.Error(t, err)
+}
+
+func TestFilePromptRepository_JobExtractionUsesModelCategories(t *testing.T) {
+	prompt, err := NewFilePromptRepository().GetPrompt("job_extraction")
+	require.NoError(t, err)
+
+	rendered, err := prompt.Render(PromptData{
+		Description: "Description",
+		Categories:  models.ValidJobCategories,
+		Today:       "2024-10-20",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, strings.Join(models.ValidJobCategories, ", "))
+	assert.NotContains(t, rendered, "IoT_ENGINEER")
+}
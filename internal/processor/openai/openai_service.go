@@ -3,12 +3,16 @@ package openai
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"job-scraper/internal/apperrors"
+	"job-scraper/internal/metrics/domains"
 	"job-scraper/internal/models"
 	"job-scraper/internal/parser"
+	"job-scraper/internal/processor"
 	"net/http"
 	"strings"
 	"time"
@@ -17,10 +21,13 @@ import (
 )
 
 type Processor struct {
-	client     HTTPClient
-	config     Config
-	promptRepo PromptRepository
-	jobParser  *parser.JobParser
+	client        HTTPClient
+	config        Config
+	promptRepo    PromptRepository
+	jobParser     *parser.JobParser
+	usageRecorder processor.UsageRecorder
+	budget        processor.BudgetGuard
+	cache         ExtractionCache
 }
 
 type HTTPClient interface {
@@ -28,6 +35,7 @@ type HTTPClient interface {
 }
 
 type Config struct {
+	Provider    string // name reported in usage records, defaults to "openai"
 	APIURL      string
 	APIKey      string
 	Model       string
@@ -40,10 +48,20 @@ type Config struct {
 }
 
 type PromptRepository interface {
-	GetPrompt(name string) (string, error)
+	GetPrompt(name string) (*Prompt, error)
+}
+
+// ExtractionCache stores extraction results keyed by a content hash.
+// Get returns nil without error on a cache miss.
+type ExtractionCache interface {
+	Get(ctx context.Context, key string) (*models.Job, error)
+	Put(ctx context.Context, key string, job models.Job) error
 }
 
 func NewProcessor(config Config, promptRepo PromptRepository) *Processor {
+	if config.Provider == "" {
+		config.Provider = "openai"
+	}
 	return &Processor{
 		client:     &http.Client{},
 		config:     config,
@@ -52,8 +70,48 @@ func NewProcessor(config Config, promptRepo PromptRepository) *Processor {
 	}
 }
 
+// SetUsageRecorder registers a recorder that is notified about the tokens used per request
+func (p *Processor) SetUsageRecorder(recorder processor.UsageRecorder) {
+	p.usageRecorder = recorder
+}
+
+// SetBudget rejects requests to the API while the budget is exhausted. Cached
+// results cost no tokens and are still returned.
+func (p *Processor) SetBudget(budget processor.BudgetGuard) {
+	p.budget = budget
+}
+
+// SetCache enables caching of extraction results
+func (p *Processor) SetCache(cache ExtractionCache) {
+	p.cache = cache
+}
+
 func (p *Processor) Process(ctx context.Context, job models.Job) (models.Job, error) {
-	updatedJob, err := p.extractJobInfo(ctx, job.Description)
+	prompt, err := p.promptRepo.GetPrompt("job_extraction")
+	if err != nil {
+		return job, apperrors.NewProcessingError(job.ID.Hex(), "Failed to get prompt", err)
+	}
+
+	key := cacheKey(job.Description, prompt.Version, p.config.Model)
+	if cached := p.cachedJob(ctx, key); cached != nil {
+		cached.URL = job.URL
+		cached.PromptVersion = prompt.Version
+		cached.ExtractionMethod = models.ExtractionMethodLLM
+		cached.Source = job.Source
+		log.Info().
+			Str("job_url", job.URL).
+			Str("job_title", cached.Title).
+			Msg("Using cached extraction result")
+		return *cached, nil
+	}
+
+	if p.budget != nil {
+		if err := p.budget.Check(); err != nil {
+			return job, err
+		}
+	}
+
+	updatedJob, err := p.extractJobInfo(ctx, prompt, job.Description)
 	if err != nil {
 		return job, apperrors.NewProcessingError(
 			job.ID.Hex(),
@@ -62,8 +120,17 @@ func (p *Processor) Process(ctx context.Context, job models.Job) (models.Job, er
 		)
 	}
 
+	if p.cache != nil {
+		if err := p.cache.Put(ctx, key, *updatedJob); err != nil {
+			log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to cache extraction result")
+		}
+	}
+
 	// Preserve the original URL and any other fields that should not be overwritten
 	updatedJob.URL = job.URL
+	updatedJob.PromptVersion = prompt.Version
+	updatedJob.ExtractionMethod = models.ExtractionMethodLLM
+	updatedJob.Source = job.Source
 
 	log.Info().
 		Str("job_title", updatedJob.Title).
@@ -73,10 +140,14 @@ func (p *Processor) Process(ctx context.Context, job models.Job) (models.Job, er
 	return *updatedJob, nil
 }
 
-func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (*models.Job, error) {
-	prompt, err := p.promptRepo.GetPrompt("job_extraction")
+func (p *Processor) extractJobInfo(ctx context.Context, prompt *Prompt, jobDescription string) (*models.Job, error) {
+	content, err := prompt.Render(PromptData{
+		Description: jobDescription,
+		Categories:  models.ValidJobCategories,
+		Today:       time.Now().Format("2006-01-02"),
+	})
 	if err != nil {
-		return nil, apperrors.NewProcessingError("", "Failed to get prompt", err)
+		return nil, apperrors.NewProcessingError("", "Failed to render prompt", err)
 	}
 
 	payload := map[string]interface{}{
@@ -84,7 +155,7 @@ func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (
 		"messages": []map[string]string{
 			{
 				"role":    "user",
-				"content": fmt.Sprintf(prompt, jobDescription),
+				"content": content,
 			},
 		},
 		"temperature":       p.config.Temperature,
@@ -105,7 +176,10 @@ func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.config.APIKey))
+	// Lokale OpenAI-kompatible Server brauchen meist keinen Key
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.config.APIKey))
+	}
 
 	response, err := p.client.Do(req)
 	if err != nil {
@@ -113,6 +187,15 @@ func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusTooManyRequests {
+		body, _ := io.ReadAll(response.Body)
+		return nil, apperrors.NewBaseError(
+			apperrors.ErrCodeRateLimited,
+			"Rate limit exceeded",
+			fmt.Errorf("body: %s", string(body)),
+		)
+	}
+
 	if response.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(response.Body)
 		return nil, apperrors.NewProcessingError(
@@ -128,12 +211,23 @@ func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
 		return nil, apperrors.NewProcessingError("", "Error decoding response", err)
 	}
 
+	p.recordUsage(processor.Usage{
+		Provider:         p.config.Provider,
+		Model:            p.config.Model,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+	})
+
 	if len(result.Choices) == 0 {
 		return nil, fmt.Errorf("no choices in response")
 	}
@@ -165,9 +259,44 @@ func (p *Processor) extractJobInfo(ctx context.Context, jobDescription string) (
 	return job, nil
 }
 
+func (p *Processor) cachedJob(ctx context.Context, key string) *models.Job {
+	if p.cache == nil {
+		return nil
+	}
+
+	cached, err := p.cache.Get(ctx, key)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read extraction cache")
+	}
+	if cached == nil {
+		domains.ExtractionCacheMisses.WithLabelValues("openai").Inc()
+		return nil
+	}
+
+	domains.ExtractionCacheHits.WithLabelValues("openai").Inc()
+	return cached
+}
+
+func (p *Processor) recordUsage(usage processor.Usage) {
+	domains.OpenAITokensUsed.WithLabelValues(usage.Model, "prompt").Add(float64(usage.PromptTokens))
+	domains.OpenAITokensUsed.WithLabelValues(usage.Model, "completion").Add(float64(usage.CompletionTokens))
+
+	if p.usageRecorder != nil {
+		p.usageRecorder.RecordUsage(usage)
+	}
+}
+
 func extractJSONFromContent(content string) (string, error) {
 	content = strings.TrimSpace(content)
 	content = strings.TrimPrefix(content, "```json")
 	content = strings.TrimSuffix(content, "```")
 	return strings.TrimSpace(content), nil
 }
+
+// cacheKey hashes the normalized description together with the prompt version and the model,
+// so that a change of either invalidates the cached results
+func cacheKey(description, promptVersion, model string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(description)), " ")
+	sum := sha256.Sum256([]byte(model + "\x00" + promptVersion + "\x00" + normalized))
+	return hex.EncodeToString(sum[:])
+}
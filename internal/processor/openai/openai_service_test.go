@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"job-scraper/internal/apperrors"
 	"job-scraper/internal/models"
 	"net/http"
 	"net/http/httptest"
@@ -20,9 +21,18 @@ type MockPromptRepository struct {
 	mock.Mock
 }
 
-func (m *MockPromptRepository) GetPrompt(name string) (string, error) {
+func (m *MockPromptRepository) GetPrompt(name string) (*Prompt, error) {
 	args := m.Called(name)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(*Prompt), args.Error(1)
+}
+
+func testPrompt(t *testing.T) *Prompt {
+	t.Helper()
+	prompt, err := ParsePrompt("job_extraction", []byte("---\nversion: \"test\"\n---\nTest prompt: {{.Description}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prompt
 }
 
 func TestProcessor_Process(t *testing.T) {
@@ -75,7 +85,7 @@ func TestProcessor_Process(t *testing.T) {
 	processor.client = mockClient
 
 	// Set up expectations
-	mockRepo.On("GetPrompt", "job_extraction").Return("Test prompt", nil)
+	mockRepo.On("GetPrompt", "job_extraction").Return(testPrompt(t), nil)
 
 	// Call the Process method
 	ctx := context.Background()
@@ -95,7 +105,8 @@ func TestProcessor_Process(t *testing.T) {
 	assert.Equal(t, []string{"SOFTWARE_DEVELOPER"}, processedJob.JobCategories)
 	assert.Equal(t, []string{"Go", "MongoDB"}, processedJob.MustSkills)
 	assert.Equal(t, []string{"Docker", "Kubernetes"}, processedJob.OptionalSkills)
-	assert.Equal(t, "100000-120000", processedJob.Salary)
+	// Der Lohn wird erst von der Stage parse_salary strukturiert
+	assert.Equal(t, &models.Salary{Text: "100000-120000"}, processedJob.Salary)
 	assert.Equal(t, 3, processedJob.YearsOfExperience)
 	assert.Equal(t, "Bachelor's", processedJob.EducationLevel)
 	assert.Equal(t, []string{"Health Insurance", "401k"}, processedJob.Benefits)
@@ -103,6 +114,7 @@ func TestProcessor_Process(t *testing.T) {
 	assert.Equal(t, "Agile", processedJob.WorkCulture)
 	assert.True(t, processedJob.Remote)
 	assert.Equal(t, []string{"English", "Spanish"}, processedJob.Languages)
+	assert.Equal(t, "test", processedJob.PromptVersion)
 
 	// Verify that the expectations were met
 	mockRepo.AssertExpectations(t)
@@ -144,3 +156,82 @@ func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		Header:     make(http.Header),
 	}, nil
 }
+
+type memoryCache struct {
+	entries map[string]models.Job
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (*models.Job, error) {
+	if job, ok := c.entries[key]; ok {
+		return &job, nil
+	}
+	return nil, nil
+}
+
+func (c *memoryCache) Put(ctx context.Context, key string, job models.Job) error {
+	c.entries[key] = job
+	return nil
+}
+
+type countingTransport struct {
+	mockTransport
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	return t.mockTransport.RoundTrip(req)
+}
+
+func TestProcessor_ProcessUsesCache(t *testing.T) {
+	transport := &countingTransport{}
+	mockRepo := new(MockPromptRepository)
+	mockRepo.On("GetPrompt", "job_extraction").Return(testPrompt(t), nil)
+
+	processor := NewProcessor(Config{APIURL: "http://openai.test", Model: "test-model"}, mockRepo)
+	processor.client = &http.Client{Transport: transport}
+	processor.SetCache(&memoryCache{entries: map[string]models.Job{}})
+
+	ctx := context.Background()
+	first, err := processor.Process(ctx, models.Job{URL: "https://example.com/1", Description: "Go  Developer\nZürich"})
+	assert.NoError(t, err)
+
+	// Same content with different whitespace and casing under a new URL
+	second, err := processor.Process(ctx, models.Job{URL: "https://example.com/2", Description: "go developer zürich"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, transport.calls)
+	assert.Equal(t, first.Title, second.Title)
+	assert.Equal(t, "https://example.com/2", second.URL)
+}
+
+// exhaustedBudget lehnt jede Anfrage ab
+type exhaustedBudget struct{}
+
+func (exhaustedBudget) Check() error {
+	return apperrors.NewBudgetExceededError("daily", time.Now().Add(time.Hour))
+}
+
+func TestProcessor_ProcessServesCacheWithExhaustedBudget(t *testing.T) {
+	transport := &countingTransport{}
+	mockRepo := new(MockPromptRepository)
+	mockRepo.On("GetPrompt", "job_extraction").Return(testPrompt(t), nil)
+
+	processor := NewProcessor(Config{APIURL: "http://openai.test", Model: "test-model"}, mockRepo)
+	processor.client = &http.Client{Transport: transport}
+	processor.SetCache(&memoryCache{entries: map[string]models.Job{}})
+
+	ctx := context.Background()
+	_, err := processor.Process(ctx, models.Job{URL: "https://example.com/1", Description: "Go Developer"})
+	assert.NoError(t, err)
+
+	processor.SetBudget(exhaustedBudget{})
+	cached, err := processor.Process(ctx, models.Job{URL: "https://example.com/2", Description: "Go Developer"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Job", cached.Title)
+
+	_, err = processor.Process(ctx, models.Job{URL: "https://example.com/3", Description: "Rust Developer"})
+	var budgetErr *apperrors.BudgetExceededError
+	assert.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, 1, transport.calls)
+}
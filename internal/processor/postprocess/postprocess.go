@@ -0,0 +1,178 @@
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/geo"
+	"job-scraper/internal/langdetect"
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/processor/cleaner"
+	"job-scraper/internal/salary"
+	"job-scraper/internal/skills"
+)
+
+// ValidateCategories normalizes the extracted categories and removes unknown ones.
+// It returns the cleaned job together with a validation error if categories were dropped.
+func ValidateCategories(ctx context.Context, job models.Job) (models.Job, error) {
+	seen := make(map[string]bool, len(job.JobCategories))
+	valid := make([]string, 0, len(job.JobCategories))
+	var invalid []string
+
+	for _, category := range job.JobCategories {
+		normalized := strings.ToUpper(strings.Join(strings.Fields(category), "_"))
+		if !models.IsValidJobCategory(normalized) {
+			invalid = append(invalid, category)
+			continue
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			valid = append(valid, normalized)
+		}
+	}
+
+	job.JobCategories = valid
+	if len(invalid) > 0 {
+		return job, apperrors.NewBaseError(
+			apperrors.ErrCodeValidation,
+			fmt.Sprintf("invalid job categories: %s", strings.Join(invalid, ", ")),
+			nil,
+		)
+	}
+	return job, nil
+}
+
+// NormalizeSkills trims and deduplicates the skill lists. Skills listed as
+// required are removed from the optional skills.
+func NormalizeSkills(ctx context.Context, job models.Job) (models.Job, error) {
+	seen := make(map[string]bool)
+	job.MustSkills = dedupe(job.MustSkills, seen)
+	job.OptionalSkills = dedupe(job.OptionalSkills, seen)
+	return job, nil
+}
+
+func dedupe(skills []string, seen map[string]bool) []string {
+	result := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		skill = strings.Join(strings.Fields(skill), " ")
+		key := strings.ToLower(skill)
+		if skill == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, skill)
+	}
+	return result
+}
+
+// NewSkillNormalizer returns a stage that maps the skills to the canonical names
+// of the taxonomy after normalizing them like NormalizeSkills. Skills missing in
+// the taxonomy are kept and only counted, so they can be curated later.
+func NewSkillNormalizer(taxonomy *skills.Taxonomy) processor.JobProcessor {
+	return processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		job, _ = NormalizeSkills(ctx, job)
+
+		var mustUnmapped, optionalUnmapped []string
+		total := len(job.MustSkills) + len(job.OptionalSkills)
+		job.MustSkills, mustUnmapped = taxonomy.Canonicalize(job.MustSkills)
+		job.OptionalSkills, optionalUnmapped = taxonomy.Canonicalize(job.OptionalSkills)
+		job.OptionalSkills = without(job.OptionalSkills, job.MustSkills)
+		job.SkillTaxonomy = taxonomy.Version()
+
+		unmapped := len(mustUnmapped) + len(optionalUnmapped)
+		domains.SkillsNormalized.WithLabelValues("mapped").Add(float64(total - unmapped))
+		domains.SkillsNormalized.WithLabelValues("unmapped").Add(float64(unmapped))
+
+		return job, nil
+	})
+}
+
+// without removes the skills that are contained in exclude
+func without(skillList, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, skill := range exclude {
+		excluded[skills.Key(skill)] = true
+	}
+
+	result := make([]string, 0, len(skillList))
+	for _, skill := range skillList {
+		if !excluded[skills.Key(skill)] {
+			result = append(result, skill)
+		}
+	}
+	return result
+}
+
+// ParseSalary converts the extracted salary into a structured range with yearly CHF
+// amounts. Salary texts are parsed, structured salaries get the default currency and
+// are annualized. If no salary was extracted, the posting is searched for one.
+func ParseSalary(ctx context.Context, job models.Job) (models.Job, error) {
+	switch {
+	case job.Salary.IsStructured():
+		if job.Salary.Currency == "" {
+			job.Salary.Currency = salary.DefaultCurrency
+		}
+		salary.Annualize(job.Salary)
+	case job.Salary != nil:
+		job.Salary = salary.FromText(job.Salary.Text)
+	default:
+		job.Salary = salary.Find(PostingText(job))
+	}
+
+	result := "parsed"
+	switch {
+	case job.Salary == nil:
+		result = "missing"
+	case !job.Salary.IsStructured():
+		result = "text"
+	}
+	domains.SalariesParsed.WithLabelValues(result).Inc()
+
+	return job, nil
+}
+
+// NewLocationNormalizer returns a stage that resolves the location text to
+// structured places. The text in Location is kept as extracted.
+func NewLocationNormalizer(gazetteer *geo.Gazetteer) processor.JobProcessor {
+	return processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.Locations = gazetteer.Resolve(job.Location)
+
+		result := "resolved"
+		if len(job.Locations) == 0 {
+			result = "unresolved"
+		}
+		domains.LocationsNormalized.WithLabelValues(result).Inc()
+
+		return job, nil
+	})
+}
+
+// NewLanguageDetector returns a stage that records the language the posting is
+// written in. It uses the raw source if available, as the LLM may summarize the
+// description in another language.
+func NewLanguageDetector(detector *langdetect.Detector) processor.JobProcessor {
+	return processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.PostingLanguage = detector.Detect(PostingText(job))
+
+		language := job.PostingLanguage
+		if language == "" {
+			language = "unknown"
+		}
+		domains.LanguagesDetected.WithLabelValues(language).Inc()
+
+		return job, nil
+	})
+}
+
+// PostingText returns the text of the posting as published: the cleaned raw
+// source if available, the description otherwise
+func PostingText(job models.Job) string {
+	if job.Source != nil && job.Source.RawPayload != "" {
+		return cleaner.Clean(job.Source.RawPayload)
+	}
+	return job.Description
+}
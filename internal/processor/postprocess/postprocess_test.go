@@ -0,0 +1,112 @@
+package postprocess
+
+import (
+	"context"
+	"testing"
+
+	"job-scraper/internal/geo"
+	"job-scraper/internal/langdetect"
+	"job-scraper/internal/models"
+	"job-scraper/internal/skills"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCategories(t *testing.T) {
+	input := models.Job{JobCategories: []string{"machine learning engineer", "MACHINE_LEARNING_ENGINEER", "ASTRONAUT"}}
+
+	job, err := ValidateCategories(context.Background(), input)
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"MACHINE_LEARNING_ENGINEER"}, job.JobCategories)
+	assert.Len(t, input.JobCategories, 3, "input must not be modified")
+}
+
+func TestNormalizeSkills(t *testing.T) {
+	job, err := NormalizeSkills(context.Background(), models.Job{
+		MustSkills:     []string{" Go ", "go", "Docker", ""},
+		OptionalSkills: []string{"docker", "Apache  Kafka"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Go", "Docker"}, job.MustSkills)
+	assert.Equal(t, []string{"Apache Kafka"}, job.OptionalSkills)
+}
+
+func TestSkillNormalizer(t *testing.T) {
+	taxonomy, err := skills.Parse([]byte(`
+version: "test"
+skills:
+  - name: Go
+    category: language
+    aliases: [golang, go lang]
+  - name: Kafka
+    category: tool
+    aliases: [apache kafka]
+`))
+	assert.NoError(t, err)
+
+	job, err := NewSkillNormalizer(taxonomy).Process(context.Background(), models.Job{
+		MustSkills:     []string{"Golang", "GO", "go lang", "Terraform"},
+		OptionalSkills: []string{"golang", "Apache  Kafka"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Go", "Terraform"}, job.MustSkills)
+	assert.Equal(t, []string{"Kafka"}, job.OptionalSkills)
+	assert.Equal(t, "test", job.SkillTaxonomy)
+}
+
+func TestParseSalary(t *testing.T) {
+	ctx := context.Background()
+
+	job, err := ParseSalary(ctx, models.Job{Salary: &models.Salary{Text: "CHF 8'500.- pro Monat brutto"}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, job.Salary) {
+		assert.Equal(t, 8500.0, job.Salary.Min)
+		assert.Equal(t, models.SalaryPeriodMonth, job.Salary.Period)
+		assert.Equal(t, 102000.0, job.Salary.AnnualMinCHF)
+	}
+
+	job, _ = ParseSalary(ctx, models.Job{Salary: &models.Salary{Min: 80000, Max: 90000, Period: models.SalaryPeriodYear}})
+	assert.Equal(t, "CHF", job.Salary.Currency)
+	assert.Equal(t, 90000.0, job.Salary.AnnualMaxCHF)
+
+	job, _ = ParseSalary(ctx, models.Job{Salary: &models.Salary{Text: "Not specified"}})
+	assert.Nil(t, job.Salary)
+
+	// Ohne extrahierten Lohn wird das Inserat durchsucht
+	job, _ = ParseSalary(ctx, models.Job{Description: "Backend Engineer\nLohn: 100-120k CHF p.a."})
+	if assert.NotNil(t, job.Salary) {
+		assert.Equal(t, 120000.0, job.Salary.Max)
+	}
+}
+
+func TestLocationNormalizer(t *testing.T) {
+	gazetteer, err := geo.Load()
+	assert.NoError(t, err)
+
+	job, err := NewLocationNormalizer(gazetteer).Process(context.Background(), models.Job{Location: "8001 Zürich / Lausanne"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "8001 Zürich / Lausanne", job.Location)
+	if assert.Len(t, job.Locations, 2) {
+		assert.Equal(t, models.Place{City: "Zürich", PostalCode: "8001", Canton: "ZH", Country: "CH", Lat: 47.3769, Lon: 8.5417}, job.Locations[0])
+		assert.Equal(t, "VD", job.Locations[1].Canton)
+	}
+}
+
+func TestLanguageDetector(t *testing.T) {
+	detector, err := langdetect.New()
+	assert.NoError(t, err)
+
+	job, err := NewLanguageDetector(detector).Process(context.Background(), models.Job{
+		Description: "We are looking for an experienced backend engineer who enjoys working with distributed systems.",
+		Source: &models.SourcePayload{
+			RawPayload: "<p>Nous recherchons un ingénieur backend expérimenté qui aime travailler avec des systèmes distribués.</p>",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fr", job.PostingLanguage)
+}
@@ -8,3 +8,10 @@ import (
 type JobProcessor interface {
 	Process(ctx context.Context, job models.Job) (models.Job, error)
 }
+
+// Func adapts an ordinary function to the JobProcessor interface
+type Func func(ctx context.Context, job models.Job) (models.Job, error)
+
+func (f Func) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	return f(ctx, job)
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Provider is a processor the router can send jobs to. Weight is the share of
+// jobs for which the provider is tried first; providers with weight 0 only
+// serve as fallbacks.
+type Provider struct {
+	Name      string
+	Processor processor.JobProcessor
+	Weight    int
+}
+
+// Router sends every job to a primary provider chosen by weight and falls back
+// to the remaining providers, in configured order, if the primary fails.
+// The primary is derived from a hash of the job URL, so a job is always routed
+// to the same provider and A/B comparisons stay stable across reprocessing.
+type Router struct {
+	providers   []Provider
+	totalWeight int
+}
+
+func New(providers ...Provider) (*Router, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("router requires at least one provider")
+	}
+
+	totalWeight := 0
+	for _, p := range providers {
+		if p.Weight < 0 {
+			return nil, fmt.Errorf("provider %s has a negative weight", p.Name)
+		}
+		totalWeight += p.Weight
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("at least one provider needs a weight greater than 0")
+	}
+
+	return &Router{providers: providers, totalWeight: totalWeight}, nil
+}
+
+func (r *Router) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	var firstErr error
+
+	for i, provider := range r.order(job) {
+		start := time.Now()
+		result, err := provider.Processor.Process(ctx, job)
+		duration := time.Since(start).Seconds()
+
+		if err == nil {
+			route := "primary"
+			if i > 0 {
+				route = "fallback"
+			}
+			domains.ProcessorDuration.WithLabelValues(provider.Name, "success").Observe(duration)
+			domains.ProcessorRoutedJobs.WithLabelValues(provider.Name, route).Inc()
+
+			result.Provider = provider.Name
+			return result, nil
+		}
+
+		domains.ProcessorDuration.WithLabelValues(provider.Name, "error").Observe(duration)
+		domains.ProcessorErrors.WithLabelValues(provider.Name, errorType(err)).Inc()
+
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		log.Warn().Err(err).Str("provider", provider.Name).Str("job_url", job.URL).Msg("Provider failed")
+	}
+
+	// Der Fehler des primären Providers entscheidet, z.B. ob der Job in die Budget-Queue kommt
+	return job, firstErr
+}
+
+// order returns the primary provider of the job followed by all other providers
+func (r *Router) order(job models.Job) []Provider {
+	primary := r.primary(job)
+
+	ordered := make([]Provider, 0, len(r.providers))
+	ordered = append(ordered, r.providers[primary])
+	for i, p := range r.providers {
+		if i != primary {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered
+}
+
+func (r *Router) primary(job models.Job) int {
+	var bucket int
+	if job.URL != "" {
+		h := fnv.New32a()
+		h.Write([]byte(job.URL))
+		bucket = int(h.Sum32() % uint32(r.totalWeight))
+	} else {
+		bucket = rand.Intn(r.totalWeight)
+	}
+
+	for i, p := range r.providers {
+		if bucket < p.Weight {
+			return i
+		}
+		bucket -= p.Weight
+	}
+	return 0
+}
+
+func errorType(err error) string {
+	var budgetErr *apperrors.BudgetExceededError
+	switch {
+	case errors.As(err, &budgetErr):
+		return "budget_exceeded"
+	case apperrors.HasCode(err, apperrors.ErrCodeRateLimited):
+		return "rate_limited"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case apperrors.HasCode(err, apperrors.ErrCodeParser):
+		return "parse_error"
+	default:
+		return "error"
+	}
+}
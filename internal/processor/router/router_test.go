@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func succeeding(title string) processor.Func {
+	return func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.Title = title
+		return job, nil
+	}
+}
+
+func failingWith(err error) processor.Func {
+	return func(ctx context.Context, job models.Job) (models.Job, error) {
+		return job, err
+	}
+}
+
+func TestRouter_FallsBackOnRateLimit(t *testing.T) {
+	rateLimited := apperrors.NewBaseError(apperrors.ErrCodeRateLimited, "Rate limit exceeded", nil)
+	r, err := New(
+		Provider{Name: "openai", Processor: failingWith(rateLimited), Weight: 100},
+		Provider{Name: "local", Processor: succeeding("from local")},
+	)
+	assert.NoError(t, err)
+
+	job, err := r.Process(context.Background(), models.Job{URL: "https://example.com/1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "from local", job.Title)
+	assert.Equal(t, "local", job.Provider)
+	assert.Equal(t, "rate_limited", errorType(rateLimited))
+}
+
+func TestRouter_ReturnsPrimaryErrorIfAllFail(t *testing.T) {
+	primaryErr := errors.New("primary down")
+	r, _ := New(
+		Provider{Name: "openai", Processor: failingWith(primaryErr), Weight: 1},
+		Provider{Name: "local", Processor: failingWith(errors.New("local down"))},
+	)
+
+	_, err := r.Process(context.Background(), models.Job{URL: "https://example.com/1"})
+
+	assert.ErrorIs(t, err, primaryErr)
+}
+
+func TestRouter_SplitIsStablePerURL(t *testing.T) {
+	r, _ := New(
+		Provider{Name: "a", Processor: succeeding("a"), Weight: 80},
+		Provider{Name: "b", Processor: succeeding("b"), Weight: 20},
+	)
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		job := models.Job{URL: fmt.Sprintf("https://example.com/job/%d", i)}
+		first, _ := r.Process(context.Background(), job)
+		second, _ := r.Process(context.Background(), job)
+		assert.Equal(t, first.Provider, second.Provider)
+		counts[first.Provider]++
+	}
+
+	assert.InDelta(t, 800, counts["a"], 60)
+	assert.InDelta(t, 200, counts["b"], 60)
+}
+
+func TestNew_RequiresWeight(t *testing.T) {
+	_, err := New(Provider{Name: "local", Processor: succeeding("x")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package rules
+
+// skillPatterns maps the canonical skill name to the regular expression that detects it.
+// Short or ambiguous names are matched case-sensitively.
+var skillPatterns = map[string]string{
+	"Go":               `\bGo\b|(?i:\bgolang\b)`,
+	"Java":             `(?i)\bjava\b`,
+	"Kotlin":           `(?i)\bkotlin\b`,
+	"Scala":            `(?i)\bscala\b`,
+	"Python":           `(?i)\bpython\b`,
+	"JavaScript":       `(?i)\bjavascript\b`,
+	"TypeScript":       `(?i)\btypescript\b`,
+	"C#":               `(?i)\bc#`,
+	".NET":             `(?i)\.net\b`,
+	"C++":              `(?i)\bc\+\+`,
+	"Rust":             `\bRust\b`,
+	"PHP":              `(?i)\bphp\b`,
+	"Ruby":             `\bRuby\b`,
+	"Swift":            `\bSwift\b`,
+	"SQL":              `(?i)\bsql\b`,
+	"PostgreSQL":       `(?i)\bpostgres(ql)?\b`,
+	"MongoDB":          `(?i)\bmongo(db)?\b`,
+	"Oracle":           `\bOracle\b`,
+	"React":            `(?i)\breact(\.js|js)?\b`,
+	"Angular":          `(?i)\bangular\b`,
+	"Vue.js":           `(?i)\bvue(\.js|js)?\b`,
+	"Node.js":          `(?i)\bnode(\.js|js)\b`,
+	"Spring":           `\bSpring( Boot)?\b`,
+	"Docker":           `(?i)\bdocker\b`,
+	"Kubernetes":       `(?i)\bkubernetes\b|\bk8s\b`,
+	"Terraform":        `(?i)\bterraform\b`,
+	"Ansible":          `(?i)\bansible\b`,
+	"AWS":              `\bAWS\b|(?i:amazon web services)`,
+	"Azure":            `(?i)\bazure\b`,
+	"Google Cloud":     `\bGCP\b|(?i:google cloud)`,
+	"Linux":            `(?i)\blinux\b`,
+	"Git":              `\bGit\b|(?i:\bgitlab\b|\bgithub\b)`,
+	"CI/CD":            `(?i)\bci\s*/\s*cd\b`,
+	"Kafka":            `(?i)\bkafka\b`,
+	"Microservices":    `(?i)\bmicro-?services?\b`,
+	"REST":             `\bREST(ful)?\b`,
+	"Machine Learning": `(?i)\bmachine learning\b`,
+	"SAP":              `\bSAP\b`,
+	"Scrum":            `(?i)\bscrum\b`,
+	"ITIL":             `\bITIL\b`,
+	"Power BI":         `(?i)\bpower ?bi\b`,
+}
+
+// languagePatterns maps the language name used by the LLM extraction to its
+// German, French and English spellings, including compounds like "Deutschkenntnisse"
+var languagePatterns = map[string]string{
+	"German":  `(?i)\b(deutsch|german|allemand)(\b|kenntnis|sprach)`,
+	"English": `(?i)\b(englisch|english|anglais)(\b|kenntnis|sprach)`,
+	"French":  `(?i)\b(französisch|franzoesisch|french|français)(\b|kenntnis|sprach)`,
+	"Italian": `(?i)\b(italienisch|italian|italien)(\b|kenntnis|sprach)`,
+	"Spanish": `(?i)\b(spanisch|spanish|espagnol)(\b|kenntnis|sprach)`,
+}
+
+// articles are removed from the start of company names found in free text
+var articles = []string{"Die ", "Der ", "Das ", "The ", "La ", "Le ", "L'"}
+
+// swissCities are matched if the payload contains no explicit location field
+var swissCities = []string{
+	"Zürich", "Bern", "Basel", "Genf", "Genève", "Lausanne", "Luzern", "St. Gallen",
+	"Winterthur", "Lugano", "Zug", "Baden", "Aarau", "Olten", "Biel", "Thun",
+	"Schaffhausen", "Chur", "Fribourg", "Neuchâtel", "Sion", "Solothurn", "Wil",
+}
+
+// Feldnamen, unter denen die Werte in den flachgeklopften Payloads stehen
+var (
+	titleKeys      = []string{"title", "job_title", "position", "jobtitle"}
+	companyKeys    = []string{"company_name", "company", "employer", "organisation", "organization"}
+	locationKeys   = []string{"place", "location", "city", "workplace", "job_location"}
+	postingKeys    = []string{"publication_date", "posting_date", "published", "date_posted", "initial_publication_date"}
+	expirationKeys = []string{"expiration_date", "end_date", "valid_through", "expires"}
+)
@@ -0,0 +1,279 @@
+package rules
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+)
+
+const maxSummaryLength = 300
+
+var (
+	keyValueRe      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_ ]{0,40}):\s*(.*)$`)
+	companyRe       = regexp.MustCompile(`\b([A-ZÄÖÜ][\w&.\-]*(?:[ \t]+[A-ZÄÖÜ&][\w&.\-]*){0,3}[ \t]+(?:AG|GmbH|SA|Sàrl|Ltd\.?|Inc\.?|SE))\b`)
+	workloadRe      = regexp.MustCompile(`\b(\d{2,3})\s*%?\s*(?:-|–|bis|to)\s*(\d{2,3})\s*%|\b(\d{2,3})\s*%`)
+	isoDateRe       = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2})`)
+	swissDateRe     = regexp.MustCompile(`\b(\d{1,2})\.(\d{1,2})\.(\d{4})\b`)
+	experienceRe    = regexp.MustCompile(`(?i)\b(\d{1,2})\s*\+?\s*(?:jahre|years|ans)\b`)
+	remoteRe        = regexp.MustCompile(`(?i)\b(remote|home ?office|homeoffice|hybrid|télétravail)\b`)
+	optionalLineRe  = regexp.MustCompile(`(?i)(von vorteil|wünschenswert|nice to have|ein plus|is a plus|ideally|idealerweise|advantage|optional)`)
+	sentenceEndRe   = regexp.MustCompile(`[.!?](\s|$)`)
+	employmentTypes = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		{"Internship", regexp.MustCompile(`(?i)\b(praktikum|internship)\b`)},
+		{"Contract", regexp.MustCompile(`(?i)\b(befristet|temporary|freelance|contract)\b|(?i)\btemporär`)},
+		{"Part-time", regexp.MustCompile(`(?i)\b(teilzeit|part-time|part time)\b`)},
+		{"Full-time", regexp.MustCompile(`(?i)\b(vollzeit|full-time|full time|festanstellung|permanent)\b`)},
+	}
+)
+
+type compiledPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// Processor extracts job fields with keyword dictionaries and regular expressions.
+// It is deterministic and does not call any external service.
+type Processor struct {
+	skills    []compiledPattern
+	languages []compiledPattern
+	cities    []compiledPattern
+}
+
+func NewProcessor() *Processor {
+	cities := make([]compiledPattern, 0, len(swissCities))
+	for _, city := range swissCities {
+		cities = append(cities, compiledPattern{name: city, pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(city) + `\b`)})
+	}
+
+	return &Processor{
+		skills:    compile(skillPatterns),
+		languages: compile(languagePatterns),
+		cities:    cities,
+	}
+}
+
+func (p *Processor) Process(ctx context.Context, job models.Job) (models.Job, error) {
+	text := job.Description
+	if strings.TrimSpace(text) == "" {
+		return job, apperrors.NewProcessingError(job.ID.Hex(), "rule-based extraction requires a description", nil)
+	}
+
+	fields, body := splitFields(text)
+
+	result := models.Job{
+		ID:                job.ID,
+		URL:               job.URL,
+		Title:             firstField(fields, titleKeys),
+		Company:           firstField(fields, companyKeys),
+		Location:          firstField(fields, locationKeys),
+		Description:       summarize(body),
+		EmploymentType:    employmentType(text),
+		PostingDate:       dateField(fields, postingKeys),
+		ExpirationDate:    dateField(fields, expirationKeys),
+		IsActive:          true,
+		Languages:         matchAll(p.languages, text),
+		YearsOfExperience: yearsOfExperience(text),
+		Remote:            remoteRe.MatchString(text),
+		JobCategories:     []string{},
+		Benefits:          []string{},
+		ExtractionMethod:  models.ExtractionMethodRules,
+		Source:            job.Source,
+	}
+
+	if result.Title == "" {
+		result.Title = firstLine(body)
+	}
+	if result.Company == "" {
+		if m := companyRe.FindStringSubmatch(text); m != nil {
+			result.Company = trimArticle(m[1])
+		}
+	}
+	if result.Location == "" {
+		if cities := matchAll(p.cities, text); len(cities) > 0 {
+			result.Location = cities[0]
+		}
+	}
+	if result.PostingDate.IsZero() {
+		result.PostingDate = firstDate(text)
+	}
+	if result.PostingDate.IsZero() {
+		result.PostingDate = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+
+	result.MustSkills, result.OptionalSkills = p.extractSkills(text)
+
+	return result, nil
+}
+
+// extractSkills treats skills on lines that mark them as nice-to-have as optional
+func (p *Processor) extractSkills(text string) (must, optional []string) {
+	mustSet := make(map[string]bool)
+	optionalSet := make(map[string]bool)
+
+	for _, line := range strings.Split(text, "\n") {
+		target := mustSet
+		if optionalLineRe.MatchString(line) {
+			target = optionalSet
+		}
+		for _, skill := range matchAll(p.skills, line) {
+			target[skill] = true
+		}
+	}
+
+	for skill := range optionalSet {
+		if mustSet[skill] {
+			delete(optionalSet, skill)
+		}
+	}
+	return sortedKeys(mustSet), sortedKeys(optionalSet)
+}
+
+// splitFields separates "key: value" lines, as produced by the clean stage, from the free text
+func splitFields(text string) (map[string]string, string) {
+	fields := make(map[string]string)
+	var body []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		m := keyValueRe.FindStringSubmatch(line)
+		if m == nil {
+			body = append(body, line)
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(m[1], " ", "_"))
+		if _, exists := fields[key]; !exists && m[2] != "" {
+			fields[key] = m[2]
+		}
+		if len(m[2]) > 80 {
+			body = append(body, m[2])
+		}
+	}
+
+	return fields, strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+func firstField(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := fields[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func dateField(fields map[string]string, keys []string) time.Time {
+	return firstDate(firstField(fields, keys))
+}
+
+func firstDate(text string) time.Time {
+	if m := isoDateRe.FindStringSubmatch(text); m != nil {
+		if t, err := time.Parse("2006-01-02", m[1]); err == nil {
+			return t
+		}
+	}
+	if m := swissDateRe.FindStringSubmatch(text); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		year, _ := strconv.Atoi(m[3])
+		if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return time.Time{}
+}
+
+func employmentType(text string) string {
+	for _, et := range employmentTypes {
+		if et.pattern.MatchString(text) {
+			return et.name
+		}
+	}
+
+	// Pensum wie "80-100%" oder "100%"
+	if m := workloadRe.FindStringSubmatch(text); m != nil {
+		maxWorkload := m[3]
+		if m[2] != "" {
+			maxWorkload = m[2]
+		}
+		if workload, _ := strconv.Atoi(maxWorkload); workload >= 100 {
+			return "Full-time"
+		} else if workload > 0 {
+			return "Part-time"
+		}
+	}
+	return ""
+}
+
+func yearsOfExperience(text string) int {
+	if m := experienceRe.FindStringSubmatch(text); m != nil {
+		years, _ := strconv.Atoi(m[1])
+		return years
+	}
+	return 0
+}
+
+func trimArticle(name string) string {
+	for _, article := range articles {
+		if trimmed := strings.TrimPrefix(name, article); trimmed != name {
+			return trimmed
+		}
+	}
+	return name
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(line)
+}
+
+// summarize returns the first sentences of the free text, at most maxSummaryLength characters
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len([]rune(text)) <= maxSummaryLength {
+		return text
+	}
+
+	cut := string([]rune(text)[:maxSummaryLength])
+	ends := sentenceEndRe.FindAllStringIndex(cut, -1)
+	if len(ends) > 0 {
+		return strings.TrimSpace(cut[:ends[len(ends)-1][0]+1])
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+func matchAll(patterns []compiledPattern, text string) []string {
+	var matches []string
+	for _, p := range patterns {
+		if p.pattern.MatchString(text) {
+			matches = append(matches, p.name)
+		}
+	}
+	return matches
+}
+
+func compile(patterns map[string]string) []compiledPattern {
+	compiled := make([]compiledPattern, 0, len(patterns))
+	for name, pattern := range patterns {
+		compiled = append(compiled, compiledPattern{name: name, pattern: regexp.MustCompile(pattern)})
+	}
+	sort.Slice(compiled, func(i, j int) bool { return compiled[i].name < compiled[j].name })
+	return compiled
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
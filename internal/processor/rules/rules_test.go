@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_Process(t *testing.T) {
+	description := `title: Senior Go Developer (80-100%)
+company_name: Acme Software AG
+place: Zürich
+publication_date: 2024-10-15T08:00:00Z
+template_text:
+Wir suchen eine erfahrene Entwicklerin für unsere Plattform. Du entwickelst Microservices in Go und betreibst sie auf Kubernetes.
+- Mindestens 5 Jahre Erfahrung mit Go und Docker
+- Sehr gute Deutsch- und Englischkenntnisse
+- Erfahrung mit Terraform ist von Vorteil
+- Homeoffice möglich`
+
+	job, err := NewProcessor().Process(context.Background(), models.Job{URL: "https://example.com/1", Description: description})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", job.URL)
+	assert.Equal(t, "Senior Go Developer (80-100%)", job.Title)
+	assert.Equal(t, "Acme Software AG", job.Company)
+	assert.Equal(t, "Zürich", job.Location)
+	assert.Equal(t, "Full-time", job.EmploymentType)
+	assert.Equal(t, time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC), job.PostingDate)
+	assert.Equal(t, 5, job.YearsOfExperience)
+	assert.True(t, job.Remote)
+	assert.Equal(t, []string{"Docker", "Go", "Kubernetes", "Microservices"}, job.MustSkills)
+	assert.Equal(t, []string{"Terraform"}, job.OptionalSkills)
+	assert.ElementsMatch(t, []string{"German", "English"}, job.Languages)
+	assert.Equal(t, models.ExtractionMethodRules, job.ExtractionMethod)
+	assert.NotEmpty(t, job.Description)
+}
+
+func TestProcessor_FallsBackToFreeText(t *testing.T) {
+	description := "Java Engineer\nDie Beispiel GmbH in Bern sucht per 01.11.2024 eine Java Engineer in Teilzeit."
+
+	job, err := NewProcessor().Process(context.Background(), models.Job{Description: description})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Java Engineer", job.Title)
+	assert.Equal(t, "Beispiel GmbH", job.Company)
+	assert.Equal(t, "Bern", job.Location)
+	assert.Equal(t, "Part-time", job.EmploymentType)
+	assert.Equal(t, time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), job.PostingDate)
+	assert.Equal(t, []string{"Java"}, job.MustSkills)
+}
@@ -0,0 +1,24 @@
+package processor
+
+// Usage beschreibt den Tokenverbrauch eines einzelnen LLM-Aufrufs
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TotalTokens returns the sum of prompt and completion tokens
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// UsageRecorder receives the token usage reported by LLM-backed processors
+type UsageRecorder interface {
+	RecordUsage(usage Usage)
+}
+
+// BudgetGuard rejects LLM requests while the budget is exhausted, see budget.Tracker
+type BudgetGuard interface {
+	Check() error
+}
@@ -0,0 +1,240 @@
+package salary
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"job-scraper/internal/models"
+)
+
+// DefaultCurrency is assumed if a salary names no currency, as the scraped job boards are Swiss
+const DefaultCurrency = "CHF"
+
+// Faktoren für die Umrechnung auf einen Jahreslohn. Basis ist die 42-Stunden-Woche.
+var annualFactors = map[string]float64{
+	models.SalaryPeriodYear:  1,
+	models.SalaryPeriodMonth: 12,
+	models.SalaryPeriodWeek:  52,
+	models.SalaryPeriodDay:   5 * 52,
+	models.SalaryPeriodHour:  42 * 52,
+}
+
+// ExchangeRatesCHF are approximate rates for the conversion to CHF. They only
+// have to be accurate enough to compare salaries in statistics.
+var ExchangeRatesCHF = map[string]float64{
+	"CHF": 1,
+	"EUR": 0.95,
+	"USD": 0.88,
+	"GBP": 1.12,
+}
+
+var (
+	apostropheThousandsRe = regexp.MustCompile(`(\d)['’´` + "`" + `ʼ](\d{3})`)
+	spaceThousandsRe      = regexp.MustCompile(`(\d)[ \x{00a0}\x{202f}](\d{3})\b`)
+	dotThousandsRe        = regexp.MustCompile(`(\d)[.,](\d{3})\b`)
+	numberRe              = regexp.MustCompile(`(\d+(?:[.,]\d{1,2})?)\s*(k\b|tsd\b\.?|tausend\b|mio\b\.?)?`)
+	workloadRe            = regexp.MustCompile(`\d{1,3}\s*%?\s*(?:-|–|bis|to)\s*\d{1,3}\s*%|\d{1,3}\s*%`)
+
+	currencyPatterns = []struct {
+		currency string
+		pattern  *regexp.Regexp
+	}{
+		{"CHF", regexp.MustCompile(`\bchf\b|\bsfr\b|\bfr\.|\bfranken\b`)},
+		{"EUR", regexp.MustCompile(`\beur\b|€|\beuro\b`)},
+		{"USD", regexp.MustCompile(`\busd\b|\$|\bus-dollar\b`)},
+		{"GBP", regexp.MustCompile(`\bgbp\b|£`)},
+	}
+
+	// Reihenfolge ist relevant: "p.m." darf nicht als Jahr erkannt werden
+	periodPatterns = []struct {
+		period  string
+		pattern *regexp.Regexp
+	}{
+		{models.SalaryPeriodHour, regexp.MustCompile(`stunde|stündlich|\bhour|hourly|/\s*h\b|/\s*std\b|\bp\.?\s*h\b|heure`)},
+		{models.SalaryPeriodDay, regexp.MustCompile(`\btag\b|tagessatz|täglich|\bday\b|daily|/\s*d\b|\bjour\b`)},
+		{models.SalaryPeriodWeek, regexp.MustCompile(`woche|wöchentlich|\bweek|weekly|semaine`)},
+		{models.SalaryPeriodMonth, regexp.MustCompile(`monat|\bmonth|monthly|\bp\.?\s*m\.?(\s|$)|/\s*mt\b|\bmois\b|mensuel`)},
+		{models.SalaryPeriodYear, regexp.MustCompile(`jahr|jährlich|\byear|annual|\bp\.\s*a\.|\bpa\b|yearly|\ban\b|annuel`)},
+	}
+
+	grossRe    = regexp.MustCompile(`brutto|\bgross\b|\bbrut\b`)
+	netRe      = regexp.MustCompile(`netto|(^|[^.\w])net\b`)
+	upperRe    = regexp.MustCompile(`\b(bis|max|maximal|up to|jusqu'à)\b[^\d]*$`)
+	lowerRe    = regexp.MustCompile(`\b(ab|min|mindestens|from|starting at|dès|à partir de)\b[^\d]*$`)
+	contextRe  = regexp.MustCompile(`(?i)\b(chf|eur|usd|sfr|lohn|salär|salaire|salary|gehalt|vergütung|compensation|jahreslohn|monatslohn)|€`)
+	emptyTexts = map[string]bool{"": true, "-": true, "n/a": true, "na": true, "none": true, "not specified": true, "nicht angegeben": true, "unknown": true}
+)
+
+type amount struct {
+	value    float64
+	thousand bool
+	start    int
+}
+
+// Parse extracts the salary range from texts like "CHF 100'000 – 120'000 p.a.",
+// "80-90k" or "EUR 45 pro Stunde brutto". It reports false if the text contains no amount.
+func Parse(text string) (models.Salary, bool) {
+	result := models.Salary{Text: strings.TrimSpace(text)}
+	normalized := normalize(text)
+
+	amounts := findAmounts(normalized)
+	if len(amounts) == 0 {
+		return result, false
+	}
+
+	switch {
+	case len(amounts) >= 2:
+		low, high := amounts[0], amounts[1]
+		// "80-90k": das Suffix gilt für beide Werte
+		if high.thousand && !low.thousand && low.value < 1000 {
+			low.value *= 1000
+		}
+		result.Min, result.Max = low.value, high.value
+		if result.Min > result.Max {
+			result.Min, result.Max = result.Max, result.Min
+		}
+	case upperRe.MatchString(normalized[:amounts[0].start]):
+		result.Max = amounts[0].value
+	case lowerRe.MatchString(normalized[:amounts[0].start]):
+		result.Min = amounts[0].value
+	default:
+		result.Min, result.Max = amounts[0].value, amounts[0].value
+	}
+
+	result.Currency = currency(normalized)
+	result.Period = period(normalized, math.Max(result.Min, result.Max))
+	result.Basis = basis(normalized)
+	annualize(&result)
+
+	return result, true
+}
+
+// FromText converts a salary as returned by the LLM. Placeholders like
+// "Not specified" yield nil, texts without an amount are kept as text only.
+func FromText(text string) *models.Salary {
+	text = strings.TrimSpace(text)
+	if emptyTexts[strings.ToLower(text)] {
+		return nil
+	}
+	result, _ := Parse(text)
+	return &result
+}
+
+// Find searches a job description for the first line that states a salary
+func Find(text string) *models.Salary {
+	for _, line := range strings.Split(text, "\n") {
+		if !contextRe.MatchString(line) {
+			continue
+		}
+		if result, ok := Parse(line); ok {
+			return &result
+		}
+	}
+	return nil
+}
+
+// Annualize recalculates the yearly CHF amounts of a structured salary
+func Annualize(s *models.Salary) {
+	annualize(s)
+}
+
+func annualize(s *models.Salary) {
+	factor := annualFactors[s.Period]
+	rate, ok := ExchangeRatesCHF[s.Currency]
+	if factor == 0 || !ok {
+		s.AnnualMinCHF, s.AnnualMaxCHF = 0, 0
+		return
+	}
+	s.AnnualMinCHF = math.Round(s.Min * factor * rate)
+	s.AnnualMaxCHF = math.Round(s.Max * factor * rate)
+}
+
+func normalize(text string) string {
+	text = strings.ToLower(text)
+	for _, re := range []*regexp.Regexp{apostropheThousandsRe, spaceThousandsRe, dotThousandsRe} {
+		// Mehrfache Trennzeichen wie 1'000'000 brauchen mehrere Durchläufe
+		for {
+			replaced := re.ReplaceAllString(text, "$1$2")
+			if replaced == text {
+				break
+			}
+			text = replaced
+		}
+	}
+	return text
+}
+
+func findAmounts(text string) []amount {
+	// Pensum wie "80-100%" entfernen, die Länge bleibt für die Positionen erhalten
+	text = workloadRe.ReplaceAllStringFunc(text, func(s string) string { return strings.Repeat(" ", len(s)) })
+
+	var amounts []amount
+	for _, m := range numberRe.FindAllStringSubmatchIndex(text, -1) {
+		value, err := strconv.ParseFloat(strings.Replace(text[m[2]:m[3]], ",", ".", 1), 64)
+		if err != nil || value == 0 {
+			continue
+		}
+
+		a := amount{value: value, start: m[0]}
+		if m[4] >= 0 {
+			suffix := text[m[4]:m[5]]
+			if strings.HasPrefix(suffix, "mio") {
+				a.value *= 1_000_000
+			} else {
+				a.value *= 1000
+			}
+			a.thousand = true
+		} else if value >= 1900 && value <= 2100 && value == math.Trunc(value) {
+			// Jahreszahlen
+			continue
+		}
+
+		amounts = append(amounts, a)
+		if len(amounts) == 2 {
+			break
+		}
+	}
+	return amounts
+}
+
+func currency(text string) string {
+	for _, c := range currencyPatterns {
+		if c.pattern.MatchString(text) {
+			return c.currency
+		}
+	}
+	return DefaultCurrency
+}
+
+// period returns the stated period or guesses it from the amount
+func period(text string, value float64) string {
+	for _, p := range periodPatterns {
+		if p.pattern.MatchString(text) {
+			return p.period
+		}
+	}
+
+	switch {
+	case value >= 20000:
+		return models.SalaryPeriodYear
+	case value >= 1000:
+		return models.SalaryPeriodMonth
+	case value >= 200:
+		return models.SalaryPeriodDay
+	default:
+		return models.SalaryPeriodHour
+	}
+}
+
+func basis(text string) string {
+	switch {
+	case grossRe.MatchString(text):
+		return models.SalaryBasisGross
+	case netRe.MatchString(text):
+		return models.SalaryBasisNet
+	default:
+		return ""
+	}
+}
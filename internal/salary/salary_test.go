@@ -0,0 +1,78 @@
+package salary
+
+import (
+	"testing"
+
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max float64
+		currency string
+		period   string
+		basis    string
+	}{
+		{"CHF 100'000 – 120'000 p.a.", 100000, 120000, "CHF", models.SalaryPeriodYear, ""},
+		{"80-90k", 80000, 90000, "CHF", models.SalaryPeriodYear, ""},
+		{"120000-160000 CHF", 120000, 160000, "CHF", models.SalaryPeriodYear, ""},
+		{"CHF 8'500.- pro Monat brutto", 8500, 8500, "CHF", models.SalaryPeriodMonth, models.SalaryBasisGross},
+		{"EUR 65.000 - 75.000 jährlich", 65000, 75000, "EUR", models.SalaryPeriodYear, ""},
+		{"Stundenlohn CHF 45.50", 45.5, 45.5, "CHF", models.SalaryPeriodHour, ""},
+		{"bis CHF 130k", 0, 130000, "CHF", models.SalaryPeriodYear, ""},
+		{"ab 7000 Fr. monatlich netto", 7000, 0, "CHF", models.SalaryPeriodMonth, models.SalaryBasisNet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			s, ok := Parse(tt.text)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.min, s.Min)
+			assert.Equal(t, tt.max, s.Max)
+			assert.Equal(t, tt.currency, s.Currency)
+			assert.Equal(t, tt.period, s.Period)
+			assert.Equal(t, tt.basis, s.Basis)
+			assert.Equal(t, tt.text, s.Text)
+		})
+	}
+}
+
+func TestParseAnnualizesToCHF(t *testing.T) {
+	s, ok := Parse("CHF 8'000 - 9'000 pro Monat")
+	assert.True(t, ok)
+	assert.Equal(t, 96000.0, s.AnnualMinCHF)
+	assert.Equal(t, 108000.0, s.AnnualMaxCHF)
+
+	s, ok = Parse("EUR 100 per hour")
+	assert.True(t, ok)
+	assert.Equal(t, 100*42*52*ExchangeRatesCHF["EUR"], s.AnnualMinCHF)
+}
+
+func TestParseIgnoresWorkloadAndYears(t *testing.T) {
+	s, ok := Parse("80-100%, Lohn CHF 95'000 ab 2025")
+	assert.True(t, ok)
+	assert.Equal(t, 95000.0, s.Min)
+	assert.Equal(t, 95000.0, s.Max)
+
+	_, ok = Parse("attractive salary")
+	assert.False(t, ok)
+}
+
+func TestFromText(t *testing.T) {
+	assert.Nil(t, FromText("Not specified"))
+	assert.Equal(t, &models.Salary{Text: "competitive"}, FromText("competitive"))
+	assert.True(t, FromText("100k").IsStructured())
+}
+
+func TestFind(t *testing.T) {
+	s := Find("Senior Go Developer 80-100%\nZürich\nSalär: CHF 110'000 - 130'000 brutto")
+	if assert.NotNil(t, s) {
+		assert.Equal(t, 110000.0, s.Min)
+		assert.Equal(t, 130000.0, s.Max)
+	}
+	assert.Nil(t, Find("Senior Go Developer 80-100%"))
+}
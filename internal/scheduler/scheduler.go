@@ -74,6 +74,7 @@ func (s *Scheduler) runScraper(ctx context.Context, scraper scraper.Scraper, scr
 		Str("scraper", scraper.Name()).
 		Int("total_jobs", result.TotalJobs).
 		Int("processed_jobs", result.ProcessedJobs).
+		Int("queued_jobs", result.QueuedJobs).
 		Str("status", result.Status).
 		Msg("Scheduled scraping completed")
 }
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/scraper"
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedScraper merkt sich, wie viele Seiten angefordert wurden
+type pagedScraper struct {
+	pages int
+}
+
+func (p *pagedScraper) Scrape(ctx context.Context) ([]models.Job, error) {
+	return p.ScrapePages(ctx, 1)
+}
+func (p *pagedScraper) Name() string { return "paged" }
+
+func (p *pagedScraper) ScrapePages(ctx context.Context, pages int) ([]models.Job, error) {
+	p.pages = pages
+	return []models.Job{{URL: "https://example.com/1", Title: "Go Developer"}}, nil
+}
+
+func TestRunScraper(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+	service := services.NewScraperService(store, processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		return job, nil
+	}))
+
+	schedule, err := cron.ParseStandard("@daily")
+	require.NoError(t, err)
+	paged := &pagedScraper{}
+	s := NewScheduler(service, map[string]scraper.Scraper{"paged": paged}, []ScraperConfig{{Type: "paged", Schedule: schedule, Pages: 3}})
+
+	s.runScraper(ctx, paged, "paged")
+	assert.Equal(t, 3, paged.pages)
+
+	total, err := store.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
@@ -185,6 +185,12 @@ func (f *JobsChFetcher) FetchJob(ctx context.Context, jobID string) (*models.Job
 	job := &models.Job{
 		URL:         url,
 		Description: string(body),
+		Source: &models.SourcePayload{
+			RawPayload:  string(body),
+			ContentType: resp.Header.Get("Content-Type"),
+			FetchedAt:   time.Now(),
+			HTTPStatus:  resp.StatusCode,
+		},
 	}
 
 	return job, nil
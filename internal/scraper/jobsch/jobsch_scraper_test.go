@@ -79,3 +79,24 @@ func TestJobsChScraper_Scrape(t *testing.T) {
 	mockClient.AssertExpectations(t)
 	mockFetcher.AssertExpectations(t)
 }
+
+func TestJobsChFetcher_FetchJobKeepsSourcePayload(t *testing.T) {
+	payload := `{"job_id": "123", "title": "Go Developer"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	fetcher := NewJobsChFetcher(ts.Client(), ts.URL)
+	job, err := fetcher.FetchJob(context.Background(), "123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ts.URL+"/public/search/job/123", job.URL)
+	if assert.NotNil(t, job.Source) {
+		assert.Equal(t, payload, job.Source.RawPayload)
+		assert.Equal(t, "application/json", job.Source.ContentType)
+		assert.Equal(t, http.StatusOK, job.Source.HTTPStatus)
+		assert.False(t, job.Source.FetchedAt.IsZero())
+	}
+}
@@ -0,0 +1,170 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// snippetRadius ist die Anzahl Zeichen vor und nach einem Treffer in einem Snippet
+	snippetRadius = 60
+	// MaxSnippets begrenzt die Snippets pro Treffer
+	MaxSnippets = 3
+	// HighlightStart and HighlightEnd enclose matched terms in titles and snippets
+	HighlightStart = "<em>"
+	HighlightEnd   = "</em>"
+)
+
+// Terms returns the words of a search query that should be highlighted.
+// Phrases in quotes are split into words, negated terms like "-java" are left out.
+func Terms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, word := range words(field) {
+			key := fold(word)
+			if !seen[key] {
+				seen[key] = true
+				terms = append(terms, key)
+			}
+		}
+	}
+	return terms
+}
+
+// Highlight returns the text as HTML with the words matching one of the terms
+// enclosed. Whole words are matched like in the search, so "go" does not highlight
+// "Google". The text is escaped, as postings may contain markup.
+func Highlight(text string, terms []string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range findMatches(text, terms) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+		b.WriteString(HighlightEnd)
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// Snippets returns up to MaxSnippets highlighted excerpts around the matched
+// terms as HTML, see Highlight. Overlapping excerpts are merged. If the text has
+// no match, e.g. because only the title matched, the opening text is returned.
+func Snippets(text string, terms []string) []string {
+	matches := findMatches(text, terms)
+
+	var windows [][2]int
+	if len(matches) == 0 && strings.TrimSpace(text) != "" {
+		// Gleich lang wie ein Snippet um einen Treffer am Textanfang
+		_, end := expand(text, 0, snippetRadius)
+		windows = append(windows, [2]int{0, end})
+	}
+	for _, m := range matches {
+		start, end := expand(text, m[0], m[1])
+		if n := len(windows); n > 0 && start <= windows[n-1][1] {
+			windows[n-1][1] = end
+			continue
+		}
+		if len(windows) == MaxSnippets {
+			break
+		}
+		windows = append(windows, [2]int{start, end})
+	}
+
+	snippets := make([]string, 0, len(windows))
+	for _, w := range windows {
+		snippet := strings.Join(strings.Fields(Highlight(text[w[0]:w[1]], terms)), " ")
+		if w[0] > 0 {
+			snippet = "…" + snippet
+		}
+		if w[1] < len(text) {
+			snippet += "…"
+		}
+		snippets = append(snippets, snippet)
+	}
+	return snippets
+}
+
+// findMatches returns the byte ranges of the words equal to a term
+func findMatches(text string, terms []string) [][2]int {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches [][2]int
+	start := -1
+	for i, r := range text + " " {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWordRune && start < 0:
+			start = i
+		case !isWordRune && start >= 0:
+			if slices.Contains(terms, fold(text[start:i])) {
+				matches = append(matches, [2]int{start, i})
+			}
+			start = -1
+		}
+	}
+	return matches
+}
+
+// expand widens a match to the surrounding text, cut at word boundaries
+func expand(text string, start, end int) (int, int) {
+	from := max(0, start-snippetRadius)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	if from > 0 {
+		if i := strings.IndexFunc(text[from:start], unicode.IsSpace); i >= 0 {
+			from += i + 1
+		}
+	}
+
+	to := min(len(text), end+snippetRadius)
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	if to < len(text) {
+		if i := strings.LastIndexFunc(text[end:to], unicode.IsSpace); i >= 0 {
+			to = end + i
+		}
+	}
+	return from, to
+}
+
+// Tokens returns the words of a text, lowercased and without diacritics, as they
+// are compared by the full-text search
+func Tokens(text string) []string {
+	tokens := words(text)
+	for i, token := range tokens {
+		tokens[i] = fold(token)
+	}
+	return tokens
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var folder = strings.NewReplacer(
+	"ä", "a", "à", "a", "â", "a", "á", "a",
+	"ö", "o", "ô", "o", "ó", "o",
+	"ü", "u", "û", "u", "ù", "u",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i", "ç", "c",
+)
+
+// fold lowercases and removes diacritics like the MongoDB text index does
+func fold(word string) string {
+	return folder.Replace(strings.ToLower(word))
+}
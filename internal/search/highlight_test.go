@@ -0,0 +1,55 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"kubernetes", "zurich", "fintech", "cloud", "native"},
+		Terms(`Kubernetes Zürich fintech "cloud native" -java kubernetes`))
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "Senior <em>Kubernetes</em> <em>Entwicklerin</em> in <em>Zürich</em>",
+		Highlight("Senior Kubernetes Entwicklerin in Zürich", Terms("kubernetes entwicklerin zurich")))
+	assert.Equal(t, "Java Developer", Highlight("Java Developer", Terms("python")))
+	// Nur ganze Wörter, "go" ist kein Treffer in "Google"
+	assert.Equal(t, "<em>Go</em> bei Google, Entwickler", Highlight("Go bei Google, Entwickler", Terms("go entwick")))
+}
+
+func TestHighlightEscapesMarkup(t *testing.T) {
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; <em>Go</em> &amp; Rust",
+		Highlight("<script>alert(1)</script> Go & Rust", Terms("go")))
+	assert.Equal(t, []string{"&lt;b onmouseover=&#34;x&#34;&gt;<em>Kubernetes</em>&lt;/b&gt;"},
+		Snippets(`<b onmouseover="x">Kubernetes</b>`, Terms("kubernetes")))
+}
+
+func TestSnippets(t *testing.T) {
+	text := "Wir sind ein junges Fintech-Unternehmen mit Sitz in Zürich und bauen eine Plattform für digitale Vermögensverwaltung. " +
+		"Für unser Platform-Team suchen wir eine erfahrene Person mit Kenntnissen in Go, Terraform und Kubernetes, die unsere Infrastruktur weiterentwickelt."
+
+	snippets := Snippets(text, Terms("kubernetes zürich"))
+
+	if assert.Len(t, snippets, 2) {
+		assert.Contains(t, snippets[0], "<em>Zürich</em>")
+		assert.Contains(t, snippets[1], "<em>Kubernetes</em>")
+		assert.True(t, len(snippets[0]) < len(text))
+	}
+
+	// Ohne Treffer im Text ist der Anfang das Snippet
+	snippets = Snippets(text, Terms("python"))
+	if assert.Len(t, snippets, 1) {
+		assert.True(t, strings.HasPrefix(snippets[0], "Wir sind ein junges Fintech-Unternehmen"))
+		assert.True(t, strings.HasSuffix(snippets[0], "…"))
+		assert.NotContains(t, snippets[0], HighlightStart)
+	}
+	assert.Equal(t, []string{"Go &amp; Rust"}, Snippets("Go & Rust", Terms("python")))
+	assert.Empty(t, Snippets("  ", Terms("python")))
+}
+
+func TestTokens(t *testing.T) {
+	assert.Equal(t, []string{"senior", "go", "entwicklerin", "zurich"}, Tokens("Senior Go-Entwicklerin (Zürich)"))
+}
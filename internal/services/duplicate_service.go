@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"job-scraper/internal/dedup"
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor/postprocess"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DuplicateDetection konfiguriert die Erkennung von mehrfach ausgeschriebenen Jobs
+type DuplicateDetection struct {
+	Threshold float64       // minimum similarity of a near-duplicate
+	Window    time.Duration // how far back originals are searched, 0 searches all
+}
+
+// DeduplicateResult fasst die Duplikaterkennung gespeicherter Jobs zusammen
+type DeduplicateResult struct {
+	DryRun     bool `json:"dryRun"`
+	Total      int  `json:"total"`
+	Duplicates int  `json:"duplicates"`
+	Updated    int  `json:"updated"`
+	Failed     int  `json:"failed"`
+}
+
+// jobFingerprint computes the SimHash of a job. The posting as published is used
+// instead of the description, as LLM summaries of the same posting may differ.
+func jobFingerprint(job models.Job) dedup.Fingerprint {
+	return dedup.Compute(job.Title, job.Company, postprocess.PostingText(job))
+}
+
+// loadFingerprintIndex builds the index over the originals stored within the window
+func loadFingerprintIndex(ctx context.Context, jobStorage storage.Storage, cfg DuplicateDetection) (*dedup.Index, error) {
+	index, err := dedup.NewIndex(cfg.Threshold, cfg.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if cfg.Window > 0 {
+		since = time.Now().Add(-cfg.Window)
+	}
+	fingerprints, err := jobStorage.GetFingerprints(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fingerprints {
+		fingerprint, err := dedup.ParseFingerprint(f.Fingerprint)
+		if err != nil {
+			log.Warn().Err(err).Str("job_id", f.ID.Hex()).Msg("Skipping invalid fingerprint")
+			continue
+		}
+		index.Add(f.ID, fingerprint, f.ID.Timestamp())
+	}
+	return index, nil
+}
+
+// markDuplicate fingerprints the job and links it to the most similar original in the index.
+// Jobs without ID get one, so they can be added to the index as original after saving.
+func markDuplicate(job *models.Job, index *dedup.Index, at time.Time) (dedup.Fingerprint, float64) {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectIDFromTimestamp(at)
+	}
+
+	fingerprint := jobFingerprint(*job)
+	job.Fingerprint = fingerprint.String()
+	job.DuplicateOf = nil
+
+	original, similarity, ok := index.Match(fingerprint, at)
+	if ok && original != job.ID {
+		job.DuplicateOf = &original
+	}
+	return fingerprint, similarity
+}
+
+// DeduplicateStoredJobs fingerprints all stored jobs and links near-duplicates to the first
+// posting in storage order. Existing links are recomputed, e.g. after changing the threshold.
+func DeduplicateStoredJobs(ctx context.Context, jobStorage storage.Storage, cfg DuplicateDetection, dryRun bool) (DeduplicateResult, error) {
+	result := DeduplicateResult{DryRun: dryRun}
+
+	index, err := dedup.NewIndex(cfg.Threshold, cfg.Window)
+	if err != nil {
+		return result, err
+	}
+
+	jobs, err := jobStorage.FindJobs(ctx, storage.JobFilter{})
+	if err != nil {
+		return result, err
+	}
+	// Die ObjectID wächst mit dem Speicherzeitpunkt, das erste Inserat bleibt das Original
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID.Hex() < jobs[j].ID.Hex()
+	})
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Total++
+
+		previousFingerprint, previousOriginal := job.Fingerprint, job.DuplicateOf
+		fingerprint, _ := markDuplicate(&job, index, job.ID.Timestamp())
+		if job.DuplicateOf != nil {
+			result.Duplicates++
+		} else {
+			index.Add(job.ID, fingerprint, job.ID.Timestamp())
+		}
+
+		if job.Fingerprint == previousFingerprint && sameObjectID(job.DuplicateOf, previousOriginal) {
+			continue
+		}
+		result.Updated++
+		if dryRun {
+			continue
+		}
+
+		if err := jobStorage.UpdateJob(ctx, job); err != nil {
+			result.Failed++
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update duplicate link")
+		}
+	}
+
+	log.Info().
+		Bool("dry_run", dryRun).
+		Int("total", result.Total).
+		Int("duplicates", result.Duplicates).
+		Int("updated", result.Updated).
+		Int("failed", result.Failed).
+		Msg("Duplicate detection finished")
+
+	return result, nil
+}
+
+// keepDuplicateLink copies the fingerprint and the link to the original of the stored
+// job to its re-extracted version. Links are recomputed by DeduplicateStoredJobs.
+func keepDuplicateLink(job *models.Job, stored models.Job) {
+	job.Fingerprint = stored.Fingerprint
+	job.DuplicateOf = stored.DuplicateOf
+}
+
+// recordDuplicate logs a job that was linked to an earlier posting
+func recordDuplicate(job models.Job, similarity float64) {
+	domains.DuplicatesDetected.Inc()
+	log.Info().
+		Str("job_url", job.URL).
+		Str("duplicate_of", job.DuplicateOf.Hex()).
+		Float64("similarity", similarity).
+		Msg("Job is a near-duplicate of an earlier posting")
+}
+
+func sameObjectID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
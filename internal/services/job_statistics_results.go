@@ -0,0 +1,126 @@
+package services
+
+import "time"
+
+// Die Ergebnisse der Statistiken behalten die JSON-Felder der früheren
+// Aggregations-Pipelines, auf die sich die Grafana-Dashboards stützen.
+
+// GroupCount is the number of jobs with a value or a combination of values. K is a
+// string or one of the key structs below.
+type GroupCount[K any] struct {
+	ID    K   `json:"_id"`
+	Count int `json:"count"`
+}
+
+type CompanySizeBenefit struct {
+	CompanySize string `json:"companySize"`
+	Benefit     string `json:"benefit"`
+}
+
+type LocationLanguage struct {
+	Location string `json:"location"`
+	Language string `json:"language"`
+}
+
+type CategoryRemote struct {
+	Category string `json:"category"`
+	Remote   bool   `json:"remote"`
+}
+
+type MonthSkill struct {
+	MonthYear string `json:"monthYear"`
+	Skill     string `json:"skill"`
+}
+
+type LocationSkill struct {
+	Location string `json:"location"`
+	Skill    string `json:"skill"`
+}
+
+type CompanySizeCategory struct {
+	CompanySize string `json:"companySize"`
+	Category    string `json:"category"`
+}
+
+type ExperienceSkill struct {
+	ExperienceLevel string `json:"experienceLevel"`
+	Skill           string `json:"skill"`
+}
+
+type CategoryExperience struct {
+	Category      string  `json:"_id"`
+	AvgExperience float64 `json:"avgExperience"`
+}
+
+// WorkTypeCount counts Remote or On-site jobs
+type WorkTypeCount struct {
+	WorkType string `json:"workType"`
+	Count    int    `json:"count"`
+}
+
+type CategoryWorkType struct {
+	Category string `json:"category"`
+	WorkType string `json:"workType"`
+	Count    int    `json:"count"`
+}
+
+// EducationSalary is the average annual salary in CHF of the jobs with salary
+type EducationSalary struct {
+	EducationLevel string  `json:"_id"`
+	AvgSalary      float64 `json:"avgSalary"`
+	Count          int     `json:"count"`
+}
+
+type CompanyJobs struct {
+	Company     string `json:"company"`
+	CompanySize int    `json:"companySize"`
+	JobCount    int    `json:"jobCount"`
+}
+
+// CompanyLocations lists the locations of a company, the one with the most jobs first
+type CompanyLocations struct {
+	Company     string   `json:"company"`
+	CompanySize int      `json:"companySize"`
+	Locations   []string `json:"location"`
+	TotalJobs   int      `json:"totalJobs"`
+}
+
+// SizeShare is the share of jobs of a company size class, between 0 and 1
+type SizeShare struct {
+	SizeCategory string  `json:"sizeCategory"`
+	Count        int     `json:"count"`
+	Percentage   float64 `json:"percentage"`
+}
+
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+type CompanyPostings struct {
+	CompanyName      string    `json:"companyName"`
+	NumberOfPostings int       `json:"numberOfPostings"`
+	MostRecentPost   time.Time `json:"mostRecentPost"`
+}
+
+// PostingLifetime is the number of days closed jobs were online
+type PostingLifetime struct {
+	MedianDays float64 `json:"medianDays"`
+	P90Days    float64 `json:"p90Days"`
+	ClosedJobs int     `json:"closedJobs"`
+}
+
+type GroupLifetime struct {
+	ID string `json:"_id"`
+	PostingLifetime
+}
+
+type MonthLifetime struct {
+	Month string `json:"month"`
+	PostingLifetime
+}
@@ -2,604 +2,436 @@ package services
 
 import (
 	"context"
-	"errors"
-	"math"
 	"time"
 
 	"job-scraper/internal/apperrors"
+	"job-scraper/internal/metrics/domains"
 	"job-scraper/internal/storage"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type JobStatisticsService struct {
-	storage storage.Storage
-}
+// StatsFilter restricts the jobs a statistic is computed over. Empty fields match all jobs.
+type StatsFilter struct {
+	// PostingLanguage ist der ISO-639-1-Code der Sprache des Inserats
+	PostingLanguage string
+	// ExcludeDuplicates counts reposts and cross-posts only once, as their original
+	ExcludeDuplicates bool
 
-func NewJobStatisticsService(storage storage.Storage) *JobStatisticsService {
-	return &JobStatisticsService{storage: storage}
+	closedOnly bool // set by the lifetime statistics
 }
 
-func (s *JobStatisticsService) GetTopJobCategories() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$jobCategories"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-		{{Key: "$limit", Value: 10}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+// jobFilter returns the storage filter of the jobs matching the StatsFilter
+func (f StatsFilter) jobFilter() storage.JobFilter {
+	return storage.JobFilter{PostingLanguage: f.PostingLanguage, OriginalsOnly: f.ExcludeDuplicates, ClosedOnly: f.closedOnly}
 }
 
-func (s *JobStatisticsService) GetAvgExperienceByCategory() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$match", Value: bson.D{{Key: "yearsOfExperience", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$jobCategories"},
-			{Key: "avgExperience", Value: bson.D{{Key: "$avg", Value: "$yearsOfExperience"}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "avgExperience", Value: -1}}}},
-	}
+// Häufige Sortierungen der Statistiken
+var (
+	byCount = []storage.StatsOrder{{Key: storage.CountKey, Descending: true}}
+	byDate  = []storage.StatsOrder{{Key: string(storage.DimPostingDate)}}
+)
 
-	return s.aggregateResults(ctx, pipeline)
+// JobStatisticsService computes the statistics of the API as storage.StatsQuery,
+// in the database if the storage is a storage.StatsAggregator
+type JobStatisticsService struct {
+	storage    storage.Storage
+	aggregator storage.StatsAggregator // nil if the storage cannot aggregate
 }
 
-func (s *JobStatisticsService) GetRemoteVsOnsite() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$remote"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "workType", Value: bson.D{{Key: "$cond", Value: bson.D{
-				{Key: "if", Value: bson.D{{Key: "$eq", Value: bson.A{"$_id", true}}}},
-				{Key: "then", Value: "Remote"},
-				{Key: "else", Value: "On-site"},
-			}}}},
-			{Key: "count", Value: 1},
-		}}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func NewJobStatisticsService(s storage.Storage) *JobStatisticsService {
+	aggregator, _ := storage.Unwrap(s).(storage.StatsAggregator)
+	return &JobStatisticsService{storage: s, aggregator: aggregator}
 }
 
-func (s *JobStatisticsService) GetTopSkills() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$mustSkills"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$mustSkills"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-		{{Key: "$limit", Value: 100}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetTopJobCategories(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimCategory, 10)
 }
 
-func (s *JobStatisticsService) GetTopOptionalSkills() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$optionalSkills"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$optionalSkills"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-		{{Key: "$limit", Value: 100}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetJobCategoryCounts(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimCategory, 0)
 }
 
-func (s *JobStatisticsService) GetBenefitsByCompanySize() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$benefits"}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "companySize", Value: bson.D{{Key: "$switch", Value: bson.D{
-				{Key: "branches", Value: bson.A{
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$companySize", 50}}}}, {Key: "then", Value: "Small"}},
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$companySize", 250}}}}, {Key: "then", Value: "Medium"}},
-				}},
-				{Key: "default", Value: "Large"},
-			}}}},
-			{Key: "benefit", Value: "$benefits"},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "companySize", Value: "$companySize"}, {Key: "benefit", Value: "$benefit"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetTopSkills(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimMustSkill, 100)
 }
 
-func (s *JobStatisticsService) GetAvgSalaryByEducation() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.D{
-			{Key: "salary", Value: bson.D{
-				{Key: "$ne", Value: nil},
-				{Key: "$ne", Value: ""},
-				{Key: "$gt", Value: 0},
-			}},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$educationLevel"},
-			{Key: "avgSalary", Value: bson.D{{Key: "$avg", Value: "$salary"}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "avgSalary", Value: -1}}}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetTopOptionalSkills(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimOptionalSkill, 100)
 }
 
-func (s *JobStatisticsService) GetJobPostingsTrend() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$project", Value: bson.D{
-			{Key: "monthYear", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-				{Key: "format", Value: "%Y-%m"},
-				{Key: "date", Value: "$postingDate"},
-			}}}},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$monthYear"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+// GetJobsByCanton counts the jobs per canton, jobs with several places of work count once per canton
+func (s *JobStatisticsService) GetJobsByCanton(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimCanton, 0)
 }
 
-func (s *JobStatisticsService) GetLanguagesByLocation() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$languages"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "location", Value: "$location"}, {Key: "language", Value: "$languages"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-	}
-
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetEmploymentTypes(filter StatsFilter) ([]GroupCount[string], error) {
+	return s.countValues(filter, storage.DimEmploymentType, 0)
 }
 
-func (s *JobStatisticsService) GetEmploymentTypes() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// GetJobPostingsTrend counts the jobs per month, oldest month first
+func (s *JobStatisticsService) GetJobPostingsTrend(filter StatsFilter) ([]GroupCount[string], error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{storage.DimPostingDate}, Bucket: storage.BucketMonth, OrderBy: byDate}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) GroupCount[string] {
+		return GroupCount[string]{ID: row.Keys[0], Count: row.Count}
+	})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$employmentType"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+func (s *JobStatisticsService) GetAvgExperienceByCategory(filter StatsFilter) ([]CategoryExperience, error) {
+	query := storage.StatsQuery{
+		GroupBy:  []storage.Dimension{storage.DimCategory},
+		Measures: []storage.Measure{{Name: "avgExperience", Aggregate: storage.AggregateAvg, Field: storage.FieldYearsOfExperience}},
+		OrderBy:  []storage.StatsOrder{{Key: "avgExperience", Descending: true}},
+	}
+	rows, err := s.aggregate(filter, query)
+	results := []CategoryExperience{}
+	for _, row := range rows {
+		// Kategorien ohne Angaben zur Erfahrung fehlen
+		if avg, ok := row.Values["avgExperience"]; ok {
+			results = append(results, CategoryExperience{Category: row.Keys[0], AvgExperience: avg})
+		}
 	}
+	return results, err
+}
 
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetRemoteVsOnsite(filter StatsFilter) ([]WorkTypeCount, error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{storage.DimRemote}, OrderBy: byCount}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) WorkTypeCount {
+		return WorkTypeCount{WorkType: workType(row.Keys[0]), Count: row.Count}
+	})
 }
 
-func (s *JobStatisticsService) GetRemoteWorkByCategory() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *JobStatisticsService) GetBenefitsByCompanySize(filter StatsFilter) ([]GroupCount[CompanySizeBenefit], error) {
+	return countPairs(s, filter, storage.DimCompanySize, storage.DimBenefit, func(size, benefit string) CompanySizeBenefit {
+		return CompanySizeBenefit{CompanySize: size, Benefit: benefit}
+	})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "category", Value: "$jobCategories"}, {Key: "remote", Value: "$remote"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+// GetAvgSalaryByEducation averages the annual salary in CHF of the jobs with salary
+func (s *JobStatisticsService) GetAvgSalaryByEducation(filter StatsFilter) ([]EducationSalary, error) {
+	query := storage.StatsQuery{
+		GroupBy: []storage.Dimension{storage.DimEducationLevel},
+		Measures: []storage.Measure{
+			{Name: "avgSalary", Aggregate: storage.AggregateAvg, Field: storage.FieldAnnualSalary},
+			{Name: "salaries", Aggregate: storage.AggregateCount, Field: storage.FieldAnnualSalary},
+		},
+		OrderBy: []storage.StatsOrder{{Key: "avgSalary", Descending: true}},
 	}
-
-	return s.aggregateResults(ctx, pipeline)
+	rows, err := s.aggregate(filter, query)
+	results := []EducationSalary{}
+	for _, row := range rows {
+		if avg, ok := row.Values["avgSalary"]; ok {
+			results = append(results, EducationSalary{EducationLevel: row.Keys[0], AvgSalary: avg, Count: int(row.Values["salaries"])})
+		}
+	}
+	return results, err
 }
 
-func (s *JobStatisticsService) GetTechnologyTrends() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$mustSkills"}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "monthYear", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-				{Key: "format", Value: "%Y-%m"},
-				{Key: "date", Value: "$postingDate"},
-			}}}},
-			{Key: "skill", Value: "$mustSkills"},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "monthYear", Value: "$monthYear"}, {Key: "skill", Value: "$skill"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "_id.monthYear", Value: 1}}}},
-	}
+func (s *JobStatisticsService) GetLanguagesByLocation(filter StatsFilter) ([]GroupCount[LocationLanguage], error) {
+	return countPairs(s, filter, storage.DimLocation, storage.DimLanguage, func(location, language string) LocationLanguage {
+		return LocationLanguage{Location: location, Language: language}
+	})
+}
 
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetRemoteWorkByCategory(filter StatsFilter) ([]GroupCount[CategoryRemote], error) {
+	return countPairs(s, filter, storage.DimCategory, storage.DimRemote, func(category, remote string) CategoryRemote {
+		return CategoryRemote{Category: category, Remote: remote == "true"}
+	})
 }
 
-func (s *JobStatisticsService) GetJobRequirementsByLocation() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *JobStatisticsService) GetRemoteVsOnsiteByIndustry(filter StatsFilter) ([]CategoryWorkType, error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{storage.DimCategory, storage.DimRemote}, OrderBy: byCount}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) CategoryWorkType {
+		return CategoryWorkType{Category: row.Keys[0], WorkType: workType(row.Keys[1]), Count: row.Count}
+	})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$mustSkills"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "location", Value: "$location"}, {Key: "skill", Value: "$mustSkills"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+// GetTechnologyTrends counts the jobs per month and required skill, oldest month first
+func (s *JobStatisticsService) GetTechnologyTrends(filter StatsFilter) ([]GroupCount[MonthSkill], error) {
+	query := storage.StatsQuery{
+		GroupBy: []storage.Dimension{storage.DimPostingDate, storage.DimMustSkill},
+		Bucket:  storage.BucketMonth,
+		OrderBy: []storage.StatsOrder{byDate[0], byCount[0]},
 	}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) GroupCount[MonthSkill] {
+		return GroupCount[MonthSkill]{ID: MonthSkill{MonthYear: row.Keys[0], Skill: row.Keys[1]}, Count: row.Count}
+	})
+}
 
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetJobRequirementsByLocation(filter StatsFilter) ([]GroupCount[LocationSkill], error) {
+	return countPairs(s, filter, storage.DimLocation, storage.DimMustSkill, func(location, skill string) LocationSkill {
+		return LocationSkill{Location: location, Skill: skill}
+	})
 }
 
-func (s *JobStatisticsService) GetRemoteVsOnsiteByIndustry() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *JobStatisticsService) GetJobCategoriesByCompanySize(filter StatsFilter) ([]GroupCount[CompanySizeCategory], error) {
+	return countPairs(s, filter, storage.DimCompanySize, storage.DimCategory, func(size, category string) CompanySizeCategory {
+		return CompanySizeCategory{CompanySize: size, Category: category}
+	})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "category", Value: "$jobCategories"}, {Key: "remote", Value: "$remote"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "category", Value: "$_id.category"},
-			{Key: "workType", Value: bson.D{{Key: "$cond", Value: bson.D{
-				{Key: "if", Value: bson.D{{Key: "$eq", Value: bson.A{"$_id.remote", true}}}},
-				{Key: "then", Value: "Remote"},
-				{Key: "else", Value: "On-site"},
-			}}}},
-			{Key: "count", Value: 1},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
-	}
+func (s *JobStatisticsService) GetSkillsByExperienceLevel(filter StatsFilter) ([]GroupCount[ExperienceSkill], error) {
+	return countPairs(s, filter, storage.DimExperience, storage.DimMustSkill, func(level, skill string) ExperienceSkill {
+		return ExperienceSkill{ExperienceLevel: level, Skill: skill}
+	})
+}
 
-	return s.aggregateResults(ctx, pipeline)
+// GetCompaniesBySize lists the companies, the largest first. The size of a company
+// is the largest size given in its jobs.
+func (s *JobStatisticsService) GetCompaniesBySize(filter StatsFilter) ([]CompanyJobs, error) {
+	rows, err := s.aggregate(filter, companiesQuery(nil))
+	return collect(rows, err, func(row storage.StatsRow) CompanyJobs {
+		return CompanyJobs{Company: row.Keys[0], CompanySize: int(row.Values["companySize"]), JobCount: row.Count}
+	})
 }
 
-func (s *JobStatisticsService) GetJobCategoriesByCompanySize() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// GetCompaniesBySizeAndType lists the companies of a size class (small, medium or
+// large) with their locations, the location with the most jobs first
+func (s *JobStatisticsService) GetCompaniesBySizeAndType(filter StatsFilter, sizeType string) ([]CompanyLocations, error) {
+	switch sizeType {
+	case "small", "medium", "large":
+	default:
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid size type: "+sizeType, nil)
+	}
+	sized := []storage.StatsCondition{{Dimension: storage.DimCompanySize, Values: []string{sizeType}}}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "companySize", Value: bson.D{{Key: "$switch", Value: bson.D{
-				{Key: "branches", Value: bson.A{
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$companySize", 50}}}}, {Key: "then", Value: "Small"}},
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$companySize", 250}}}}, {Key: "then", Value: "Medium"}},
-				}},
-				{Key: "default", Value: "Large"},
-			}}}},
-			{Key: "category", Value: "$jobCategories"},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "companySize", Value: "$companySize"}, {Key: "category", Value: "$category"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+	companies, err := s.aggregate(filter, companiesQuery(sized))
+	if err != nil {
+		return nil, err
+	}
+	locations, err := s.aggregate(filter, storage.StatsQuery{
+		Where:   sized,
+		GroupBy: []storage.Dimension{storage.DimCompany, storage.DimLocation},
+		OrderBy: byCount,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.aggregateResults(ctx, pipeline)
+	byCompany := make(map[string][]string)
+	for _, row := range locations {
+		byCompany[row.Keys[0]] = append(byCompany[row.Keys[0]], row.Keys[1])
+	}
+	results := make([]CompanyLocations, 0, len(companies))
+	for _, row := range companies {
+		results = append(results, CompanyLocations{
+			Company:     row.Keys[0],
+			CompanySize: int(row.Values["companySize"]),
+			Locations:   byCompany[row.Keys[0]],
+			TotalJobs:   row.Count,
+		})
+	}
+	return results, nil
 }
 
-func (s *JobStatisticsService) GetSkillsByExperienceLevel() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$mustSkills"}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "experienceLevel", Value: bson.D{{Key: "$switch", Value: bson.D{
-				{Key: "branches", Value: bson.A{
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$yearsOfExperience", 2}}}}, {Key: "then", Value: "Junior"}},
-					bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$yearsOfExperience", 5}}}}, {Key: "then", Value: "Mid-Level"}},
-				}},
-				{Key: "default", Value: "Senior"},
-			}}}},
-			{Key: "skill", Value: "$mustSkills"},
-		}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "experienceLevel", Value: "$experienceLevel"}, {Key: "skill", Value: "$skill"}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+// companiesQuery groups the jobs by company, the largest company first
+func companiesQuery(where []storage.StatsCondition) storage.StatsQuery {
+	return storage.StatsQuery{
+		Where:    where,
+		GroupBy:  []storage.Dimension{storage.DimCompany},
+		Measures: []storage.Measure{{Name: "companySize", Aggregate: storage.AggregateMax, Field: storage.FieldCompanySize}},
+		OrderBy:  []storage.StatsOrder{{Key: "companySize", Descending: true}},
 	}
-
-	return s.aggregateResults(ctx, pipeline)
 }
 
-func (s *JobStatisticsService) GetCompaniesBySize() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// GetCompanySizeDistribution returns the share of jobs per company size class.
+// Negative sizes are invalid and counted as Unknown.
+func (s *JobStatisticsService) GetCompanySizeDistribution(filter StatsFilter) ([]SizeShare, error) {
+	rows, err := s.aggregate(filter, storage.StatsQuery{GroupBy: []storage.Dimension{storage.DimCompanySize}})
+	if err != nil {
+		return nil, err
+	}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$company"},
-			{Key: "companySize", Value: bson.D{{Key: "$first", Value: "$companySize"}}},
-			{Key: "jobCount", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "companySize", Value: -1}}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "company", Value: "$_id"},
-			{Key: "companySize", Value: 1},
-			{Key: "jobCount", Value: 1},
-			{Key: "_id", Value: 0},
-		}}},
+	var total int
+	for _, row := range rows {
+		total += row.Count
 	}
+	results := make([]SizeShare, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, SizeShare{SizeCategory: row.Keys[0], Count: row.Count, Percentage: float64(row.Count) / float64(total)})
+	}
+	return results, nil
+}
 
-	return s.aggregateResults(ctx, pipeline)
+// Die Lebensdauer eines Inserats reicht vom Veröffentlichungsdatum bis zur Schliessung
+var lifetimeMeasures = []storage.Measure{
+	{Name: "medianDays", Aggregate: storage.AggregateMedian, Field: storage.FieldLifetime},
+	{Name: "p90Days", Aggregate: storage.AggregateP90, Field: storage.FieldLifetime},
+	{Name: "closedJobs", Aggregate: storage.AggregateCount, Field: storage.FieldLifetime},
 }
 
-func (s *JobStatisticsService) GetCompaniesBySizeAndType(sizeType string) ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// lifetimeGroups are the dimensions the posting lifetime can be grouped by, with
+// the number of groups returned (0 for all)
+var lifetimeGroups = map[string]struct {
+	dimension storage.Dimension
+	limit     int
+}{
+	"category": {storage.DimCategory, 0},
+	"company":  {storage.DimCompany, 50},
+	"location": {storage.DimLocation, 50},
+	"skill":    {storage.DimMustSkill, 50},
+}
 
-	var sizeCriteria bson.D
-	switch sizeType {
-	case "small":
-		sizeCriteria = bson.D{{Key: "$lte", Value: 50}}
-	case "medium":
-		sizeCriteria = bson.D{{Key: "$gt", Value: 50}, {Key: "$lte", Value: 250}}
-	case "large":
-		sizeCriteria = bson.D{{Key: "$gt", Value: 250}}
-	default:
-		return nil, errors.New("invalid size type")
+// GetPostingLifetime returns the median and 90th percentile of the days from posting
+// to closing per category, company, location or required skill, the group with the
+// most closed jobs first. Open jobs are left out, see LifecycleService.
+func (s *JobStatisticsService) GetPostingLifetime(filter StatsFilter, groupBy string) ([]GroupLifetime, error) {
+	group, ok := lifetimeGroups[groupBy]
+	if !ok {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid lifetime group: "+groupBy, nil)
 	}
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.D{{Key: "companySize", Value: sizeCriteria}}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "company", Value: "$company"}, {Key: "location", Value: "$location"}}},
-			{Key: "companySize", Value: bson.D{{Key: "$first", Value: "$companySize"}}},
-			{Key: "locationCount", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "locationCount", Value: -1}}}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$_id.company"},
-			{Key: "companySize", Value: bson.D{{Key: "$first", Value: "$companySize"}}},
-			{Key: "location", Value: bson.D{{Key: "$push", Value: "$_id.location"}}},
-			{Key: "totalJobs", Value: bson.D{{Key: "$sum", Value: "$locationCount"}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "companySize", Value: -1}}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "company", Value: "$_id"},
-			{Key: "companySize", Value: 1},
-			{Key: "location", Value: 1},
-			{Key: "totalJobs", Value: 1},
-			{Key: "_id", Value: 0},
-		}}},
+	filter.closedOnly = true
+	query := storage.StatsQuery{
+		GroupBy:  []storage.Dimension{group.dimension},
+		Measures: lifetimeMeasures,
+		OrderBy:  []storage.StatsOrder{{Key: "closedJobs", Descending: true}},
+		Limit:    group.limit,
 	}
-
-	return s.aggregateResults(ctx, pipeline)
+	rows, err := s.aggregate(filter, query)
+	results := []GroupLifetime{}
+	for _, row := range rows {
+		// Gruppen ohne gültige Lebensdauer (Schliessung vor der Veröffentlichung) fehlen
+		if lifetime, ok := postingLifetime(row); ok {
+			results = append(results, GroupLifetime{ID: row.Keys[0], PostingLifetime: lifetime})
+		}
+	}
+	return results, err
 }
 
-func (s *JobStatisticsService) GetCompanySizeDistribution() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$facet", Value: bson.D{
-			{Key: "sizes", Value: bson.A{
-				bson.D{{Key: "$bucket", Value: bson.D{
-					{Key: "groupBy", Value: "$companySize"},
-					{Key: "boundaries", Value: bson.A{0, 51, 251, math.MaxInt64}},
-					{Key: "default", Value: "Unknown"},
-					{Key: "output", Value: bson.D{
-						{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-					}},
-				}}},
-			}},
-			{Key: "total", Value: bson.A{
-				bson.D{{Key: "$count", Value: "count"}},
-			}},
-		}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "sizes", Value: bson.D{{Key: "$map", Value: bson.D{
-				{Key: "input", Value: "$sizes"},
-				{Key: "as", Value: "size"},
-				{Key: "in", Value: bson.D{
-					{Key: "sizeCategory", Value: bson.D{{Key: "$switch", Value: bson.D{
-						{Key: "branches", Value: bson.A{
-							bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$size._id", "Unknown"}}}}, {Key: "then", Value: "Unknown"}},
-							bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$$size._id", 50}}}}, {Key: "then", Value: "Small"}},
-							bson.D{{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$$size._id", 250}}}}, {Key: "then", Value: "Medium"}},
-						}},
-						{Key: "default", Value: "Large"},
-					}}}},
-					{Key: "count", Value: "$$size.count"},
-					{Key: "percentage", Value: bson.D{{Key: "$divide", Value: bson.A{"$$size.count", bson.D{{Key: "$arrayElemAt", Value: bson.A{"$total.count", 0}}}}}}},
-				}},
-			}}}},
-		}}},
-		{{Key: "$unwind", Value: "$sizes"}},
-		{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$sizes"}}}},
-		{{Key: "$sort", Value: bson.D{{Key: "sizeCategory", Value: 1}}}},
+// GetPostingLifetimeTrend returns the posting lifetime of the jobs closed per month, oldest month first
+func (s *JobStatisticsService) GetPostingLifetimeTrend(filter StatsFilter) ([]MonthLifetime, error) {
+	filter.closedOnly = true
+	query := storage.StatsQuery{
+		GroupBy:  []storage.Dimension{storage.DimClosedDate},
+		Bucket:   storage.BucketMonth,
+		Measures: lifetimeMeasures,
+		OrderBy:  []storage.StatsOrder{{Key: string(storage.DimClosedDate)}},
 	}
-
-	results, err := s.aggregateResults(ctx, pipeline)
-	if err != nil {
-		return nil, apperrors.NewBaseError(
-			apperrors.ErrCodeStorage,
-			"Failed to execute aggregation query",
-			err,
-		)
+	rows, err := s.aggregate(filter, query)
+	results := []MonthLifetime{}
+	for _, row := range rows {
+		if lifetime, ok := postingLifetime(row); ok {
+			results = append(results, MonthLifetime{Month: row.Keys[0], PostingLifetime: lifetime})
+		}
 	}
-
-	return results, nil
+	return results, err
 }
 
-func (s *JobStatisticsService) GetJobPostingsPerDay() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-				{Key: "format", Value: "%Y-%m-%d"},
-				{Key: "date", Value: "$postingDate"},
-			}}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "day", Value: "$_id"},
-			{Key: "count", Value: 1},
-			{Key: "_id", Value: 0},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "day", Value: 1}}}},
-	}
+func postingLifetime(row storage.StatsRow) (PostingLifetime, bool) {
+	median, ok := row.Values["medianDays"]
+	return PostingLifetime{MedianDays: median, P90Days: row.Values["p90Days"], ClosedJobs: int(row.Values["closedJobs"])}, ok
+}
 
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetJobPostingsPerDay(filter StatsFilter) ([]DayCount, error) {
+	return s.postingsPerDay(filter, nil)
 }
 
-func (s *JobStatisticsService) GetJobPostingsPerMonth() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *JobStatisticsService) GetJobPostingsPerMonth(filter StatsFilter) ([]MonthCount, error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{storage.DimPostingDate}, Bucket: storage.BucketMonth, OrderBy: byDate}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) MonthCount {
+		return MonthCount{Month: row.Keys[0], Count: row.Count}
+	})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-				{Key: "format", Value: "%Y-%m"},
-				{Key: "date", Value: "$postingDate"},
-			}}}},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "month", Value: "$_id"},
-			{Key: "count", Value: 1},
-			{Key: "_id", Value: 0},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "month", Value: 1}}}},
+// GetJobPostingsPerCompany counts the jobs per company, the company with the most jobs first
+func (s *JobStatisticsService) GetJobPostingsPerCompany(filter StatsFilter) ([]CompanyPostings, error) {
+	query := storage.StatsQuery{
+		GroupBy:  []storage.Dimension{storage.DimCompany},
+		Measures: []storage.Measure{{Name: "mostRecentPost", Aggregate: storage.AggregateMax, Field: storage.FieldPostingDate}},
+		OrderBy:  byCount,
 	}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) CompanyPostings {
+		postings := CompanyPostings{CompanyName: row.Keys[0], NumberOfPostings: row.Count}
+		if posted, ok := row.Values["mostRecentPost"]; ok {
+			postings.MostRecentPost = time.Unix(int64(posted), 0).UTC()
+		}
+		return postings
+	})
+}
 
-	return s.aggregateResults(ctx, pipeline)
+func (s *JobStatisticsService) GetMustSkillFrequencyPerDay(filter StatsFilter, skill string) ([]DayCount, error) {
+	return s.postingsPerDay(filter, []storage.StatsCondition{{Dimension: storage.DimMustSkill, Values: []string{skill}}})
 }
 
-func (s *JobStatisticsService) GetJobPostingsPerCompany() ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *JobStatisticsService) GetOptionalSkillFrequencyPerDay(filter StatsFilter, skill string) ([]DayCount, error) {
+	return s.postingsPerDay(filter, []storage.StatsCondition{{Dimension: storage.DimOptionalSkill, Values: []string{skill}}})
+}
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$company"},
-			{Key: "numberOfPostings", Value: bson.D{{Key: "$sum", Value: 1}}},
-			{Key: "postingDates", Value: bson.D{{Key: "$push", Value: "$postingDate"}}},
-			{Key: "postingUrls", Value: bson.D{{Key: "$push", Value: "$url"}}},
-			{Key: "mostRecentPost", Value: bson.D{{Key: "$max", Value: "$postingDate"}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "numberOfPostings", Value: -1}}}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "companyName", Value: "$_id"},
-			{Key: "numberOfPostings", Value: 1},
-			{Key: "postingDates", Value: 1},
-			{Key: "postingUrls", Value: 1},
-			{Key: "mostRecentPost", Value: 1},
-			{Key: "_id", Value: 0},
-		}}},
-	}
+// postingsPerDay counts the jobs matching the conditions per day, oldest day first
+func (s *JobStatisticsService) postingsPerDay(filter StatsFilter, where []storage.StatsCondition) ([]DayCount, error) {
+	query := storage.StatsQuery{Where: where, GroupBy: []storage.Dimension{storage.DimPostingDate}, Bucket: storage.BucketDay, OrderBy: byDate}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) DayCount {
+		return DayCount{Day: row.Keys[0], Count: row.Count}
+	})
+}
 
-	return s.aggregateResults(ctx, pipeline)
+// countValues counts the jobs per value of a dimension, the most frequent first.
+// limit keeps the top N values, 0 all.
+func (s *JobStatisticsService) countValues(filter StatsFilter, dimension storage.Dimension, limit int) ([]GroupCount[string], error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{dimension}, OrderBy: byCount, Limit: limit}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) GroupCount[string] {
+		return GroupCount[string]{ID: row.Keys[0], Count: row.Count}
+	})
 }
 
-func (s *JobStatisticsService) GetMustSkillFrequencyPerDay(skill string) ([]bson.M, error) {
-	return s.getSkillFrequencyPerDay(skill, "mustSkills")
+// countPairs counts the jobs per combination of two dimensions, the most frequent first
+func countPairs[K any](s *JobStatisticsService, filter StatsFilter, first, second storage.Dimension, key func(a, b string) K) ([]GroupCount[K], error) {
+	query := storage.StatsQuery{GroupBy: []storage.Dimension{first, second}, OrderBy: byCount}
+	rows, err := s.aggregate(filter, query)
+	return collect(rows, err, func(row storage.StatsRow) GroupCount[K] {
+		return GroupCount[K]{ID: key(row.Keys[0], row.Keys[1]), Count: row.Count}
+	})
 }
 
-func (s *JobStatisticsService) GetOptionalSkillFrequencyPerDay(skill string) ([]bson.M, error) {
-	return s.getSkillFrequencyPerDay(skill, "optionalSkills")
+// collect converts the rows of aggregate to results
+func collect[T any](rows []storage.StatsRow, err error, convert func(storage.StatsRow) T) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	results := make([]T, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, convert(row))
+	}
+	return results, nil
 }
 
-func (s *JobStatisticsService) getSkillFrequencyPerDay(skill, skillField string) ([]bson.M, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	pipeline := mongo.Pipeline{
-		{{
-			Key: "$match",
-			Value: bson.D{{
-				Key: skillField,
-				Value: bson.D{{
-					Key:   "$regex",
-					Value: primitive.Regex{Pattern: "^" + skill + "$", Options: "i"},
-				}},
-			}},
-		}},
-		{{
-			Key: "$group",
-			Value: bson.D{
-				{Key: "_id", Value: bson.D{{
-					Key: "$dateToString",
-					Value: bson.D{
-						{Key: "format", Value: "%Y-%m-%d"},
-						{Key: "date", Value: "$postingDate"},
-					},
-				}}},
-				{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-			},
-		}},
-		{{
-			Key: "$project",
-			Value: bson.D{
-				{Key: "_id", Value: 0},
-				{Key: "day", Value: "$_id"},
-				{Key: "count", Value: 1},
-			},
-		}},
-		{{Key: "$sort", Value: bson.D{{Key: "day", Value: 1}}}},
+func workType(remote string) string {
+	if remote == "true" {
+		return "Remote"
 	}
-	return s.aggregateResults(ctx, pipeline)
+	return "On-site"
 }
 
-func (s *JobStatisticsService) GetJobCategoryCounts() ([]bson.M, error) {
+// aggregate computes a statistic over the jobs matching the filter, in the
+// database if the storage supports it
+func (s *JobStatisticsService) aggregate(filter StatsFilter, query storage.StatsQuery) ([]storage.StatsRow, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: "$jobCategories"}},
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$jobCategories"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+	query.Filter = filter.jobFilter()
+
+	if s.aggregator == nil {
+		jobs, err := s.storage.FindJobs(ctx, query.Filter)
+		if err != nil {
+			return nil, err
+		}
+		return storage.ComputeStats(jobs, query)
 	}
 
-	return s.aggregateResults(ctx, pipeline)
-}
+	start := time.Now()
+	rows, err := s.aggregator.AggregateStats(ctx, query)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	domains.DBOperationDuration.WithLabelValues("aggregate_jobs", status).Observe(time.Since(start).Seconds())
+	domains.DBOperationsTotal.WithLabelValues("aggregate_jobs", status).Inc()
 
-func (s *JobStatisticsService) aggregateResults(ctx context.Context, pipeline mongo.Pipeline) ([]bson.M, error) {
-	return s.storage.AggregateJobs(ctx, pipeline)
+	return rows, err
 }
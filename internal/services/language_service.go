@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+
+	"job-scraper/internal/langdetect"
+	"job-scraper/internal/processor/postprocess"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LanguageDetectResult fasst die Spracherkennung gespeicherter Jobs zusammen
+type LanguageDetectResult struct {
+	DryRun     bool           `json:"dryRun"`
+	Total      int            `json:"total"`
+	Detected   int            `json:"detected"`
+	Unknown    int            `json:"unknown"`
+	Failed     int            `json:"failed"`
+	ByLanguage map[string]int `json:"byLanguage"`
+}
+
+// DetectStoredLanguages records the posting language of stored jobs that have none yet.
+// Like the detect_language stage it prefers the raw source over the extracted description.
+func DetectStoredLanguages(ctx context.Context, jobStorage storage.Storage, detector *langdetect.Detector, dryRun bool) (LanguageDetectResult, error) {
+	result := LanguageDetectResult{DryRun: dryRun, ByLanguage: make(map[string]int)}
+
+	jobs, err := jobStorage.FindJobs(ctx, storage.JobFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if job.PostingLanguage != "" {
+			continue
+		}
+		result.Total++
+
+		job.PostingLanguage = detector.Detect(postprocess.PostingText(job))
+		if job.PostingLanguage == "" {
+			result.Unknown++
+			log.Debug().Str("job_url", job.URL).Msg("Posting language could not be detected")
+			continue
+		}
+		result.Detected++
+		result.ByLanguage[job.PostingLanguage]++
+		if dryRun {
+			continue
+		}
+
+		if err := jobStorage.UpdateJob(ctx, job); err != nil {
+			result.Failed++
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update posting language")
+		}
+	}
+
+	log.Info().
+		Bool("dry_run", dryRun).
+		Int("total", result.Total).
+		Int("detected", result.Detected).
+		Int("unknown", result.Unknown).
+		Int("failed", result.Failed).
+		Msg("Language detection finished")
+
+	return result, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Gründe, aus denen ein Job geschlossen wird
+const (
+	CloseReasonExpired = "expired" // the expiration date has passed
+	CloseReasonRemoved = "removed" // the posting is no longer online
+)
+
+// HTTPClient sends the online checks of the lifecycle pass
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// LifecycleConfig steuert, wann offene Jobs online geprüft werden
+type LifecycleConfig struct {
+	// MissingAfter is how long a job may be missing from the scrapes before it is
+	// checked online. The scrapers only read the first pages, so a missing job may
+	// still be online.
+	MissingAfter time.Duration
+	MaxChecks    int // online checks per pass, the jobs missing longest first; 0 checks all
+}
+
+// LifecycleResult fasst einen Durchlauf über die offenen Jobs zusammen
+type LifecycleResult struct {
+	DryRun    bool `json:"dryRun"`
+	OpenJobs  int  `json:"openJobs"`
+	Expired   int  `json:"expired"`   // closed at their expiration date
+	Checked   int  `json:"checked"`   // checked online
+	Removed   int  `json:"removed"`   // closed because the posting is gone
+	Confirmed int  `json:"confirmed"` // still online
+	Failed    int  `json:"failed"`    // check or update failed, retried in the next pass
+}
+
+// LifecycleService closes jobs whose posting has expired or was taken offline, so
+// that IsActive and ClosedAt reflect the posting instead of the extraction
+type LifecycleService struct {
+	storage storage.Storage
+	client  HTTPClient
+	config  LifecycleConfig
+	now     func() time.Time
+}
+
+// NewLifecycleService erstellt eine neue Instanz des LifecycleService
+func NewLifecycleService(storage storage.Storage, client HTTPClient, config LifecycleConfig) *LifecycleService {
+	return &LifecycleService{
+		storage: storage,
+		client:  client,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// CheckJobs runs one pass over the open jobs. Jobs past their expiration date are
+// closed at that date, unless a scraper has seen them since. Jobs missing from the
+// scrapes for MissingAfter are requested: a posting answering 404 or 410 is closed,
+// one that answers is marked as seen. With dryRun, no job is updated.
+func (s *LifecycleService) CheckJobs(ctx context.Context, dryRun bool) (*LifecycleResult, error) {
+	jobs, err := s.storage.FindJobs(ctx, storage.JobFilter{OpenOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	result := &LifecycleResult{DryRun: dryRun, OpenJobs: len(jobs)}
+	var missing []models.Job
+	for _, job := range jobs {
+		switch {
+		case isExpired(job, now):
+			result.Expired++
+			s.closeJob(ctx, job, job.ExpirationDate, CloseReasonExpired, result)
+		case now.Sub(lastSeen(job)) >= s.config.MissingAfter:
+			missing = append(missing, job)
+		}
+	}
+
+	slices.SortFunc(missing, func(a, b models.Job) int { return lastSeen(a).Compare(lastSeen(b)) })
+	if s.config.MaxChecks > 0 && len(missing) > s.config.MaxChecks {
+		missing = missing[:s.config.MaxChecks]
+	}
+
+	for _, job := range missing {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		result.Checked++
+		gone, err := s.isGone(ctx, job.URL)
+		if err != nil {
+			result.Failed++
+			domains.LifecycleChecks.WithLabelValues("failed").Inc()
+			log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to check job posting")
+			continue
+		}
+		if gone {
+			result.Removed++
+			domains.LifecycleChecks.WithLabelValues(CloseReasonRemoved).Inc()
+			s.closeJob(ctx, job, now, CloseReasonRemoved, result)
+			continue
+		}
+
+		result.Confirmed++
+		domains.LifecycleChecks.WithLabelValues("online").Inc()
+		if !dryRun {
+			markSeen(&job, now)
+			if err := s.storage.UpdateJob(ctx, job); err != nil {
+				result.Failed++
+				log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update job")
+			}
+		}
+	}
+	return result, nil
+}
+
+// Run checks the open jobs on start and then periodically until the context is cancelled
+func (s *LifecycleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.CheckJobs(ctx, false)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			log.Error().Err(err).Msg("Failed to check job lifecycle")
+		default:
+			log.Info().
+				Int("open_jobs", result.OpenJobs).
+				Int("expired", result.Expired).
+				Int("checked", result.Checked).
+				Int("removed", result.Removed).
+				Int("failed", result.Failed).
+				Msg("Checked job lifecycle")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *LifecycleService) closeJob(ctx context.Context, job models.Job, at time.Time, reason string, result *LifecycleResult) {
+	if result.DryRun {
+		return
+	}
+	closeJob(&job, at)
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		result.Failed++
+		log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to close job")
+		return
+	}
+	domains.ClosedJobs.WithLabelValues(reason).Inc()
+	log.Info().Str("job_url", job.URL).Str("reason", reason).Time("closed_at", at).Msg("Closed job")
+}
+
+// isGone requests the posting. Servers that do not allow HEAD are asked with GET.
+// Other answers than success or 404/410 are errors, as they say nothing about the posting.
+func (s *LifecycleService) isGone(ctx context.Context, url string) (bool, error) {
+	status, err := s.status(ctx, http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = s.status(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return true, nil
+	case status >= 200 && status < 300:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status code: %d", status)
+	}
+}
+
+func (s *LifecycleService) status(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// isExpired reports whether the expiration date has passed without the job being
+// seen since. Expiration dates before the posting date are extraction errors.
+func isExpired(job models.Job, now time.Time) bool {
+	switch {
+	case job.ExpirationDate.IsZero() || job.ExpirationDate.Before(job.PostingDate):
+		return false
+	case job.LastSeenAt != nil && !job.LastSeenAt.Before(job.ExpirationDate):
+		return false
+	default:
+		return job.ExpirationDate.Before(now)
+	}
+}
+
+// lastSeen returns when a scraper or check last found the job. Jobs stored before
+// this was recorded were last seen when they were stored.
+func lastSeen(job models.Job) time.Time {
+	switch {
+	case job.LastSeenAt != nil:
+		return *job.LastSeenAt
+	case !job.ID.IsZero():
+		return job.ID.Timestamp()
+	default:
+		return job.PostingDate
+	}
+}
+
+// markSeen records that the posting is online, which reopens a closed job
+func markSeen(job *models.Job, at time.Time) {
+	job.LastSeenAt = &at
+	job.IsActive = true
+	job.ClosedAt = nil
+}
+
+func closeJob(job *models.Job, at time.Time) {
+	job.IsActive = false
+	job.ClosedAt = &at
+}
+
+// keepLifecycle copies the lifecycle fields of the stored job to its re-extracted
+// version, as the processor does not know them
+func keepLifecycle(job *models.Job, stored models.Job) {
+	job.IsActive = stored.IsActive
+	job.ClosedAt = stored.ClosedAt
+	job.LastSeenAt = stored.LastSeenAt
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckJobs(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/removed":
+			w.WriteHeader(http.StatusGone)
+		case "/error":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, days)
+		return &t
+	}
+	jobs := []models.Job{
+		{URL: server.URL + "/expired", PostingDate: now.AddDate(0, -1, 0), ExpirationDate: *at(-2), LastSeenAt: at(-5), IsActive: true},
+		// Nach dem Ablaufdatum noch gelistet, das Datum war also falsch
+		{URL: server.URL + "/extended", PostingDate: now.AddDate(0, -1, 0), ExpirationDate: *at(-2), LastSeenAt: at(-1), IsActive: true},
+		{URL: server.URL + "/removed", LastSeenAt: at(-4), IsActive: true},
+		{URL: server.URL + "/no-head", LastSeenAt: at(-3)},
+		{URL: server.URL + "/error", LastSeenAt: at(-3), IsActive: true},
+		{URL: server.URL + "/recent", LastSeenAt: at(-1), IsActive: true},
+		{URL: server.URL + "/closed", LastSeenAt: at(-30), ClosedAt: at(-20)},
+	}
+	for _, job := range jobs {
+		require.NoError(t, store.SaveJob(ctx, job))
+	}
+
+	service := NewLifecycleService(store, server.Client(), LifecycleConfig{MissingAfter: 48 * time.Hour})
+	service.now = func() time.Time { return now }
+
+	result, err := service.CheckJobs(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, &LifecycleResult{DryRun: true, OpenJobs: 6, Expired: 1, Checked: 3, Removed: 1, Confirmed: 1, Failed: 1}, result)
+	open, err := store.FindJobs(ctx, storage.JobFilter{OpenOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, open, 6)
+
+	result, err = service.CheckJobs(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	assert.Equal(t, 1, result.Removed)
+
+	expired, err := store.GetJobByURL(ctx, server.URL+"/expired")
+	require.NoError(t, err)
+	assert.False(t, expired.IsActive)
+	assert.Equal(t, jobs[0].ExpirationDate, *expired.ClosedAt)
+
+	removed, err := store.GetJobByURL(ctx, server.URL+"/removed")
+	require.NoError(t, err)
+	assert.False(t, removed.IsActive)
+	assert.Equal(t, now, *removed.ClosedAt)
+
+	// Ein erreichbares Inserat gilt als gesehen und aktiv
+	online, err := store.GetJobByURL(ctx, server.URL+"/no-head")
+	require.NoError(t, err)
+	assert.True(t, online.IsActive)
+	assert.Nil(t, online.ClosedAt)
+	assert.Equal(t, now, *online.LastSeenAt)
+
+	// Nur die am längsten vermissten Jobs werden geprüft
+	service.config.MaxChecks = 1
+	service.now = func() time.Time { return now.AddDate(0, 0, 3) }
+	result, err = service.CheckJobs(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Checked)
+	assert.Equal(t, 1, result.Failed)
+}
+
+func TestScrapingReopensClosedJobs(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+	service := NewScraperService(store, firstLineProcessor)
+	scraper := fakeScraper{jobs: []models.Job{rawJob("https://example.com/1", "Go Developer")}}
+
+	_, err = service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	job, err := store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	require.NotNil(t, job.LastSeenAt)
+	assert.True(t, job.IsActive)
+
+	closeJob(job, time.Now().Add(-time.Hour))
+	require.NoError(t, store.UpdateJob(ctx, *job))
+
+	_, err = service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	job, err = store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.True(t, job.IsActive)
+	assert.Nil(t, job.ClosedAt)
+
+	versions, err := store.GetJobVersions(ctx, job.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+
+	"job-scraper/internal/geo"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LocationResolveResult fasst die Auflösung gespeicherter Arbeitsorte zusammen
+type LocationResolveResult struct {
+	DryRun     bool `json:"dryRun"`
+	Total      int  `json:"total"`
+	Resolved   int  `json:"resolved"`
+	Unresolved int  `json:"unresolved"`
+	Failed     int  `json:"failed"`
+}
+
+// ResolveStoredLocations resolves the location text of stored jobs that have no structured places yet
+func ResolveStoredLocations(ctx context.Context, jobStorage storage.Storage, gazetteer *geo.Gazetteer, dryRun bool) (LocationResolveResult, error) {
+	result := LocationResolveResult{DryRun: dryRun}
+
+	jobs, err := jobStorage.FindJobs(ctx, storage.JobFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if len(job.Locations) > 0 || job.Location == "" {
+			continue
+		}
+		result.Total++
+
+		job.Locations = gazetteer.Resolve(job.Location)
+		if len(job.Locations) == 0 {
+			result.Unresolved++
+			log.Debug().Str("location", job.Location).Msg("Location not found in gazetteer")
+			continue
+		}
+		result.Resolved++
+		if dryRun {
+			continue
+		}
+
+		if err := jobStorage.UpdateJob(ctx, job); err != nil {
+			result.Failed++
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update resolved locations")
+		}
+	}
+
+	log.Info().
+		Bool("dry_run", dryRun).
+		Int("total", result.Total).
+		Int("resolved", result.Resolved).
+		Int("unresolved", result.Unresolved).
+		Int("failed", result.Failed).
+		Msg("Location resolution finished")
+
+	return result, nil
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrReprocessingRunning is returned when a reprocessing run is started while another one is active
+var ErrReprocessingRunning = errors.New("reprocessing is already running")
+
+// progressLogInterval bestimmt, nach wie vielen Jobs der Fortschritt geloggt wird
+const progressLogInterval = 25
+
+// ReprocessRequest selects the jobs of a reprocessing run.
+// With FailedOnly the jobs whose processing failed are retried and Filter is ignored.
+type ReprocessRequest struct {
+	Filter     storage.JobFilter
+	FailedOnly bool
+	DryRun     bool
+}
+
+// ReprocessProgress beschreibt den Fortschritt eines Reprocessing-Laufs
+type ReprocessProgress struct {
+	Status     string     `json:"status"`
+	DryRun     bool       `json:"dryRun"`
+	FailedOnly bool       `json:"failedOnly"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Updated    int        `json:"updated"`
+	Failed     int        `json:"failed"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// URLs contains the selected jobs of a dry run
+	URLs []string `json:"urls,omitempty"`
+}
+
+type reprocessItem struct {
+	job    models.Job
+	failed bool
+}
+
+// ReprocessService runs stored jobs through the current processor again,
+// e.g. after the prompt or the model has changed
+type ReprocessService struct {
+	storage   storage.Storage
+	processor processor.JobProcessor
+
+	mu       sync.Mutex
+	running  bool
+	progress *ReprocessProgress
+}
+
+func NewReprocessService(storage storage.Storage, processor processor.JobProcessor) *ReprocessService {
+	return &ReprocessService{
+		storage:   storage,
+		processor: processor,
+	}
+}
+
+// Start runs the reprocessing in the background. Dry runs are executed synchronously.
+func (s *ReprocessService) Start(ctx context.Context, req ReprocessRequest) (ReprocessProgress, error) {
+	if req.DryRun {
+		return s.Run(ctx, req, nil)
+	}
+
+	items, err := s.begin(ctx, req)
+	if err != nil {
+		return ReprocessProgress{}, err
+	}
+
+	go s.execute(context.WithoutCancel(ctx), items, nil)
+	return s.Progress(), nil
+}
+
+// Run executes the reprocessing and blocks until it is finished.
+// onProgress is called after every job and may be nil.
+func (s *ReprocessService) Run(ctx context.Context, req ReprocessRequest, onProgress func(ReprocessProgress)) (ReprocessProgress, error) {
+	items, err := s.begin(ctx, req)
+	if err != nil {
+		return ReprocessProgress{}, err
+	}
+
+	if req.DryRun {
+		return s.finish(nil), nil
+	}
+	return s.execute(ctx, items, onProgress)
+}
+
+// Progress returns the state of the current or last run
+func (s *ReprocessService) Progress() ReprocessProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress == nil {
+		return ReprocessProgress{Status: "Idle"}
+	}
+	return *s.progress
+}
+
+func (s *ReprocessService) begin(ctx context.Context, req ReprocessRequest) ([]reprocessItem, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrReprocessingRunning
+	}
+	s.running = true
+	s.progress = &ReprocessProgress{
+		Status:     "Running",
+		DryRun:     req.DryRun,
+		FailedOnly: req.FailedOnly,
+		StartedAt:  time.Now(),
+	}
+	s.mu.Unlock()
+
+	items, err := s.selectJobs(ctx, req)
+	if err != nil {
+		s.finish(err)
+		return nil, err
+	}
+
+	s.update(func(p *ReprocessProgress) {
+		p.Total = len(items)
+		if req.DryRun {
+			p.URLs = make([]string, 0, len(items))
+			for _, item := range items {
+				p.URLs = append(p.URLs, item.job.URL)
+			}
+		}
+	})
+
+	return items, nil
+}
+
+func (s *ReprocessService) selectJobs(ctx context.Context, req ReprocessRequest) ([]reprocessItem, error) {
+	if req.FailedOnly {
+		failedJobs, err := s.storage.GetFailedJobs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]reprocessItem, 0, len(failedJobs))
+		for _, failedJob := range failedJobs {
+			items = append(items, reprocessItem{
+				job:    models.Job{URL: failedJob.URL, Description: failedJob.Description, Source: failedJob.Source},
+				failed: true,
+			})
+		}
+		return items, nil
+	}
+
+	jobs, err := s.storage.FindJobs(ctx, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]reprocessItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, reprocessItem{job: job})
+	}
+	return items, nil
+}
+
+func (s *ReprocessService) execute(ctx context.Context, items []reprocessItem, onProgress func(ReprocessProgress)) (ReprocessProgress, error) {
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return s.finish(err), err
+		}
+
+		err := s.reprocessJob(ctx, item)
+		var budgetErr *apperrors.BudgetExceededError
+		if errors.As(err, &budgetErr) {
+			log.Warn().
+				Str("window", budgetErr.Window).
+				Time("resets_at", budgetErr.ResetsAt).
+				Int("remaining_jobs", len(items)-i).
+				Msg("LLM budget exhausted, reprocessing stopped")
+			return s.finish(err), err
+		}
+
+		progress := s.update(func(p *ReprocessProgress) {
+			p.Processed++
+			if err != nil {
+				p.Failed++
+			} else {
+				p.Updated++
+			}
+		})
+		if err != nil {
+			log.Error().Err(err).Str("job_url", item.job.URL).Msg("Failed to reprocess job")
+		}
+		if progress.Processed%progressLogInterval == 0 || progress.Processed == progress.Total {
+			log.Info().
+				Int("processed", progress.Processed).
+				Int("total", progress.Total).
+				Int("failed", progress.Failed).
+				Msg("Reprocessing progress")
+		}
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	return s.finish(nil), nil
+}
+
+func (s *ReprocessService) reprocessJob(ctx context.Context, item reprocessItem) error {
+	input := models.Job{
+		ID:          item.job.ID,
+		URL:         item.job.URL,
+		Description: sourceDescription(item.job),
+		Source:      item.job.Source,
+	}
+
+	processedJob, err := s.processor.Process(ctx, input)
+	if err != nil {
+		var budgetErr *apperrors.BudgetExceededError
+		if item.failed && !errors.As(err, &budgetErr) {
+			failedJob := models.FailedJob{URL: input.URL, Description: input.Description, Source: input.Source, Error: err.Error()}
+			if saveErr := s.storage.SaveFailedJob(ctx, failedJob); saveErr != nil {
+				log.Warn().Err(saveErr).Str("job_url", input.URL).Msg("Failed to record failed job")
+			}
+		}
+		return err
+	}
+
+	processedJob.Source = input.Source
+	if !item.failed {
+		processedJob.ID = item.job.ID
+		keepLifecycle(&processedJob, item.job)
+		keepDuplicateLink(&processedJob, item.job)
+		return s.storage.UpdateJob(ctx, processedJob)
+	}
+
+	if err := s.storage.SaveJob(ctx, processedJob); err != nil {
+		return err
+	}
+	return s.storage.DeleteFailedJob(ctx, input.URL)
+}
+
+// sourceDescription returns the text that is sent to the processor. Jobs stored
+// before the raw payload was kept only have the extracted description.
+func sourceDescription(job models.Job) string {
+	if job.Source != nil && job.Source.RawPayload != "" {
+		return job.Source.RawPayload
+	}
+	return job.Description
+}
+
+func (s *ReprocessService) update(fn func(p *ReprocessProgress)) ReprocessProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(s.progress)
+	return *s.progress
+}
+
+func (s *ReprocessService) finish(err error) ReprocessProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.running = false
+	s.progress.FinishedAt = &now
+	if err != nil {
+		s.progress.Status = "Failed"
+		s.progress.Error = err.Error()
+	} else {
+		s.progress.Status = "Completed"
+	}
+	return *s.progress
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// titleProcessor setzt den Titel auf die Beschreibung und zählt die Aufrufe
+func titleProcessor(calls *int) processor.Func {
+	return func(ctx context.Context, job models.Job) (models.Job, error) {
+		*calls++
+		job.Title = job.Description
+		return job, nil
+	}
+}
+
+func TestReprocessDryRun(t *testing.T) {
+	store := newQueueStorage()
+	store.jobs["https://example.com/1"] = models.Job{URL: "https://example.com/1", Description: "Go Developer"}
+	store.jobs["https://example.com/2"] = models.Job{URL: "https://example.com/2", Description: "Data Analyst"}
+
+	calls := 0
+	progress, err := NewReprocessService(store, titleProcessor(&calls)).Run(context.Background(), ReprocessRequest{DryRun: true}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Completed", progress.Status)
+	assert.Equal(t, 2, progress.Total)
+	assert.Equal(t, 0, progress.Processed)
+	assert.ElementsMatch(t, []string{"https://example.com/1", "https://example.com/2"}, progress.URLs)
+	assert.Zero(t, calls, "a dry run must not call the processor")
+	assert.Empty(t, store.jobs["https://example.com/1"].Title)
+}
+
+func TestReprocessFilterSelection(t *testing.T) {
+	store := newQueueStorage()
+	store.jobs["https://example.com/go"] = models.Job{
+		URL: "https://example.com/go", Description: "Go Developer", JobCategories: []string{"SOFTWARE_DEVELOPER"},
+	}
+	store.jobs["https://example.com/sales"] = models.Job{
+		URL: "https://example.com/sales", Description: "Sales", JobCategories: []string{"SALES_EXECUTIVE"},
+	}
+
+	calls := 0
+	filter := storage.JobFilter{Category: "SOFTWARE_DEVELOPER", PromptVersion: "v1"}
+	progress, err := NewReprocessService(store, titleProcessor(&calls)).Run(context.Background(), ReprocessRequest{Filter: filter}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, filter, store.filter)
+	assert.Equal(t, 1, progress.Total)
+	assert.Equal(t, 1, progress.Updated)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "Go Developer", store.jobs["https://example.com/go"].Title)
+	assert.Empty(t, store.jobs["https://example.com/sales"].Title)
+}
+
+func TestReprocessFailedOnly(t *testing.T) {
+	store := newQueueStorage()
+	store.jobs["https://example.com/stored"] = models.Job{URL: "https://example.com/stored", Description: "Stored"}
+	store.failed["https://example.com/ok"] = models.FailedJob{URL: "https://example.com/ok", Description: "Go Developer", Attempts: 1}
+	store.failed["https://example.com/broken"] = models.FailedJob{URL: "https://example.com/broken", Description: "broken", Attempts: 1}
+	// Jobs in der Budget-Warteschlange gehören dem Queue-Worker und werden nicht erneut verarbeitet
+	store.queued["https://example.com/queued"] = models.QueuedJob{URL: "https://example.com/queued"}
+
+	extract := processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		if job.Description == "broken" {
+			return job, errors.New("invalid response")
+		}
+		job.Title = job.Description
+		return job, nil
+	})
+	req := ReprocessRequest{FailedOnly: true, Filter: storage.JobFilter{Category: "SOFTWARE_DEVELOPER"}}
+	progress, err := NewReprocessService(store, extract).Run(context.Background(), req, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, progress.Total)
+	assert.Equal(t, 1, progress.Updated)
+	assert.Equal(t, 1, progress.Failed)
+
+	assert.Equal(t, "Go Developer", store.jobs["https://example.com/ok"].Title)
+	assert.NotContains(t, store.failed, "https://example.com/ok")
+	assert.Equal(t, "invalid response", store.failed["https://example.com/broken"].Error)
+	assert.Empty(t, store.jobs["https://example.com/stored"].Title, "the filter is ignored and stored jobs are untouched")
+	assert.Contains(t, store.queued, "https://example.com/queued")
+}
+
+func TestReprocessKeepsDuplicateLinks(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+
+	original := rawJob("https://example.com/1", "Go Developer\nZürich")
+	original.Fingerprint = "00000000000000ff"
+	require.NoError(t, store.SaveJob(ctx, original))
+	stored, err := store.GetJobByURL(ctx, original.URL)
+	require.NoError(t, err)
+
+	duplicate := rawJob("https://other.example.com/1", "Go Developer\nZürich")
+	duplicate.Fingerprint = "00000000000000fe"
+	duplicate.DuplicateOf = &stored.ID
+	require.NoError(t, store.SaveJob(ctx, duplicate))
+
+	progress, err := NewReprocessService(store, firstLineProcessor).Run(ctx, ReprocessRequest{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.Updated)
+
+	job, err := store.GetJobByURL(ctx, duplicate.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Go Developer", job.Title)
+	assert.Equal(t, "00000000000000fe", job.Fingerprint)
+	require.NotNil(t, job.DuplicateOf)
+	assert.Equal(t, stored.ID, *job.DuplicateOf)
+
+	originals, err := store.FindJobs(ctx, storage.JobFilter{OriginalsOnly: true})
+	require.NoError(t, err)
+	require.Len(t, originals, 1)
+	assert.Equal(t, original.URL, originals[0].URL)
+}
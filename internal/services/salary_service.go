@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+
+	"job-scraper/internal/salary"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SalaryParseResult fasst die Umstellung gespeicherter Lohnangaben zusammen
+type SalaryParseResult struct {
+	DryRun   bool `json:"dryRun"`
+	Total    int  `json:"total"`
+	Parsed   int  `json:"parsed"`
+	Unparsed int  `json:"unparsed"`
+	Failed   int  `json:"failed"`
+}
+
+// ParseStoredSalaries converts the plain-text salaries of jobs stored before
+// salaries were structured. Salaries that contain no amount are kept as text.
+func ParseStoredSalaries(ctx context.Context, jobStorage storage.Storage, dryRun bool) (SalaryParseResult, error) {
+	result := SalaryParseResult{DryRun: dryRun}
+
+	jobs, err := jobStorage.FindJobs(ctx, storage.JobFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if job.Salary == nil || job.Salary.IsStructured() || job.Salary.Currency != "" {
+			continue
+		}
+		result.Total++
+
+		job.Salary = salary.FromText(job.Salary.Text)
+		if job.Salary.IsStructured() {
+			result.Parsed++
+		} else {
+			result.Unparsed++
+		}
+		if dryRun {
+			continue
+		}
+
+		if err := jobStorage.UpdateJob(ctx, job); err != nil {
+			result.Failed++
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update parsed salary")
+		}
+	}
+
+	log.Info().
+		Bool("dry_run", dryRun).
+		Int("total", result.Total).
+		Int("parsed", result.Parsed).
+		Int("unparsed", result.Unparsed).
+		Int("failed", result.Failed).
+		Msg("Salary parsing finished")
+
+	return result, nil
+}
@@ -2,10 +2,17 @@ package services
 
 import (
 	"context"
+	"errors"
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/dedup"
+	"job-scraper/internal/metrics/domains"
 	"job-scraper/internal/models"
 	"job-scraper/internal/processor"
+	"job-scraper/internal/processor/cleaner"
 	"job-scraper/internal/scraper"
 	"job-scraper/internal/storage"
+	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -14,12 +21,22 @@ import (
 type ScraperService struct {
 	storage   storage.Storage
 	processor processor.JobProcessor
+
+	// Erkennung von Reposts und Duplikaten über mehrere Quellen, nil wenn deaktiviert
+	duplicates *DuplicateDetection
+
+	// Jobs, die wegen eines erschöpften LLM-Budgets nicht verarbeitet werden konnten.
+	// Die Warteschlange wird zusätzlich gespeichert, siehe RestoreQueue.
+	queueMu sync.Mutex
+	queue   []models.Job
+	queued  map[string]bool
 }
 
 // ScrapingResult repräsentiert das Ergebnis eines Scraping-Durchlaufs
 type ScrapingResult struct {
 	TotalJobs     int
 	ProcessedJobs int
+	QueuedJobs    int
 	Status        string
 	Error         error
 }
@@ -29,9 +46,15 @@ func NewScraperService(storage storage.Storage, processor processor.JobProcessor
 	return &ScraperService{
 		storage:   storage,
 		processor: processor,
+		queued:    make(map[string]bool),
 	}
 }
 
+// SetDuplicateDetection enables linking near-duplicate jobs to the first posting
+func (s *ScraperService) SetDuplicateDetection(cfg DuplicateDetection) {
+	s.duplicates = &cfg
+}
+
 // ExecuteScraping führt den vollständigen Scraping-Workflow aus
 func (s *ScraperService) ExecuteScraping(ctx context.Context, scraper scraper.Scraper, pages int) (*ScrapingResult, error) {
 	result := &ScrapingResult{
@@ -46,8 +69,15 @@ func (s *ScraperService) ExecuteScraping(ctx context.Context, scraper scraper.Sc
 		return result, getExistingUrlError
 	}
 
+	index, err := s.fingerprintIndex(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load job fingerprints")
+		result.Status = "Failed"
+		result.Error = err
+		return result, err
+	}
+
 	var jobs []models.Job
-	var err error
 
 	// Type assertion für PaginatedScraper
 	if pScraper, ok := scraper.(interface {
@@ -72,7 +102,7 @@ func (s *ScraperService) ExecuteScraping(ctx context.Context, scraper scraper.Sc
 		case <-ctx.Done():
 			return result, ctx.Err()
 		default:
-			if err := s.processJob(ctx, job, existingURLs, result); err != nil {
+			if err := s.processJob(ctx, job, existingURLs, index, result); err != nil {
 				log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to process job")
 			}
 		}
@@ -82,20 +112,72 @@ func (s *ScraperService) ExecuteScraping(ctx context.Context, scraper scraper.Sc
 	return result, nil
 }
 
-func (s *ScraperService) processJob(ctx context.Context, job models.Job, existingURLs map[string]bool, result *ScrapingResult) error {
+// fingerprintIndex loads the fingerprints of the stored originals, nil if duplicate detection is disabled
+func (s *ScraperService) fingerprintIndex(ctx context.Context) (*dedup.Index, error) {
+	if s.duplicates == nil {
+		return nil, nil
+	}
+	return loadFingerprintIndex(ctx, s.storage, *s.duplicates)
+}
+
+func (s *ScraperService) processJob(ctx context.Context, job models.Job, existingURLs map[string]bool, index *dedup.Index, result *ScrapingResult) error {
+	var stored *models.Job
 	if _, exists := existingURLs[job.URL]; exists {
-		log.Info().Str("job_url", job.URL).Msg("Job already exists, skipping processing")
-		return nil
+		var changed bool
+		var err error
+		stored, changed, err = s.storedVersion(ctx, job)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			log.Info().Str("job_url", job.URL).Msg("Job already exists, skipping processing")
+			s.markSeen(ctx, stored)
+			return nil
+		}
+		log.Info().Str("job_url", job.URL).Msg("Job content changed, processing new version")
 	}
 
 	processedJob, err := s.processor.Process(ctx, job)
 	if err != nil {
+		var budgetErr *apperrors.BudgetExceededError
+		if errors.As(err, &budgetErr) {
+			s.deferJob(ctx, job)
+			result.QueuedJobs++
+			log.Warn().
+				Str("job_url", job.URL).
+				Str("window", budgetErr.Window).
+				Time("resets_at", budgetErr.ResetsAt).
+				Msg("LLM budget exhausted, job queued")
+			return nil
+		}
+		s.recordFailure(ctx, job, err)
 		return err
 	}
+	// Der Prozessor überschreibt die Beschreibung, die Rohdaten bleiben erhalten
+	processedJob.Source = job.Source
+	if stored != nil {
+		processedJob.ID = stored.ID
+	}
+	markSeen(&processedJob, time.Now())
+
+	var fingerprint dedup.Fingerprint
+	if index != nil {
+		var similarity float64
+		fingerprint, similarity = markDuplicate(&processedJob, index, time.Now())
+		if processedJob.DuplicateOf != nil {
+			recordDuplicate(processedJob, similarity)
+		}
+	}
 
 	if err := s.storage.SaveJob(ctx, processedJob); err != nil {
 		return err
 	}
+	if index != nil && processedJob.DuplicateOf == nil {
+		s.addToIndex(ctx, index, processedJob, stored != nil, fingerprint)
+	}
+	if err := s.storage.DeleteFailedJob(ctx, job.URL); err != nil {
+		log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to clear failed job record")
+	}
 
 	result.ProcessedJobs++
 	log.Info().
@@ -105,3 +187,202 @@ func (s *ScraperService) processJob(ctx context.Context, job models.Job, existin
 
 	return nil
 }
+
+// deferJob queues a job until the budget resets. The job is also stored, so the
+// queue survives a restart.
+func (s *ScraperService) deferJob(ctx context.Context, job models.Job) {
+	if err := s.storage.QueueJob(ctx, job); err != nil {
+		log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to store queued job")
+	}
+	s.enqueue(job)
+}
+
+// RestoreQueue queues the jobs stored before a restart and returns their number
+func (s *ScraperService) RestoreQueue(ctx context.Context) (int, error) {
+	queuedJobs, err := s.storage.GetQueuedJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	jobs := make([]models.Job, 0, len(queuedJobs))
+	for _, queuedJob := range queuedJobs {
+		jobs = append(jobs, queuedJob.Job)
+	}
+	s.enqueue(jobs...)
+	return len(jobs), nil
+}
+
+// addToIndex registers a saved original, so later jobs of the run are compared with it.
+// A job missing from the URL snapshot may have been stored by a parallel run in the
+// meantime, SaveJob then keeps the ID of that run, which is read back.
+func (s *ScraperService) addToIndex(ctx context.Context, index *dedup.Index, job models.Job, known bool, fingerprint dedup.Fingerprint) {
+	if !known {
+		saved, err := s.storage.GetJobByURL(ctx, job.URL)
+		if err != nil {
+			log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to read saved job, not used for duplicate detection")
+			return
+		}
+		job.ID = saved.ID
+	}
+	index.Add(job.ID, fingerprint, job.ID.Timestamp())
+}
+
+// storedVersion returns the stored job of a re-scraped posting and whether the posting
+// changed since. The cleaned text is compared, so markup or tracking parameters do not
+// count as change. Jobs stored without raw payload cannot be compared and count as unchanged.
+func (s *ScraperService) storedVersion(ctx context.Context, job models.Job) (*models.Job, bool, error) {
+	stored, err := s.storage.GetJobByURL(ctx, job.URL)
+	if err != nil {
+		var notFoundErr *apperrors.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	if stored.Source == nil || stored.Source.RawPayload == "" || job.Source == nil {
+		return stored, false, nil
+	}
+	return stored, cleaner.Clean(stored.Source.RawPayload) != cleaner.Clean(job.Source.RawPayload), nil
+}
+
+// markSeen records that a scraper found the unchanged job again and reopens it if
+// it has been closed
+func (s *ScraperService) markSeen(ctx context.Context, stored *models.Job) {
+	if stored.ClosedAt != nil {
+		log.Info().Str("job_url", stored.URL).Msg("Closed job is listed again, reopening")
+	}
+	markSeen(stored, time.Now())
+	if err := s.storage.UpdateJob(ctx, *stored); err != nil {
+		log.Warn().Err(err).Str("job_url", stored.URL).Msg("Failed to record job as seen")
+	}
+}
+
+// recordFailure stores the failed job, so it can be picked up by a later reprocessing run
+func (s *ScraperService) recordFailure(ctx context.Context, job models.Job, processErr error) {
+	failedJob := models.FailedJob{
+		URL:         job.URL,
+		Description: job.Description,
+		Source:      job.Source,
+		Error:       processErr.Error(),
+	}
+	if err := s.storage.SaveFailedJob(ctx, failedJob); err != nil {
+		log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to record failed job")
+	}
+}
+
+// QueueLength returns the number of jobs waiting for the LLM budget to reset
+func (s *ScraperService) QueueLength() int {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	return len(s.queue)
+}
+
+// ProcessQueue retries the queued jobs. It stops as soon as the budget is exhausted again.
+func (s *ScraperService) ProcessQueue(ctx context.Context) (*ScrapingResult, error) {
+	jobs := s.dequeueAll()
+	result := &ScrapingResult{
+		Status:    "Running",
+		TotalJobs: len(jobs),
+	}
+	if len(jobs) == 0 {
+		result.Status = "Completed"
+		return result, nil
+	}
+
+	existingURLs, err := s.storage.GetExistingURLs(ctx)
+	if err != nil {
+		s.enqueue(jobs...)
+		result.Status = "Failed"
+		result.Error = err
+		return result, err
+	}
+
+	index, err := s.fingerprintIndex(ctx)
+	if err != nil {
+		s.enqueue(jobs...)
+		result.Status = "Failed"
+		result.Error = err
+		return result, err
+	}
+
+	for i, job := range jobs {
+		if ctx.Err() != nil {
+			s.enqueue(jobs[i:]...)
+			return result, ctx.Err()
+		}
+		if err := s.processJob(ctx, job, existingURLs, index, result); err != nil {
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to process queued job")
+		}
+		if result.QueuedJobs > 0 {
+			// Budget is still exhausted, keep the remaining jobs for the next window
+			s.enqueue(jobs[i+1:]...)
+			break
+		}
+		// Verarbeitet, übersprungen oder fehlgeschlagen, der Job verlässt die Warteschlange
+		if err := s.storage.DeleteQueuedJob(ctx, job.URL); err != nil {
+			log.Warn().Err(err).Str("job_url", job.URL).Msg("Failed to remove job from the stored queue")
+		}
+	}
+
+	result.Status = "Completed"
+	return result, nil
+}
+
+// RunQueueWorker restores the queue of an earlier run and then periodically retries
+// queued jobs until the context is cancelled
+func (s *ScraperService) RunQueueWorker(ctx context.Context, interval time.Duration) {
+	if restored, err := s.RestoreQueue(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to restore queued jobs")
+	} else if restored > 0 {
+		log.Info().Int("queued_jobs", restored).Msg("Restored jobs queued for the LLM budget")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.QueueLength() == 0 {
+				continue
+			}
+			result, err := s.ProcessQueue(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to process queued jobs")
+				continue
+			}
+			if result.ProcessedJobs > 0 {
+				log.Info().
+					Int("processed_jobs", result.ProcessedJobs).
+					Int("remaining_jobs", s.QueueLength()).
+					Msg("Processed queued jobs")
+			}
+		}
+	}
+}
+
+func (s *ScraperService) enqueue(jobs ...models.Job) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	for _, job := range jobs {
+		if s.queued[job.URL] {
+			continue
+		}
+		s.queued[job.URL] = true
+		s.queue = append(s.queue, job)
+	}
+	domains.QueuedJobs.Set(float64(len(s.queue)))
+}
+
+func (s *ScraperService) dequeueAll() []models.Job {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	jobs := s.queue
+	s.queue = nil
+	s.queued = make(map[string]bool)
+	domains.QueuedJobs.Set(0)
+	return jobs
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/processor"
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScraper liefert immer dieselben Jobs
+type fakeScraper struct {
+	jobs []models.Job
+}
+
+func (f fakeScraper) Scrape(ctx context.Context) ([]models.Job, error) { return f.jobs, nil }
+func (f fakeScraper) Name() string                                     { return "fake" }
+
+func rawJob(url, text string) models.Job {
+	return models.Job{URL: url, Description: text, Source: &models.SourcePayload{RawPayload: text}}
+}
+
+// firstLineProcessor nimmt die erste Zeile der Beschreibung als Titel und lehnt leere Beschreibungen ab
+var firstLineProcessor = processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+	if job.Description == "" {
+		return job, errors.New("empty description")
+	}
+	job.Title, _, _ = strings.Cut(job.Description, "\n")
+	job.MustSkills = []string{"Go"}
+	return job, nil
+})
+
+func TestExecuteScraping(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+	service := NewScraperService(store, firstLineProcessor)
+
+	scraper := fakeScraper{jobs: []models.Job{
+		rawJob("https://example.com/1", "Go Developer\nZürich"),
+		rawJob("https://example.com/2", ""),
+	}}
+	result, err := service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.TotalJobs)
+	assert.Equal(t, 1, result.ProcessedJobs)
+
+	failed, err := store.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "https://example.com/2", failed[0].URL)
+
+	// Unveränderte Jobs werden übersprungen, geänderte als neue Version gespeichert
+	scraper.jobs[1] = rawJob("https://example.com/2", "Platform Engineer")
+	result, err = service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ProcessedJobs)
+
+	scraper.jobs[0] = rawJob("https://example.com/1", "Senior Go Developer\nZürich")
+	result, err = service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ProcessedJobs)
+
+	job, err := store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.Equal(t, "Senior Go Developer", job.Title)
+	versions, err := store.GetJobVersions(ctx, job.ID.Hex())
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	failed, err = store.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// Statistiken werden aus den gespeicherten Jobs berechnet
+	skills, err := NewJobStatisticsService(store).GetTopSkills(StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, skills, 1)
+	assert.Equal(t, "Go", skills[0].ID)
+	assert.EqualValues(t, 2, skills[0].Count)
+}
+
+// queueStorage hält Jobs, fehlgeschlagene Jobs und die gespeicherte Warteschlange im Speicher
+type queueStorage struct {
+	storage.Storage
+	jobs   map[string]models.Job
+	failed map[string]models.FailedJob
+	queued map[string]models.QueuedJob
+	// filter is the last filter passed to FindJobs
+	filter storage.JobFilter
+}
+
+func newQueueStorage() *queueStorage {
+	return &queueStorage{
+		jobs:   map[string]models.Job{},
+		failed: map[string]models.FailedJob{},
+		queued: map[string]models.QueuedJob{},
+	}
+}
+
+func (s *queueStorage) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
+	urls := make(map[string]bool, len(s.jobs))
+	for url := range s.jobs {
+		urls[url] = true
+	}
+	return urls, nil
+}
+
+func (s *queueStorage) SaveJob(ctx context.Context, job models.Job) error {
+	s.jobs[job.URL] = job
+	return nil
+}
+
+// FindJobs only supports the category of the filter
+func (s *queueStorage) FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	s.filter = filter
+	var jobs []models.Job
+	for _, job := range s.jobs {
+		if filter.Category == "" || slices.Contains(job.JobCategories, filter.Category) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *queueStorage) UpdateJob(ctx context.Context, job models.Job) error {
+	s.jobs[job.URL] = job
+	return nil
+}
+
+func (s *queueStorage) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	s.failed[job.URL] = job
+	return nil
+}
+
+func (s *queueStorage) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	var jobs []models.FailedJob
+	for _, job := range s.failed {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *queueStorage) DeleteFailedJob(ctx context.Context, url string) error {
+	delete(s.failed, url)
+	return nil
+}
+
+func (s *queueStorage) QueueJob(ctx context.Context, job models.Job) error {
+	s.queued[job.URL] = models.QueuedJob{URL: job.URL, Job: job, QueuedAt: time.Now()}
+	return nil
+}
+
+func (s *queueStorage) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	var jobs []models.QueuedJob
+	for _, job := range s.queued {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *queueStorage) DeleteQueuedJob(ctx context.Context, url string) error {
+	delete(s.queued, url)
+	return nil
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := newQueueStorage()
+
+	exhausted := processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		return job, apperrors.NewBudgetExceededError("daily", time.Now().Add(time.Hour))
+	})
+	scraper := fakeScraper{jobs: []models.Job{{URL: "https://example.com/1", Description: "Go Developer"}}}
+	result, err := NewScraperService(store, exhausted).ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.QueuedJobs)
+	assert.Len(t, store.queued, 1)
+	assert.Empty(t, store.failed, "budget-deferred jobs are not failed jobs")
+
+	// Nach dem Neustart wird die Warteschlange aus den gespeicherten Jobs wiederhergestellt
+	extract := processor.Func(func(ctx context.Context, job models.Job) (models.Job, error) {
+		job.Title = job.Description
+		return job, nil
+	})
+	service := NewScraperService(store, extract)
+	restored, err := service.RestoreQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	result, err = service.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ProcessedJobs)
+	assert.Equal(t, 0, service.QueueLength())
+	assert.Empty(t, store.queued)
+	assert.Equal(t, "Go Developer", store.jobs["https://example.com/1"].Title)
+}
+
+// racingScraper speichert den ersten Job selbst, wie ein paralleler Lauf nach dem Laden der URLs
+type racingScraper struct {
+	fakeScraper
+	store *memory.Store
+}
+
+func (r racingScraper) Scrape(ctx context.Context) ([]models.Job, error) {
+	job := rawJob(r.jobs[0].URL, "Go Developer")
+	job.Title = "Go Developer"
+	if err := r.store.SaveJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return r.jobs, nil
+}
+
+func TestDuplicatesOfJobStoredByParallelRun(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewStore("")
+	require.NoError(t, err)
+	service := NewScraperService(store, firstLineProcessor)
+	service.SetDuplicateDetection(DuplicateDetection{Threshold: 0.9, Window: 24 * time.Hour})
+
+	scraper := racingScraper{store: store, fakeScraper: fakeScraper{jobs: []models.Job{
+		rawJob("https://example.com/1", "Go Developer\nZürich, Fintech"),
+		rawJob("https://other.example.com/1", "Go Developer\nZürich, Fintech"),
+	}}}
+	result, err := service.ExecuteScraping(ctx, scraper, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ProcessedJobs)
+
+	original, err := store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	duplicate, err := store.GetJobByURL(ctx, "https://other.example.com/1")
+	require.NoError(t, err)
+	require.NotNil(t, duplicate.DuplicateOf)
+	assert.Equal(t, original.ID, *duplicate.DuplicateOf)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"job-scraper/internal/skills"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// UnmappedSkill is a skill that is not part of the taxonomy yet
+type UnmappedSkill struct {
+	Name string `json:"name"`
+	Jobs int    `json:"jobs"`
+}
+
+// CanonicalizeResult fasst einen Lauf der Skill-Kanonisierung zusammen
+type CanonicalizeResult struct {
+	TaxonomyVersion string `json:"taxonomyVersion"`
+	DryRun          bool   `json:"dryRun"`
+	Total           int    `json:"total"`
+	Changed         int    `json:"changed"`
+	Failed          int    `json:"failed"`
+}
+
+// SkillService applies the skill taxonomy to stored jobs
+type SkillService struct {
+	storage  storage.Storage
+	taxonomy *skills.Taxonomy
+}
+
+func NewSkillService(storage storage.Storage, taxonomy *skills.Taxonomy) *SkillService {
+	return &SkillService{
+		storage:  storage,
+		taxonomy: taxonomy,
+	}
+}
+
+// Taxonomy returns the loaded skill taxonomy
+func (s *SkillService) Taxonomy() *skills.Taxonomy {
+	return s.taxonomy
+}
+
+// Canonicalize maps the skills of all stored jobs to their canonical names.
+// Jobs that were canonicalized with the current taxonomy version are skipped.
+// With dryRun the changed jobs are only counted.
+func (s *SkillService) Canonicalize(ctx context.Context, dryRun bool) (CanonicalizeResult, error) {
+	result := CanonicalizeResult{TaxonomyVersion: s.taxonomy.Version(), DryRun: dryRun}
+
+	jobs, err := s.storage.FindJobs(ctx, storage.JobFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if job.SkillTaxonomy == s.taxonomy.Version() {
+			continue
+		}
+		result.Total++
+
+		mustSkills, _ := s.taxonomy.Canonicalize(job.MustSkills)
+		optionalSkills, _ := s.taxonomy.Canonicalize(job.OptionalSkills)
+		optionalSkills = slices.DeleteFunc(optionalSkills, func(skill string) bool {
+			return slices.ContainsFunc(mustSkills, func(must string) bool { return skills.Key(must) == skills.Key(skill) })
+		})
+
+		if !slices.Equal(mustSkills, job.MustSkills) || !slices.Equal(optionalSkills, job.OptionalSkills) {
+			result.Changed++
+		}
+		if dryRun {
+			continue
+		}
+
+		job.MustSkills = mustSkills
+		job.OptionalSkills = optionalSkills
+		job.SkillTaxonomy = s.taxonomy.Version()
+		if err := s.storage.UpdateJob(ctx, job); err != nil {
+			result.Failed++
+			log.Error().Err(err).Str("job_url", job.URL).Msg("Failed to update canonicalized skills")
+		}
+	}
+
+	log.Info().
+		Str("taxonomy_version", result.TaxonomyVersion).
+		Bool("dry_run", dryRun).
+		Int("total", result.Total).
+		Int("changed", result.Changed).
+		Int("failed", result.Failed).
+		Msg("Skill canonicalization finished")
+
+	return result, nil
+}
+
+// UnmappedSkills returns the stored skills that are missing in the taxonomy,
+// ordered by the number of jobs that mention them. A limit <= 0 returns all.
+func (s *SkillService) UnmappedSkills(ctx context.Context, limit int) ([]UnmappedSkill, error) {
+	counts, err := s.storage.GetSkillCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schreibweisen, die nur im Gross-/Kleinschreiben abweichen, zusammenfassen
+	byKey := make(map[string]*UnmappedSkill)
+	for name, count := range counts {
+		if _, ok := s.taxonomy.Lookup(name); ok || name == "" {
+			continue
+		}
+		key := skills.Key(name)
+		if existing, ok := byKey[key]; ok {
+			existing.Jobs += count
+			continue
+		}
+		byKey[key] = &UnmappedSkill{Name: name, Jobs: count}
+	}
+
+	unmapped := make([]UnmappedSkill, 0, len(byKey))
+	for _, skill := range byKey {
+		unmapped = append(unmapped, *skill)
+	}
+	sort.Slice(unmapped, func(i, j int) bool {
+		if unmapped[i].Jobs != unmapped[j].Jobs {
+			return unmapped[i].Jobs > unmapped[j].Jobs
+		}
+		return unmapped[i].Name < unmapped[j].Name
+	})
+
+	if limit > 0 && len(unmapped) > limit {
+		unmapped = unmapped[:limit]
+	}
+	return unmapped, nil
+}
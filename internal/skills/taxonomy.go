@@ -0,0 +1,177 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Skill categories used in the taxonomy file
+const (
+	CategoryLanguage  = "language"
+	CategoryFramework = "framework"
+	CategoryDatabase  = "database"
+	CategoryCloud     = "cloud"
+	CategoryTool      = "tool"
+	CategorySoftSkill = "soft skill"
+)
+
+var validCategories = map[string]bool{
+	CategoryLanguage:  true,
+	CategoryFramework: true,
+	CategoryDatabase:  true,
+	CategoryCloud:     true,
+	CategoryTool:      true,
+	CategorySoftSkill: true,
+}
+
+// Skill is a canonical skill of the taxonomy
+type Skill struct {
+	Name     string   `yaml:"name" json:"name"`
+	Category string   `yaml:"category" json:"category"`
+	Aliases  []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+}
+
+// Taxonomy maps the free-form skill names produced by the extraction to canonical skills
+type Taxonomy struct {
+	version string
+	skills  []Skill
+	byKey   map[string]*Skill
+}
+
+type taxonomyFile struct {
+	Version string  `yaml:"version"`
+	Skills  []Skill `yaml:"skills"`
+}
+
+// DefaultPath returns the location of the taxonomy file. It can be overridden
+// with JOBSCRAPER_TAXONOMY_PATH, otherwise the file in the source tree is used.
+func DefaultPath() string {
+	if envPath := os.Getenv("JOBSCRAPER_TAXONOMY_PATH"); envPath != "" {
+		return envPath
+	}
+
+	// Fallback
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		panic("No caller information")
+	}
+	return filepath.Join(filepath.Dir(filename), "..", "..", "taxonomy", "skills.yaml")
+}
+
+// Load reads the taxonomy from a YAML (or JSON) file
+func Load(path string) (*Taxonomy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading taxonomy file: %w", err)
+	}
+	return Parse(content)
+}
+
+// Parse parses a taxonomy document. Every name and alias must map to exactly one skill.
+func Parse(content []byte) (*Taxonomy, error) {
+	var file taxonomyFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("error parsing taxonomy: %w", err)
+	}
+	if file.Version == "" {
+		return nil, fmt.Errorf("taxonomy has no version")
+	}
+
+	t := &Taxonomy{
+		version: file.Version,
+		skills:  file.Skills,
+		byKey:   make(map[string]*Skill),
+	}
+	for i := range t.skills {
+		skill := &t.skills[i]
+		if skill.Name == "" {
+			return nil, fmt.Errorf("taxonomy skill %d has no name", i)
+		}
+		if !validCategories[skill.Category] {
+			return nil, fmt.Errorf("skill %s has invalid category: %q", skill.Name, skill.Category)
+		}
+
+		for _, name := range append([]string{skill.Name}, skill.Aliases...) {
+			key := Key(name)
+			if other, exists := t.byKey[key]; exists && other != skill {
+				return nil, fmt.Errorf("alias %q of skill %s is already used by %s", name, skill.Name, other.Name)
+			}
+			t.byKey[key] = skill
+		}
+	}
+
+	return t, nil
+}
+
+// Key returns the lookup key of a skill name. Case, whitespace, dots, hyphens
+// and underscores are ignored, so "Node.js", "nodejs" and "NODE JS" are equal.
+// Symbols like "#" and "+" are kept to distinguish C, C# and C++.
+func Key(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch r {
+		case ' ', '\t', '\n', '.', '-', '_':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Version returns the version of the taxonomy file
+func (t *Taxonomy) Version() string {
+	return t.version
+}
+
+// Skills returns all canonical skills sorted by name
+func (t *Taxonomy) Skills() []Skill {
+	result := make([]Skill, len(t.skills))
+	copy(result, t.skills)
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Lookup returns the canonical skill for a name or alias
+func (t *Taxonomy) Lookup(name string) (Skill, bool) {
+	skill, ok := t.byKey[Key(name)]
+	if !ok {
+		return Skill{}, false
+	}
+	return *skill, true
+}
+
+// Canonicalize maps the skills to their canonical names and removes duplicates.
+// Unknown skills are kept with trimmed whitespace and returned as unmapped.
+func (t *Taxonomy) Canonicalize(skills []string) (canonical []string, unmapped []string) {
+	seen := make(map[string]bool, len(skills))
+	canonical = make([]string, 0, len(skills))
+
+	for _, name := range skills {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" {
+			continue
+		}
+		skill, mapped := t.Lookup(name)
+		if mapped {
+			name = skill.Name
+		}
+
+		key := Key(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		canonical = append(canonical, name)
+		if !mapped {
+			unmapped = append(unmapped, name)
+		}
+	}
+
+	return canonical, unmapped
+}
@@ -0,0 +1,65 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("Node.js"), Key("NODE JS"))
+	assert.Equal(t, Key("ci-cd"), Key("CI_CD"))
+	assert.NotEqual(t, Key("C"), Key("C#"))
+	assert.NotEqual(t, Key("C#"), Key("C++"))
+}
+
+func TestParseRejectsDuplicateAliases(t *testing.T) {
+	_, err := Parse([]byte(`
+version: "1"
+skills:
+  - name: Go
+    category: language
+  - name: Golang
+    category: language
+    aliases: [go]
+`))
+	assert.Error(t, err)
+}
+
+func TestParseRejectsUnknownCategory(t *testing.T) {
+	_, err := Parse([]byte(`
+version: "1"
+skills:
+  - name: Go
+    category: programming
+`))
+	assert.Error(t, err)
+}
+
+func TestCanonicalize(t *testing.T) {
+	taxonomy, err := Parse([]byte(`
+version: "1"
+skills:
+  - name: Go
+    category: language
+    aliases: [golang, go lang]
+`))
+	require.NoError(t, err)
+
+	canonical, unmapped := taxonomy.Canonicalize([]string{"Golang", "go", "go lang", " Fortran ", "fortran"})
+
+	assert.Equal(t, []string{"Go", "Fortran"}, canonical)
+	assert.Equal(t, []string{"Fortran"}, unmapped)
+}
+
+// Die ausgelieferte Taxonomie muss sich laden lassen
+func TestDefaultTaxonomy(t *testing.T) {
+	taxonomy, err := Load(DefaultPath())
+	require.NoError(t, err)
+
+	skill, ok := taxonomy.Lookup("golang")
+	assert.True(t, ok)
+	assert.Equal(t, "Go", skill.Name)
+	assert.Equal(t, CategoryLanguage, skill.Category)
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "time"
+
+// JobFilter restricts the jobs returned by FindJobs. Zero values are ignored.
+type JobFilter struct {
+	PostedFrom       time.Time
+	PostedTo         time.Time
+	Category         string
+	PromptVersion    string
+	ExtractionMethod string
+	// PostingLanguage ist der ISO-639-1-Code der Sprache des Inserats
+	PostingLanguage string
+	// OriginalsOnly leaves out jobs linked to an earlier near-duplicate posting
+	OriginalsOnly bool
+	// OpenOnly leaves out jobs that have been closed, see models.Job.ClosedAt
+	OpenOnly bool
+	// ClosedOnly keeps the jobs that have been closed
+	ClosedOnly bool
+}
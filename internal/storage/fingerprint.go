@@ -0,0 +1,9 @@
+package storage
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// JobFingerprint is the SimHash fingerprint of a stored original job
+type JobFingerprint struct {
+	ID          primitive.ObjectID
+	Fingerprint string
+}
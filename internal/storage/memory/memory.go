@@ -0,0 +1,460 @@
+// Package memory keeps jobs in memory. It needs no database and is meant for tests
+// and the demo mode. A JSON snapshot file can keep the data across restarts.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// storedJob ist ein Job mit dem Zeitpunkt, zu dem er zuerst gespeichert wurde
+type storedJob struct {
+	Job       models.Job `json:"job"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// snapshot is the content of the snapshot file
+type snapshot struct {
+	Jobs       []snapshotJob       `json:"jobs"`
+	Versions   []snapshotVersion   `json:"versions"`
+	FailedJobs []models.FailedJob  `json:"failedJobs"`
+	QueuedJobs []snapshotQueuedJob `json:"queuedJobs"`
+}
+
+// snapshotJob, snapshotVersion and snapshotQueuedJob write the raw source next to
+// the job, as it is not part of the JSON of a job that the API returns
+type snapshotJob struct {
+	storedJob
+	Source *models.SourcePayload `json:"source,omitempty"`
+}
+
+type snapshotVersion struct {
+	models.JobVersion
+	Source *models.SourcePayload `json:"source,omitempty"`
+}
+
+type snapshotQueuedJob struct {
+	models.QueuedJob
+	Source *models.SourcePayload `json:"source,omitempty"`
+}
+
+// Store implements storage.Storage with maps and slices guarded by a mutex. Jobs
+// are copied on the way in and out, so callers cannot change stored jobs.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+
+	jobs       []storedJob // in the order they were stored
+	byID       map[primitive.ObjectID]int
+	byURL      map[string]int
+	versions   map[primitive.ObjectID][]models.JobVersion // oldest first
+	failedJobs map[string]models.FailedJob
+	queuedJobs map[string]models.QueuedJob
+}
+
+// NewStore returns an empty store. If a snapshot path is given, the store is loaded
+// from the file if it exists and written back to it by Close and SaveSnapshot.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:       path,
+		byID:       make(map[primitive.ObjectID]int),
+		byURL:      make(map[string]int),
+		versions:   make(map[primitive.ObjectID][]models.JobVersion),
+		failedJobs: make(map[string]models.FailedJob),
+		queuedJobs: make(map[string]models.QueuedJob),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var saved snapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+
+	for _, stored := range saved.Jobs {
+		stored.Job.Source = stored.Source
+		s.add(stored.Job, stored.CreatedAt)
+	}
+	for _, entry := range saved.Versions {
+		version := entry.JobVersion
+		version.Job.Source = entry.Source
+		s.versions[version.JobID] = append(s.versions[version.JobID], version)
+	}
+	for _, failedJob := range saved.FailedJobs {
+		s.failedJobs[failedJob.URL] = failedJob
+	}
+	for _, entry := range saved.QueuedJobs {
+		queuedJob := entry.QueuedJob
+		queuedJob.Job.Source = entry.Source
+		s.queuedJobs[queuedJob.URL] = queuedJob
+	}
+	return s, nil
+}
+
+func (s *Store) GetJobs(ctx context.Context) ([]models.Job, error) {
+	return s.filterJobs(func(models.Job) bool { return true }), nil
+}
+
+func (s *Store) GetJobByID(ctx context.Context, id string) (*models.Job, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("Job", id)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.byID[objectID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Job", id)
+	}
+	job := cloneJob(s.jobs[i].Job)
+	return &job, nil
+}
+
+// GetJobByURL returns the job stored for a posting URL
+func (s *Store) GetJobByURL(ctx context.Context, url string) (*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.byURL[url]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Job", url)
+	}
+	job := cloneJob(s.jobs[i].Job)
+	return &job, nil
+}
+
+// GetJobVersions returns the prior versions of a job, newest first
+func (s *Store) GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error) {
+	objectID, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("Job", jobID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.versions[objectID]
+	versions := make([]models.JobVersion, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		version := stored[i]
+		version.Job = cloneJob(version.Job)
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func (s *Store) FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	return s.filterJobs(func(job models.Job) bool { return matchesFilter(job, filter) }), nil
+}
+
+// SaveJob inserts a job or, if a job with the same URL is already stored, replaces it.
+// Prior versions of changed postings are kept.
+func (s *Store) SaveJob(ctx context.Context, job models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, exists := s.byURL[job.URL]
+	if !exists {
+		if job.ID.IsZero() {
+			job.ID = primitive.NewObjectID()
+		}
+		s.add(job, time.Now())
+		return nil
+	}
+
+	existing := s.jobs[i].Job
+	job.ID = existing.ID
+	// Ein Repost derselben URL ist kein Duplikat von sich selbst
+	if job.DuplicateOf != nil && *job.DuplicateOf == existing.ID {
+		job.DuplicateOf = nil
+	}
+
+	changes, err := storage.DiffJobs(existing, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to compare job versions", err)
+	}
+	// Ohne neue Version werden nur Felder wie lastSeenAt, duplicateOf und source übernommen
+	if len(changes) > 0 {
+		s.versions[existing.ID] = append(s.versions[existing.ID], models.JobVersion{
+			ID:         primitive.NewObjectID(),
+			JobID:      existing.ID,
+			URL:        existing.URL,
+			Version:    len(s.versions[existing.ID]) + 1,
+			ReplacedAt: time.Now(),
+			Changes:    changes,
+			Job:        existing,
+		})
+	}
+	s.jobs[i].Job = cloneJob(job)
+	return nil
+}
+
+func (s *Store) UpdateJob(ctx context.Context, job models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.byID[job.ID]
+	if !ok {
+		return apperrors.NewNotFoundError("Job", job.ID.Hex())
+	}
+	if previous := s.jobs[i].Job.URL; previous != job.URL {
+		delete(s.byURL, previous)
+		s.byURL[job.URL] = i
+	}
+	s.jobs[i].Job = cloneJob(job)
+	return nil
+}
+
+func (s *Store) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if previous, ok := s.failedJobs[job.URL]; ok {
+		job.Attempts = previous.Attempts + 1
+		job.FirstFailedAt = previous.FirstFailedAt
+	} else {
+		job.Attempts = 1
+		job.FirstFailedAt = now
+	}
+	job.LastFailedAt = now
+	s.failedJobs[job.URL] = job
+	return nil
+}
+
+func (s *Store) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]models.FailedJob, 0, len(s.failedJobs))
+	for _, job := range s.failedJobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].FirstFailedAt.Before(jobs[j].FirstFailedAt) })
+	return jobs, nil
+}
+
+func (s *Store) DeleteFailedJob(ctx context.Context, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failedJobs, url)
+	return nil
+}
+
+// QueueJob stores a job waiting for the LLM budget. A job queued again keeps its queue time.
+func (s *Store) QueueJob(ctx context.Context, job models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queuedAt := time.Now()
+	if previous, ok := s.queuedJobs[job.URL]; ok {
+		queuedAt = previous.QueuedAt
+	}
+	s.queuedJobs[job.URL] = models.QueuedJob{URL: job.URL, Job: cloneJob(job), QueuedAt: queuedAt}
+	return nil
+}
+
+func (s *Store) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]models.QueuedJob, 0, len(s.queuedJobs))
+	for _, queuedJob := range s.queuedJobs {
+		queuedJob.Job = cloneJob(queuedJob.Job)
+		jobs = append(jobs, queuedJob)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].QueuedAt.Before(jobs[j].QueuedAt) })
+	return jobs, nil
+}
+
+func (s *Store) DeleteQueuedJob(ctx context.Context, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.queuedJobs, url)
+	return nil
+}
+
+func (s *Store) GetJobCountByCategory(ctx context.Context) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, stored := range s.jobs {
+		for _, category := range stored.Job.JobCategories {
+			counts[category]++
+		}
+	}
+	return counts, nil
+}
+
+// GetSkillCounts returns the number of jobs per skill, required and optional skills combined
+func (s *Store) GetSkillCounts(ctx context.Context) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, stored := range s.jobs {
+		seen := make(map[string]bool)
+		for _, skill := range append(slices.Clone(stored.Job.MustSkills), stored.Job.OptionalSkills...) {
+			if !seen[skill] {
+				seen[skill] = true
+				counts[skill]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (s *Store) GetTotalJobCount(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.jobs), nil
+}
+
+func (s *Store) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	urls := make(map[string]bool, len(s.byURL))
+	for url := range s.byURL {
+		urls[url] = true
+	}
+	return urls, nil
+}
+
+// GetFingerprints returns the fingerprints of the original jobs stored since the given time.
+// Jobs marked as duplicates are left out, so new duplicates link to the original.
+func (s *Store) GetFingerprints(ctx context.Context, since time.Time) ([]storage.JobFingerprint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fingerprints := []storage.JobFingerprint{}
+	for _, stored := range s.jobs {
+		job := stored.Job
+		if job.Fingerprint == "" || job.DuplicateOf != nil || stored.CreatedAt.Before(since) {
+			continue
+		}
+		fingerprints = append(fingerprints, storage.JobFingerprint{ID: job.ID, Fingerprint: job.Fingerprint})
+	}
+	return fingerprints, nil
+}
+
+// Close writes the snapshot, if the store has a snapshot path
+func (s *Store) Close(ctx context.Context) error {
+	return s.SaveSnapshot()
+}
+
+// SaveSnapshot writes all jobs, versions, failed and queued jobs to the snapshot path. The
+// file is replaced at once, so a crash while writing keeps the previous snapshot.
+func (s *Store) SaveSnapshot() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	saved := snapshot{
+		Jobs:       make([]snapshotJob, 0, len(s.jobs)),
+		Versions:   []snapshotVersion{},
+		FailedJobs: make([]models.FailedJob, 0, len(s.failedJobs)),
+		QueuedJobs: make([]snapshotQueuedJob, 0, len(s.queuedJobs)),
+	}
+	for _, stored := range s.jobs {
+		saved.Jobs = append(saved.Jobs, snapshotJob{storedJob: stored, Source: stored.Job.Source})
+		for _, version := range s.versions[stored.Job.ID] {
+			saved.Versions = append(saved.Versions, snapshotVersion{JobVersion: version, Source: version.Job.Source})
+		}
+	}
+	for _, failedJob := range s.failedJobs {
+		saved.FailedJobs = append(saved.FailedJobs, failedJob)
+	}
+	for _, queuedJob := range s.queuedJobs {
+		saved.QueuedJobs = append(saved.QueuedJobs, snapshotQueuedJob{QueuedJob: queuedJob, Source: queuedJob.Job.Source})
+	}
+	data, err := json.Marshal(saved)
+	s.mu.RUnlock()
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode snapshot", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to create snapshot directory", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to write snapshot", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to write snapshot", err)
+	}
+	return nil
+}
+
+// add appends a new job and indexes it. The caller holds the write lock.
+func (s *Store) add(job models.Job, createdAt time.Time) {
+	s.byID[job.ID] = len(s.jobs)
+	s.byURL[job.URL] = len(s.jobs)
+	s.jobs = append(s.jobs, storedJob{Job: cloneJob(job), CreatedAt: createdAt})
+}
+
+// filterJobs returns copies of the matching jobs in the order they were stored
+func (s *Store) filterJobs(match func(models.Job) bool) []models.Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := []models.Job{}
+	for _, stored := range s.jobs {
+		if match(stored.Job) {
+			jobs = append(jobs, cloneJob(stored.Job))
+		}
+	}
+	return jobs
+}
+
+// cloneJob copies the lists and referenced values of a job
+func cloneJob(job models.Job) models.Job {
+	job.Locations = slices.Clone(job.Locations)
+	job.JobCategories = slices.Clone(job.JobCategories)
+	job.MustSkills = slices.Clone(job.MustSkills)
+	job.OptionalSkills = slices.Clone(job.OptionalSkills)
+	job.Benefits = slices.Clone(job.Benefits)
+	job.Languages = slices.Clone(job.Languages)
+	if job.Salary != nil {
+		salary := *job.Salary
+		job.Salary = &salary
+	}
+	if job.DuplicateOf != nil {
+		original := *job.DuplicateOf
+		job.DuplicateOf = &original
+	}
+	if job.Source != nil {
+		source := *job.Source
+		job.Source = &source
+	}
+	return job
+}
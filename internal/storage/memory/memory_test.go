@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	store, err := NewStore("")
+	require.NoError(t, err)
+	return store
+}
+
+func testJob(url, title string, postingDate time.Time) models.Job {
+	return models.Job{
+		URL:            url,
+		Title:          title,
+		Description:    "Wir suchen Verstärkung für unser Team",
+		Company:        "Example AG",
+		Location:       "Zürich",
+		Locations:      []models.Place{{City: "Zürich", Canton: "ZH", Country: "CH"}},
+		EmploymentType: "Full-time",
+		PostingDate:    postingDate,
+		JobCategories:  []string{"SOFTWARE ENGINEER"},
+		MustSkills:     []string{"Go"},
+		OptionalSkills: []string{"Kubernetes"},
+	}
+}
+
+func TestSaveJobKeepsVersions(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.SaveJob(ctx, testJob("https://example.com/1", "Go Developer", postingDate)))
+	stored, err := store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.False(t, stored.ID.IsZero())
+
+	// Gelesene Jobs sind Kopien
+	stored.MustSkills[0] = "Rust"
+	stored, err = store.GetJobByID(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go"}, stored.MustSkills)
+
+	// Derselbe Inhalt erzeugt keine Version, unversionierte Felder werden trotzdem gespeichert
+	seenAt := time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)
+	seen := testJob("https://example.com/1", "Go Developer", postingDate)
+	seen.LastSeenAt = &seenAt
+	require.NoError(t, store.SaveJob(ctx, seen))
+	versions, err := store.GetJobVersions(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+	stored, err = store.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastSeenAt)
+	assert.True(t, stored.LastSeenAt.Equal(seenAt))
+
+	require.NoError(t, store.SaveJob(ctx, testJob("https://example.com/1", "Senior Go Developer", postingDate)))
+	require.NoError(t, store.SaveJob(ctx, testJob("https://example.com/1", "Lead Go Developer", postingDate)))
+	versions, err = store.GetJobVersions(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, "Senior Go Developer", versions[0].Job.Title)
+	require.Len(t, versions[1].Changes, 1)
+	assert.Equal(t, "title", versions[1].Changes[0].Field)
+
+	total, err := store.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	_, err = store.GetJobByID(ctx, "not an id")
+	assert.Error(t, err)
+}
+
+func TestQueryJobs(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, title := range []string{"Go Developer", "Platform Engineer", "Backend Engineer"} {
+		job := testJob("https://example.com/"+title, title, postingDate.AddDate(0, 0, i))
+		if i == 1 {
+			job.MustSkills = []string{"Rust"}
+			job.Remote = true
+		}
+		require.NoError(t, store.SaveJob(ctx, job))
+	}
+
+	page, err := store.QueryJobs(ctx, storage.JobQuery{Skill: "go", SkillType: storage.SkillTypeMust})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	remote := true
+	page, err = store.QueryJobs(ctx, storage.JobQuery{Remote: &remote, Location: "zh"})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, "Platform Engineer", page.Jobs[0].Title)
+
+	// Seiten setzen nach dem letzten Job der vorherigen Seite fort
+	var titles []string
+	query := storage.JobQuery{SortDescending: true, Limit: 2}
+	for {
+		page, err := store.QueryJobs(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, 3, page.Total)
+		for _, job := range page.Jobs {
+			titles = append(titles, job.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"Backend Engineer", "Platform Engineer", "Go Developer"}, titles)
+
+	_, err = store.QueryJobs(ctx, storage.JobQuery{SortBy: storage.SortTitle, Cursor: query.Cursor})
+	assert.Error(t, err)
+}
+
+func TestSearchJobs(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	goJob := testJob("https://example.com/go", "Go Developer", postingDate)
+	rustJob := testJob("https://example.com/rust", "Rust Developer", postingDate)
+	rustJob.Description = "Rust und etwas Go"
+	rustJob.MustSkills = []string{"Rust"}
+	require.NoError(t, store.SaveJob(ctx, goJob))
+	require.NoError(t, store.SaveJob(ctx, rustJob))
+
+	page, err := store.SearchJobs(ctx, storage.JobSearch{Text: "go"})
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	// Treffer im Titel wiegen schwerer als in der Beschreibung
+	assert.Equal(t, "Go Developer", page.Results[0].Job.Title)
+	assert.Greater(t, page.Results[0].Score, page.Results[1].Score)
+
+	page, err = store.SearchJobs(ctx, storage.JobSearch{Text: "developer -rust"})
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "Go Developer", page.Results[0].Job.Title)
+
+	page, err = store.SearchJobs(ctx, storage.JobSearch{Text: `"etwas go"`})
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "Rust Developer", page.Results[0].Job.Title)
+
+	page, err = store.SearchJobs(ctx, storage.JobSearch{Text: "zurich", JobQuery: storage.JobQuery{Limit: 1}})
+	require.NoError(t, err)
+	assert.Len(t, page.Results, 1)
+	assert.Equal(t, 2, page.Total)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = store.SearchJobs(ctx, storage.JobSearch{Text: "-rust"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Results)
+}
+
+func TestFailedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	failed := models.FailedJob{URL: "https://example.com/1", Error: "timeout"}
+	require.NoError(t, store.SaveFailedJob(ctx, failed))
+	require.NoError(t, store.SaveFailedJob(ctx, failed))
+
+	jobs, err := store.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 2, jobs[0].Attempts)
+
+	require.NoError(t, store.DeleteFailedJob(ctx, failed.URL))
+	jobs, err = store.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot", "jobs.json")
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := NewStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveJob(ctx, testJob("https://example.com/1", "Go Developer", postingDate)))
+	changed := testJob("https://example.com/1", "Senior Go Developer", postingDate)
+	changed.Source = &models.SourcePayload{RawPayload: "<h1>Senior Go Developer</h1>", ContentType: "text/html"}
+	require.NoError(t, store.SaveJob(ctx, changed))
+	require.NoError(t, store.SaveFailedJob(ctx, models.FailedJob{URL: "https://example.com/2", Error: "timeout"}))
+	queuedJob := testJob("https://example.com/3", "Data Engineer", postingDate)
+	queuedJob.Source = &models.SourcePayload{RawPayload: "<p>Data Engineer</p>", ContentType: "text/html"}
+	require.NoError(t, store.QueueJob(ctx, queuedJob))
+	require.NoError(t, store.Close(ctx))
+
+	restored, err := NewStore(path)
+	require.NoError(t, err)
+	job, err := restored.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.Equal(t, "Senior Go Developer", job.Title)
+	assert.True(t, job.PostingDate.Equal(postingDate))
+	require.NotNil(t, job.Source)
+	assert.Equal(t, "<h1>Senior Go Developer</h1>", job.Source.RawPayload)
+
+	versions, err := restored.GetJobVersions(ctx, job.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "Go Developer", versions[0].Job.Title)
+
+	failed, err := restored.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, failed, 1)
+
+	queued, err := restored.GetQueuedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "Data Engineer", queued[0].Job.Title)
+	require.NotNil(t, queued[0].Job.Source)
+	assert.Equal(t, "<p>Data Engineer</p>", queued[0].Job.Source.RawPayload)
+
+	fingerprints, err := restored.GetFingerprints(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, fingerprints)
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.SaveJob(ctx, testJob("https://example.com/1", "Go Developer", postingDate)))
+			_, err := store.QueryJobs(ctx, storage.JobQuery{Skill: "go"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	total, err := store.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
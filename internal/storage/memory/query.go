@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor ist die Position nach dem letzten Job einer Seite
+type pageCursor struct {
+	SortBy string      `json:"s"`
+	Value  interface{} `json:"v"`
+	ID     string      `json:"id"`
+}
+
+// QueryJobs returns a page of the jobs matching the query. Pages are continued with
+// the cursor of the previous page, which stays stable while new jobs are stored.
+func (s *Store) QueryJobs(ctx context.Context, query storage.JobQuery) (storage.JobPage, error) {
+	page := storage.JobPage{Jobs: []models.Job{}}
+
+	sortBy, err := query.SortField()
+	if err != nil {
+		return page, err
+	}
+	var after *models.Job
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, sortBy)
+		if err != nil {
+			return page, err
+		}
+		after = &cursor
+	}
+
+	jobs := s.filterJobs(func(job models.Job) bool { return matchesQuery(job, query) })
+	page.Total = len(jobs)
+
+	compare := func(a, b models.Job) int {
+		// Jobs mit gleichem Sortierwert sind nach ID geordnet
+		c := cmp.Or(compareField(a, b, sortBy), strings.Compare(a.ID.Hex(), b.ID.Hex()))
+		if query.SortDescending {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(jobs, compare)
+	if after != nil {
+		start := sort.Search(len(jobs), func(i int) bool { return compare(jobs[i], *after) > 0 })
+		jobs = jobs[start:]
+	}
+
+	limit := query.PageSize()
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+		if page.NextCursor, err = encodeCursor(jobs[limit-1], sortBy); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	page.Jobs = jobs
+	return page, nil
+}
+
+// compareField compares two jobs by a sort field of storage.JobQuery
+func compareField(a, b models.Job, sortBy string) int {
+	switch sortBy {
+	case storage.SortTitle:
+		return strings.Compare(a.Title, b.Title)
+	case storage.SortCompany:
+		return strings.Compare(a.Company, b.Company)
+	case storage.SortYearsOfExperience:
+		return cmp.Compare(a.YearsOfExperience, b.YearsOfExperience)
+	default:
+		return a.PostingDate.Compare(b.PostingDate)
+	}
+}
+
+// matchesFilter reports whether a job matches a storage.JobFilter
+func matchesFilter(job models.Job, filter storage.JobFilter) bool {
+	switch {
+	case !filter.PostedFrom.IsZero() && job.PostingDate.Before(filter.PostedFrom):
+		return false
+	case !filter.PostedTo.IsZero() && !job.PostingDate.Before(filter.PostedTo):
+		return false
+	case filter.Category != "" && !slices.Contains(job.JobCategories, filter.Category):
+		return false
+	case filter.PromptVersion != "" && job.PromptVersion != filter.PromptVersion:
+		return false
+	case filter.ExtractionMethod != "" && job.ExtractionMethod != filter.ExtractionMethod:
+		return false
+	case filter.PostingLanguage != "" && job.PostingLanguage != filter.PostingLanguage:
+		return false
+	case filter.OriginalsOnly && job.DuplicateOf != nil:
+		return false
+	case filter.OpenOnly && job.ClosedAt != nil:
+		return false
+	case filter.ClosedOnly && job.ClosedAt == nil:
+		return false
+	}
+	return true
+}
+
+// matchesQuery reports whether a job matches the filter fields of a storage.JobQuery.
+// Like in MongoDB, text is compared ignoring case.
+func matchesQuery(job models.Job, query storage.JobQuery) bool {
+	if query.Category != "" && !slices.Contains(job.JobCategories, strings.ToUpper(strings.TrimSpace(query.Category))) {
+		return false
+	}
+	if query.Skill != "" {
+		skill := strings.TrimSpace(query.Skill)
+		mustSkill := containsFold(job.MustSkills, skill)
+		optionalSkill := containsFold(job.OptionalSkills, skill)
+		switch query.SkillType {
+		case storage.SkillTypeMust:
+			optionalSkill = false
+		case storage.SkillTypeOptional:
+			mustSkill = false
+		}
+		if !mustSkill && !optionalSkill {
+			return false
+		}
+	}
+	if query.Company != "" && !strings.Contains(strings.ToLower(job.Company), strings.ToLower(strings.TrimSpace(query.Company))) {
+		return false
+	}
+	if query.Location != "" && !matchesLocation(job, strings.TrimSpace(query.Location)) {
+		return false
+	}
+	if query.Remote != nil && job.Remote != *query.Remote {
+		return false
+	}
+	if query.EmploymentType != "" && !strings.EqualFold(job.EmploymentType, strings.TrimSpace(query.EmploymentType)) {
+		return false
+	}
+	if !query.PostedFrom.IsZero() && job.PostingDate.Before(query.PostedFrom) {
+		return false
+	}
+	if !query.PostedTo.IsZero() && !job.PostingDate.Before(query.PostedTo) {
+		return false
+	}
+	return job.YearsOfExperience >= query.MinExperience
+}
+
+// matchesLocation matches the location text, the resolved city or the canton
+func matchesLocation(job models.Job, location string) bool {
+	if strings.Contains(strings.ToLower(job.Location), strings.ToLower(location)) {
+		return true
+	}
+	for _, place := range job.Locations {
+		if strings.EqualFold(place.City, location) || place.Canton == strings.ToUpper(location) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
+
+func encodeCursor(job models.Job, sortBy string) (string, error) {
+	cursor := pageCursor{SortBy: sortBy, ID: job.ID.Hex()}
+	switch sortBy {
+	case storage.SortPostingDate:
+		cursor.Value = job.PostingDate.UTC().Format(time.RFC3339Nano)
+	case storage.SortTitle:
+		cursor.Value = job.Title
+	case storage.SortCompany:
+		cursor.Value = job.Company
+	case storage.SortYearsOfExperience:
+		cursor.Value = job.YearsOfExperience
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor returns a job with the ID and sort field of the cursor, which sorts
+// like the last job of the previous page
+func decodeCursor(value, sortBy string) (models.Job, error) {
+	var job models.Job
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return job, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	if cursor.SortBy != sortBy {
+		return job, apperrors.NewBaseError(apperrors.ErrCodeValidation, "page cursor belongs to a different sort order", nil)
+	}
+
+	invalid := apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", nil)
+	if job.ID, err = primitive.ObjectIDFromHex(cursor.ID); err != nil {
+		return job, invalid
+	}
+	var ok bool
+	switch sortBy {
+	case storage.SortPostingDate:
+		text, _ := cursor.Value.(string)
+		job.PostingDate, err = time.Parse(time.RFC3339Nano, text)
+		ok = err == nil
+	case storage.SortTitle:
+		job.Title, ok = cursor.Value.(string)
+	case storage.SortCompany:
+		job.Company, ok = cursor.Value.(string)
+	case storage.SortYearsOfExperience:
+		var number float64
+		number, ok = cursor.Value.(float64)
+		job.YearsOfExperience = int(number)
+	}
+	if !ok {
+		return job, invalid
+	}
+	return job, nil
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/search"
+	"job-scraper/internal/storage"
+)
+
+// searchField ist ein durchsuchtes Feld, gewichtet wie im MongoDB-Textindex
+type searchField struct {
+	weight float64
+	text   func(job models.Job) string
+}
+
+var searchFields = []searchField{
+	{10, func(job models.Job) string { return job.Title }},
+	{5, func(job models.Job) string { return strings.Join(job.MustSkills, " ") }},
+	{3, func(job models.Job) string { return strings.Join(job.OptionalSkills, " ") }},
+	{3, func(job models.Job) string { return job.Company }},
+	{3, func(job models.Job) string { return job.Location }},
+	{1, func(job models.Job) string { return job.Description }},
+}
+
+// searchCursor ist die Anzahl Treffer auf den vorherigen Seiten
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+// SearchJobs scans all jobs for the words of the search text, most relevant first.
+// The score is the weighted number of matched words; diacritics are ignored.
+func (s *Store) SearchJobs(ctx context.Context, jobSearch storage.JobSearch) (storage.SearchPage, error) {
+	page := storage.SearchPage{Results: []storage.SearchResult{}}
+
+	if strings.TrimSpace(jobSearch.Text) == "" {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeValidation, "search text is required", nil)
+	}
+	limit := jobSearch.PageSize()
+	offset, err := decodeSearchCursor(jobSearch.Cursor)
+	if err != nil {
+		return page, err
+	}
+
+	terms := storage.ParseSearchText(jobSearch.Text)
+	if terms.Empty() {
+		// Nur ausgeschlossene Wörter finden wie in MongoDB nichts
+		return page, nil
+	}
+
+	var results []storage.SearchResult
+	for _, job := range s.filterJobs(func(job models.Job) bool { return matchesQuery(job, jobSearch.JobQuery) }) {
+		if score, ok := scoreJob(job, terms); ok {
+			results = append(results, storage.SearchResult{Job: job, Score: score})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b storage.SearchResult) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Job.ID.Hex(), b.Job.ID.Hex())
+	})
+
+	page.Total = len(results)
+	results = results[min(offset, len(results)):]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	page.Results = append(page.Results, results...)
+
+	if next := offset + len(page.Results); next < page.Total {
+		if page.NextCursor, err = encodeSearchCursor(next); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// scoreJob returns the score of a job and whether it matches the search terms.
+// Like in MongoDB, any word matches, but all phrases are required and excluded
+// terms must not occur.
+func scoreJob(job models.Job, terms storage.SearchTerms) (float64, bool) {
+	fields := make([][]string, len(searchFields))
+	for i, field := range searchFields {
+		fields[i] = search.Tokens(field.text(job))
+	}
+	contains := func(term string) bool {
+		tokens := search.Tokens(term)
+		return slices.ContainsFunc(fields, func(field []string) bool { return containsSequence(field, tokens) })
+	}
+
+	for _, term := range terms.Excluded {
+		if contains(term) {
+			return 0, false
+		}
+	}
+	for _, phrase := range terms.Phrases {
+		if !contains(phrase) {
+			return 0, false
+		}
+	}
+
+	// Wörter der Phrasen zählen für die Relevanz wie einzelne Wörter
+	wanted := make(map[string]bool)
+	for _, term := range append(slices.Clone(terms.Words), terms.Phrases...) {
+		for _, token := range search.Tokens(term) {
+			wanted[token] = true
+		}
+	}
+	var score float64
+	for i, field := range fields {
+		for _, token := range field {
+			if wanted[token] {
+				score += searchFields[i].weight
+			}
+		}
+	}
+	return score, score > 0
+}
+
+// containsSequence reports whether the tokens occur in order and next to each other
+func containsSequence(field, tokens []string) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	for i := 0; i+len(tokens) <= len(field); i++ {
+		if slices.Equal(field[i:i+len(tokens)], tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeSearchCursor(offset int) (string, error) {
+	data, err := json.Marshal(searchCursor{Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSearchCursor(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Offset < 0 {
+		return 0, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	return cursor.Offset, nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"context"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+)
+
+// AggregateStats computes a statistic from the stored jobs with storage.ComputeStats
+func (s *Store) AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error) {
+	jobs := s.filterJobs(func(job models.Job) bool { return matchesFilter(job, query.Filter) })
+	return storage.ComputeStats(jobs, query)
+}
@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"job-scraper/internal/metrics/domains"
+	"job-scraper/internal/models"
+)
+
+// MetricsDecorator records the duration and outcome of every storage operation
+type MetricsDecorator struct {
+	storage Storage
+}
+
+func NewMetricsDecorator(storage Storage) Storage {
+	return &MetricsDecorator{storage: storage}
+}
+
+func (d *MetricsDecorator) GetOriginalStorage() Storage {
+	return d.storage
+}
+
+func (d *MetricsDecorator) SaveJob(ctx context.Context, job models.Job) error {
+	start := time.Now()
+	err := d.storage.SaveJob(ctx, job)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("save_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("save_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) GetJobs(ctx context.Context) ([]models.Job, error) {
+	start := time.Now()
+	jobs, err := d.storage.GetJobs(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_jobs", status).Inc()
+
+	return jobs, err
+}
+
+func (d *MetricsDecorator) GetJobByID(ctx context.Context, id string) (*models.Job, error) {
+	start := time.Now()
+	job, err := d.storage.GetJobByID(ctx, id)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_job_by_id", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_job_by_id", status).Inc()
+
+	return job, err
+}
+
+func (d *MetricsDecorator) GetJobByURL(ctx context.Context, url string) (*models.Job, error) {
+	start := time.Now()
+	job, err := d.storage.GetJobByURL(ctx, url)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_job_by_url", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_job_by_url", status).Inc()
+
+	return job, err
+}
+
+func (d *MetricsDecorator) GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error) {
+	start := time.Now()
+	versions, err := d.storage.GetJobVersions(ctx, jobID)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_job_versions", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_job_versions", status).Inc()
+
+	return versions, err
+}
+
+func (d *MetricsDecorator) QueryJobs(ctx context.Context, query JobQuery) (JobPage, error) {
+	start := time.Now()
+	page, err := d.storage.QueryJobs(ctx, query)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("query_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("query_jobs", status).Inc()
+
+	return page, err
+}
+
+func (d *MetricsDecorator) SearchJobs(ctx context.Context, search JobSearch) (SearchPage, error) {
+	start := time.Now()
+	page, err := d.storage.SearchJobs(ctx, search)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("search_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("search_jobs", status).Inc()
+
+	return page, err
+}
+
+func (d *MetricsDecorator) FindJobs(ctx context.Context, filter JobFilter) ([]models.Job, error) {
+	start := time.Now()
+	jobs, err := d.storage.FindJobs(ctx, filter)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("find_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("find_jobs", status).Inc()
+
+	return jobs, err
+}
+
+func (d *MetricsDecorator) UpdateJob(ctx context.Context, job models.Job) error {
+	start := time.Now()
+	err := d.storage.UpdateJob(ctx, job)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("update_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("update_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	start := time.Now()
+	err := d.storage.SaveFailedJob(ctx, job)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("save_failed_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("save_failed_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	start := time.Now()
+	jobs, err := d.storage.GetFailedJobs(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_failed_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_failed_jobs", status).Inc()
+
+	return jobs, err
+}
+
+func (d *MetricsDecorator) DeleteFailedJob(ctx context.Context, url string) error {
+	start := time.Now()
+	err := d.storage.DeleteFailedJob(ctx, url)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("delete_failed_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("delete_failed_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) QueueJob(ctx context.Context, job models.Job) error {
+	start := time.Now()
+	err := d.storage.QueueJob(ctx, job)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("queue_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("queue_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	start := time.Now()
+	jobs, err := d.storage.GetQueuedJobs(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_queued_jobs", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_queued_jobs", status).Inc()
+
+	return jobs, err
+}
+
+func (d *MetricsDecorator) DeleteQueuedJob(ctx context.Context, url string) error {
+	start := time.Now()
+	err := d.storage.DeleteQueuedJob(ctx, url)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("delete_queued_job", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("delete_queued_job", status).Inc()
+
+	return err
+}
+
+func (d *MetricsDecorator) GetJobCountByCategory(ctx context.Context) (map[string]int, error) {
+	start := time.Now()
+	counts, err := d.storage.GetJobCountByCategory(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_job_count_by_category", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_job_count_by_category", status).Inc()
+
+	return counts, err
+}
+
+func (d *MetricsDecorator) GetSkillCounts(ctx context.Context) (map[string]int, error) {
+	start := time.Now()
+	counts, err := d.storage.GetSkillCounts(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_skill_counts", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_skill_counts", status).Inc()
+
+	return counts, err
+}
+
+func (d *MetricsDecorator) GetTotalJobCount(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := d.storage.GetTotalJobCount(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_total_job_count", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_total_job_count", status).Inc()
+
+	return count, err
+}
+
+func (d *MetricsDecorator) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
+	start := time.Now()
+	urls, err := d.storage.GetExistingURLs(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_existing_urls", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_existing_urls", status).Inc()
+
+	return urls, err
+}
+
+func (d *MetricsDecorator) GetFingerprints(ctx context.Context, since time.Time) ([]JobFingerprint, error) {
+	start := time.Now()
+	fingerprints, err := d.storage.GetFingerprints(ctx, since)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("get_fingerprints", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("get_fingerprints", status).Inc()
+
+	return fingerprints, err
+}
+
+func (d *MetricsDecorator) Close(ctx context.Context) error {
+	start := time.Now()
+	err := d.storage.Close(ctx)
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	domains.DBOperationDuration.WithLabelValues("close", status).Observe(duration)
+	domains.DBOperationsTotal.WithLabelValues("close", status).Inc()
+
+	return err
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Migrator is implemented by backends with a versioned schema
+type Migrator interface {
+	// Migrate applies the pending migrations in version order and returns the applied ones
+	Migrate(ctx context.Context) ([]MigrationStatus, error)
+	// MigrationStatus lists all known migrations and whether they have been applied
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+}
+
+// MigrationStatus beschreibt den Stand einer Migration
+type MigrationStatus struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	Applied     bool      `bson:"-" json:"applied"`
+	AppliedAt   time.Time `bson:"appliedAt" json:"appliedAt,omitempty"`
+}
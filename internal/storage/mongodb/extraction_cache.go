@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const extractionCacheCollection = "extraction_cache"
+
+type extractionCacheEntry struct {
+	Key       string     `bson:"_id"`
+	Job       models.Job `bson:"job"`
+	CreatedAt time.Time  `bson:"createdAt"`
+}
+
+// ExtractionCache speichert LLM-Extraktionsergebnisse anhand eines Content-Hashes
+type ExtractionCache struct {
+	collection *mongo.Collection
+}
+
+// NewExtractionCache creates the cache collection. With a ttl > 0 entries expire automatically.
+func NewExtractionCache(ctx context.Context, client *Client, ttl time.Duration) (*ExtractionCache, error) {
+	collection := client.db.Collection(extractionCacheCollection)
+
+	if ttl > 0 {
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+		})
+		if err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to create extraction cache index", err)
+		}
+	}
+
+	return &ExtractionCache{collection: collection}, nil
+}
+
+func (c *ExtractionCache) Get(ctx context.Context, key string) (*models.Job, error) {
+	var entry extractionCacheEntry
+	err := c.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to read extraction cache", err)
+	}
+	return &entry.Job, nil
+}
+
+func (c *ExtractionCache) Put(ctx context.Context, key string, job models.Job) error {
+	// Identität des Jobs gehört nicht in den Cache
+	job.ID = primitive.NilObjectID
+	job.URL = ""
+
+	entry := extractionCacheEntry{
+		Key:       key,
+		Job:       job,
+		CreatedAt: time.Now(),
+	}
+	_, err := c.collection.ReplaceOne(ctx, bson.M{"_id": key}, entry, options.Replace().SetUpsert(true))
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to write extraction cache", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const jobVersionsCollection = "job_versions"
+
+// GetJobVersions returns the prior versions of a job, newest first
+func (c *Client) GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error) {
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("Job", jobID)
+	}
+
+	cursor, err := c.db.Collection(jobVersionsCollection).Find(ctx,
+		bson.M{"jobId": id},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+	)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch job versions", err)
+	}
+	defer cursor.Close(ctx)
+
+	versions := []models.JobVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+	}
+	return versions, nil
+}
+
+// revisionField zählt die Schreibvorgänge eines Jobdokuments. Ein Job wird nur
+// ersetzt, wenn die gelesene Revision noch gespeichert ist.
+const revisionField = "revision"
+
+// errJobChanged is returned by replaceJob if the stored job changed since it was read
+var errJobChanged = errors.New("job was changed concurrently")
+
+// maxSaveAttempts limits how often SaveJob reads a job again that keeps changing
+const maxSaveAttempts = 3
+
+// storedJob is a job document together with its revision. Jobs written before
+// revisions were counted have revision 0.
+type storedJob struct {
+	models.Job `bson:",inline"`
+	Revision   int `bson:"revision,omitempty"`
+}
+
+// replaceJob overwrites a stored job with a new scrape of the same posting. If the
+// content changed, the stored job is kept as prior version together with the diff.
+// The job is only replaced if it is unchanged since it was read, otherwise
+// errJobChanged is returned and the caller reads it again.
+func (c *Client) replaceJob(ctx context.Context, existing storedJob, job models.Job) error {
+	job.ID = existing.ID
+	// Ein Repost derselben URL ist kein Duplikat von sich selbst
+	if job.DuplicateOf != nil && *job.DuplicateOf == existing.ID {
+		job.DuplicateOf = nil
+	}
+
+	changes, err := storage.DiffJobs(existing.Job, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to compare job versions", err)
+	}
+	doc, err := storage.ToDocument(job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode job", err)
+	}
+
+	jobs := c.db.Collection("jobs")
+	if len(changes) == 0 {
+		// Ohne neue Version werden nur Felder wie lastSeenAt, duplicateOf und source übernommen
+		if _, err := jobs.UpdateOne(ctx, bson.M{"_id": existing.ID}, unversionedUpdate(doc)); err != nil {
+			return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+		}
+		return nil
+	}
+
+	doc[revisionField] = existing.Revision + 1
+	result, err := jobs.ReplaceOne(ctx, revisionFilter(existing), doc)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	if result.MatchedCount == 0 {
+		return errJobChanged
+	}
+
+	versions := c.db.Collection(jobVersionsCollection)
+	count, err := versions.CountDocuments(ctx, bson.M{"jobId": existing.ID})
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count job versions", err)
+	}
+	version := models.JobVersion{
+		JobID:      existing.ID,
+		URL:        existing.URL,
+		Version:    int(count) + 1,
+		ReplacedAt: time.Now(),
+		Changes:    changes,
+		Job:        existing.Job,
+	}
+	if _, err := versions.InsertOne(ctx, version); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+	return nil
+}
+
+// revisionFilter matches the job only in the revision it was read in
+func revisionFilter(existing storedJob) bson.M {
+	filter := bson.M{"_id": existing.ID}
+	if existing.Revision == 0 {
+		filter[revisionField] = bson.M{"$exists": false}
+	} else {
+		filter[revisionField] = existing.Revision
+	}
+	return filter
+}
+
+// unversionedUpdate sets the unversioned fields of a job document and removes those
+// the job does not have, e.g. a cleared duplicate link
+func unversionedUpdate(doc bson.M) bson.M {
+	set, unset := bson.M{}, bson.M{}
+	for _, field := range storage.UnversionedFields() {
+		if value, ok := doc[field]; ok {
+			set[field] = value
+		} else {
+			unset[field] = ""
+		}
+	}
+
+	update := bson.M{"$inc": bson.M{revisionField: 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update
+}
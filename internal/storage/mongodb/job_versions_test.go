@@ -0,0 +1,56 @@
+package mongodb
+
+import (
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnversionedUpdate(t *testing.T) {
+	seenAt := time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)
+	doc, err := storage.ToDocument(models.Job{
+		ID:         primitive.NewObjectID(),
+		Title:      "Go Developer",
+		LastSeenAt: &seenAt,
+		Source:     &models.SourcePayload{RawPayload: "<p>Go Developer</p>"},
+	})
+	require.NoError(t, err)
+
+	update := unversionedUpdate(doc)
+	set := update["$set"].(bson.M)
+	assert.Contains(t, set, "lastSeenAt")
+	assert.Contains(t, set, "source")
+	assert.NotContains(t, set, "title", "versioned fields are only written with a new version")
+	// Ein aufgehobener Duplikat-Link wird entfernt
+	assert.Contains(t, update["$unset"], "duplicateOf")
+	assert.Equal(t, bson.M{revisionField: 1}, update["$inc"])
+}
+
+func TestRevisionFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	filter := revisionFilter(storedJob{Job: models.Job{ID: id}})
+	assert.Equal(t, bson.M{"_id": id, revisionField: bson.M{"$exists": false}}, filter)
+
+	filter = revisionFilter(storedJob{Job: models.Job{ID: id}, Revision: 3})
+	assert.Equal(t, bson.M{"_id": id, revisionField: 3}, filter)
+}
+
+func TestStoredJobDecodesRevision(t *testing.T) {
+	id := primitive.NewObjectID()
+	data, err := bson.Marshal(bson.M{"_id": id, "url": "https://example.com/1", "title": "Go Developer", revisionField: 2})
+	require.NoError(t, err)
+
+	var stored storedJob
+	require.NoError(t, bson.Unmarshal(data, &stored))
+	assert.Equal(t, id, stored.ID)
+	assert.Equal(t, "Go Developer", stored.Title)
+	assert.Equal(t, 2, stored.Revision)
+}
@@ -0,0 +1,289 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const schemaMigrationsCollection = "schema_migrations"
+
+// Migration is a versioned change of the database schema. Up must be idempotent:
+// if two instances start at the same time, a step may run twice.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// migrations lists all steps in ascending version order. Applied steps must not be
+// changed, new steps are appended with the next version.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "merge jobs stored twice under the same URL into job versions",
+		Up:          mergeDuplicateURLs,
+	},
+	{
+		Version:     2,
+		Description: "create unique URL index and job version index",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db.Collection("jobs"), mongo.IndexModel{
+				Keys:    bson.D{{Key: "url", Value: 1}},
+				Options: options.Index().SetName("url_unique").SetUnique(true),
+			}); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db.Collection(jobVersionsCollection), mongo.IndexModel{
+				Keys: bson.D{{Key: "jobId", Value: 1}, {Key: "version", Value: -1}},
+			})
+		},
+	},
+	{
+		Version:     3,
+		Description: "create indexes for statistics and filters",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("jobs"),
+				mongo.IndexModel{Keys: bson.D{{Key: "postingDate", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "jobCategories", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "mustSkills", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "optionalSkills", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "company", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "location", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "locations.canton", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "postingLanguage", Value: 1}}},
+			)
+		},
+	},
+	{
+		Version:     4,
+		Description: "create text index over title, skills, company, location and description",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("jobs"), textIndex())
+		},
+	},
+	{
+		Version:     5,
+		Description: "convert plain-text salaries into salary documents",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("jobs").UpdateMany(ctx,
+				bson.M{"salary": bson.M{"$type": "string"}},
+				mongo.Pipeline{{{Key: "$set", Value: bson.D{
+					{Key: "salary", Value: bson.D{{Key: "text", Value: "$salary"}}},
+				}}}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "set extraction method of jobs extracted before it was recorded",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("jobs").UpdateMany(ctx,
+				bson.M{"extractionMethod": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"extractionMethod": models.ExtractionMethodLLM}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "create index for open jobs",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("jobs"), mongo.IndexModel{Keys: bson.D{{Key: "closedAt", Value: 1}}})
+		},
+	},
+}
+
+// textIndex is the text index over the searchable job fields.
+// The field holding the language of a document is never set, so all documents
+// are indexed without stemming.
+func textIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "mustSkills", Value: "text"},
+			{Key: "optionalSkills", Value: "text"},
+			{Key: "company", Value: "text"},
+			{Key: "location", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("jobs_text").
+			SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "mustSkills", Value: 5},
+				{Key: "optionalSkills", Value: 3},
+				{Key: "company", Value: 3},
+				{Key: "location", Value: 3},
+				{Key: "description", Value: 1},
+			}).
+			SetDefaultLanguage("none").
+			SetLanguageOverride("textLanguage"),
+	}
+}
+
+// Migrate applies the migrations that have not been applied yet, in version order.
+// It stops at the first failing step and returns the steps applied before.
+func (c *Client) Migrate(ctx context.Context) ([]storage.MigrationStatus, error) {
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []storage.MigrationStatus
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		start := time.Now()
+		if err := migration.Up(ctx, c.db); err != nil {
+			return result, apperrors.NewBaseError(
+				apperrors.ErrCodeStorage,
+				fmt.Sprintf("migration %d (%s) failed", migration.Version, migration.Description),
+				err,
+			)
+		}
+
+		status := storage.MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     true,
+			AppliedAt:   time.Now(),
+		}
+		_, err := c.db.Collection(schemaMigrationsCollection).InsertOne(ctx, status)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return result, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to record migration", err)
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("description", migration.Description).
+			Dur("duration", time.Since(start)).
+			Msg("Applied schema migration")
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// MigrationStatus returns all known migrations and whether they have been applied
+func (c *Client) MigrationStatus(ctx context.Context) ([]storage.MigrationStatus, error) {
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := storage.MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+func (c *Client) appliedMigrations(ctx context.Context) (map[int]storage.MigrationStatus, error) {
+	cursor, err := c.db.Collection(schemaMigrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to read schema migrations", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []storage.MigrationStatus
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode schema migrations", err)
+	}
+
+	applied := make(map[int]storage.MigrationStatus, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// createIndexes creates the given indexes. Existing indexes with the same keys
+// and options are left untouched, so the step can run again.
+func createIndexes(ctx context.Context, collection *mongo.Collection, indexes ...mongo.IndexModel) error {
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// mergeDuplicateURLs keeps the most recently stored job of every URL and moves the
+// older ones into job_versions, so the unique URL index can be created
+func mergeDuplicateURLs(ctx context.Context, db *mongo.Database) error {
+	jobs := db.Collection("jobs")
+	cursor, err := jobs.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$url"},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var groups []struct {
+		URL string               `bson:"_id"`
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	versions := db.Collection(jobVersionsCollection)
+	for _, group := range groups {
+		findCursor, err := jobs.Find(ctx,
+			bson.M{"_id": bson.M{"$in": group.IDs}},
+			options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+		)
+		if err != nil {
+			return err
+		}
+		var stored []models.Job
+		if err := findCursor.All(ctx, &stored); err != nil {
+			return err
+		}
+		if len(stored) < 2 {
+			continue
+		}
+
+		latest := stored[len(stored)-1]
+		for i, job := range stored[:len(stored)-1] {
+			changes, err := storage.DiffJobs(job, stored[i+1])
+			if err != nil {
+				return err
+			}
+			version := models.JobVersion{
+				JobID:      latest.ID,
+				URL:        group.URL,
+				Version:    i + 1,
+				ReplacedAt: stored[i+1].ID.Timestamp(),
+				Changes:    changes,
+				Job:        job,
+			}
+			if _, err := versions.InsertOne(ctx, version); err != nil {
+				return err
+			}
+			if _, err := jobs.DeleteOne(ctx, bson.M{"_id": job.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"job-scraper/internal/apperrors"
 	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -15,10 +19,24 @@ type JobRepository interface {
 	SaveJob(ctx context.Context, job models.Job) error
 	GetJobByID(ctx context.Context, id string) (*models.Job, error)
 	GetJobs(ctx context.Context) ([]models.Job, error)
+	QueueJob(ctx context.Context, job models.Job) error
+	GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error)
+	DeleteQueuedJob(ctx context.Context, url string) error
+	GetJobByURL(ctx context.Context, url string) (*models.Job, error)
+	GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error)
+	FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error)
+	QueryJobs(ctx context.Context, query storage.JobQuery) (storage.JobPage, error)
+	SearchJobs(ctx context.Context, search storage.JobSearch) (storage.SearchPage, error)
+	UpdateJob(ctx context.Context, job models.Job) error
+	SaveFailedJob(ctx context.Context, job models.FailedJob) error
+	GetFailedJobs(ctx context.Context) ([]models.FailedJob, error)
+	DeleteFailedJob(ctx context.Context, url string) error
 	GetJobCountByCategory(ctx context.Context) (map[string]int, error)
+	GetSkillCounts(ctx context.Context) (map[string]int, error)
 	GetTotalJobCount(ctx context.Context) (int, error)
 	GetExistingURLs(ctx context.Context) (map[string]bool, error)
-	AggregateJobs(ctx context.Context, pipeline mongo.Pipeline) ([]bson.M, error)
+	GetFingerprints(ctx context.Context, since time.Time) ([]storage.JobFingerprint, error)
+	AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error)
 }
 
 type Client struct {
@@ -70,10 +88,204 @@ func (c *Client) GetJobByID(ctx context.Context, id string) (*models.Job, error)
 	return &job, nil
 }
 
+// SaveJob inserts a job or, if a job with the same URL is already stored, replaces it.
+// Prior versions of changed postings are kept in the job_versions collection. The
+// insert is an upsert on the URL, so parallel runs cannot store a posting twice, and
+// a job changed by a parallel run while it is replaced is read and compared again.
 func (c *Client) SaveJob(ctx context.Context, job models.Job) error {
-	_, err := c.db.Collection("jobs").InsertOne(ctx, job)
+	var err error
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		if err = c.saveJob(ctx, job); !errors.Is(err, errJobChanged) {
+			return err
+		}
+	}
+	return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+}
+
+func (c *Client) saveJob(ctx context.Context, job models.Job) error {
+	existing, err := c.findJobByURL(ctx, job.URL)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return c.replaceJob(ctx, *existing, job)
+	}
+
+	inserted, err := c.insertJob(ctx, job)
+	if err != nil || inserted {
+		return err
+	}
+
+	// Ein paralleler Lauf hat denselben Job zuerst gespeichert
+	existing, err = c.findJobByURL(ctx, job.URL)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", nil)
+	}
+	return c.replaceJob(ctx, *existing, job)
+}
+
+// insertJob stores the job unless a job with the same URL exists and reports whether
+// it was inserted. The document is only written on insert, an existing job is kept.
+func (c *Client) insertJob(ctx context.Context, job models.Job) (bool, error) {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	doc, err := storage.ToDocument(job)
+	if err != nil {
+		return false, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode job", err)
+	}
+
+	result, err := c.db.Collection("jobs").UpdateOne(ctx,
+		bson.M{"url": job.URL},
+		bson.M{"$setOnInsert": doc},
+		options.Update().SetUpsert(true),
+	)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		// Zwei Upserts derselben URL: der Unique-Index lässt nur einen zu
+		return false, nil
+	case err != nil:
+		return false, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// GetJobByURL returns the job stored for a posting URL
+func (c *Client) GetJobByURL(ctx context.Context, url string) (*models.Job, error) {
+	stored, err := c.findJobByURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, apperrors.NewNotFoundError("Job", url)
+	}
+	return &stored.Job, nil
+}
+
+// findJobByURL returns nil if no job is stored for the URL
+func (c *Client) findJobByURL(ctx context.Context, url string) (*storedJob, error) {
+	var job storedJob
+	err := c.db.Collection("jobs").FindOne(ctx, bson.M{"url": url}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to find job by URL", err)
+	}
+	return &job, nil
+}
+
+func (c *Client) FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	var jobs []models.Job
+	cursor, err := c.db.Collection("jobs").Find(ctx, jobFilterToBSON(filter))
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to find jobs", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode jobs", err)
+	}
+	return jobs, nil
+}
+
+// UpdateJob replaces the job with the same ID. Its revision is counted up, so a
+// parallel SaveJob of the posting notices the change.
+func (c *Client) UpdateJob(ctx context.Context, job models.Job) error {
+	doc, err := storage.ToDocument(job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode job", err)
+	}
+	// $literal, damit Werte wie "$100" nicht als Feldpfad gelesen werden
+	update := mongo.Pipeline{{{Key: "$replaceWith", Value: bson.M{"$mergeObjects": bson.A{
+		bson.M{"$literal": doc},
+		bson.M{revisionField: bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + revisionField, 0}}, 1}}},
+	}}}}}
+	result, err := c.db.Collection("jobs").UpdateOne(ctx, bson.M{"_id": job.ID}, update)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError("Job", job.ID.Hex())
+	}
+	return nil
+}
+
+func (c *Client) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"description":  job.Description,
+			"source":       job.Source,
+			"error":        job.Error,
+			"lastFailedAt": now,
+		},
+		"$setOnInsert": bson.M{"firstFailedAt": now},
+		"$inc":         bson.M{"attempts": 1},
+	}
+	_, err := c.db.Collection("failed_jobs").UpdateByID(ctx, job.URL, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save failed job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	var jobs []models.FailedJob
+	cursor, err := c.db.Collection("failed_jobs").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch failed jobs", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode failed jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteFailedJob(ctx context.Context, url string) error {
+	_, err := c.db.Collection("failed_jobs").DeleteOne(ctx, bson.M{"_id": url})
 	if err != nil {
-		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete failed job", err)
+	}
+	return nil
+}
+
+// QueueJob stores a job until the LLM budget resets. Queuing a job again keeps its position.
+func (c *Client) QueueJob(ctx context.Context, job models.Job) error {
+	update := bson.M{
+		"$set":         bson.M{"job": job},
+		"$setOnInsert": bson.M{"queuedAt": time.Now()},
+	}
+	_, err := c.db.Collection("queued_jobs").UpdateByID(ctx, job.URL, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	var jobs []models.QueuedJob
+	cursor, err := c.db.Collection("queued_jobs").Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"queuedAt": 1}))
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch queued jobs", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteQueuedJob(ctx context.Context, url string) error {
+	_, err := c.db.Collection("queued_jobs").DeleteOne(ctx, bson.M{"_id": url})
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete queued job", err)
 	}
 	return nil
 }
@@ -103,6 +315,37 @@ func (c *Client) GetJobCountByCategory(ctx context.Context) (map[string]int, err
 	return countMap, nil
 }
 
+// GetSkillCounts returns the number of jobs per skill, required and optional skills combined
+func (c *Client) GetSkillCounts(ctx context.Context) (map[string]int, error) {
+	pipeline := []bson.M{
+		{"$project": bson.M{"skills": bson.M{"$setUnion": bson.A{
+			bson.M{"$ifNull": bson.A{"$mustSkills", bson.A{}}},
+			bson.M{"$ifNull": bson.A{"$optionalSkills", bson.A{}}},
+		}}}},
+		{"$unwind": "$skills"},
+		{"$group": bson.M{"_id": "$skills", "count": bson.M{"$sum": 1}}},
+	}
+	cursor, err := c.db.Collection("jobs").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count skills", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode skill counts", err)
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.ID] = result.Count
+	}
+	return counts, nil
+}
+
 func (c *Client) GetTotalJobCount(ctx context.Context) (int, error) {
 	count, err := c.db.Collection("jobs").CountDocuments(ctx, bson.M{})
 	return int(count), err
@@ -133,20 +376,79 @@ func (c *Client) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
 	return urls, nil
 }
 
+// GetFingerprints returns the fingerprints of the original jobs stored since the given time.
+// Jobs marked as duplicates are left out, so new duplicates link to the original.
+func (c *Client) GetFingerprints(ctx context.Context, since time.Time) ([]storage.JobFingerprint, error) {
+	query := bson.M{
+		"fingerprint": bson.M{"$exists": true},
+		"duplicateOf": bson.M{"$exists": false},
+	}
+	if !since.IsZero() {
+		// Die ObjectID enthält den Zeitpunkt, zu dem der Job gespeichert wurde
+		query["_id"] = bson.M{"$gte": primitive.NewObjectIDFromTimestamp(since)}
+	}
+
+	cursor, err := c.db.Collection("jobs").Find(ctx, query, options.Find().SetProjection(bson.M{"fingerprint": 1}))
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch fingerprints", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID          primitive.ObjectID `bson:"_id"`
+		Fingerprint string             `bson:"fingerprint"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode fingerprints", err)
+	}
+
+	fingerprints := make([]storage.JobFingerprint, 0, len(results))
+	for _, result := range results {
+		fingerprints = append(fingerprints, storage.JobFingerprint{ID: result.ID, Fingerprint: result.Fingerprint})
+	}
+	return fingerprints, nil
+}
+
 func (c *Client) Close(ctx context.Context) error {
 	return c.client.Disconnect(ctx)
 }
 
-func (c *Client) AggregateJobs(ctx context.Context, pipeline mongo.Pipeline) ([]bson.M, error) {
-	cursor, err := c.db.Collection("jobs").Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, err
+// jobFilterToBSON translates a storage.JobFilter into a MongoDB query
+func jobFilterToBSON(filter storage.JobFilter) bson.M {
+	query := bson.M{}
+
+	postingDate := bson.M{}
+	if !filter.PostedFrom.IsZero() {
+		postingDate["$gte"] = filter.PostedFrom
+	}
+	if !filter.PostedTo.IsZero() {
+		postingDate["$lt"] = filter.PostedTo
+	}
+	if len(postingDate) > 0 {
+		query["postingDate"] = postingDate
 	}
-	defer cursor.Close(ctx)
 
-	var results []bson.M
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
+	if filter.Category != "" {
+		query["jobCategories"] = filter.Category
+	}
+	if filter.PromptVersion != "" {
+		query["promptVersion"] = filter.PromptVersion
+	}
+	if filter.ExtractionMethod != "" {
+		query["extractionMethod"] = filter.ExtractionMethod
+	}
+	if filter.PostingLanguage != "" {
+		query["postingLanguage"] = filter.PostingLanguage
 	}
-	return results, nil
+	if filter.OriginalsOnly {
+		query["duplicateOf"] = bson.M{"$exists": false}
+	}
+	if filter.OpenOnly {
+		query["closedAt"] = nil
+	}
+	if filter.ClosedOnly {
+		query["closedAt"] = bson.M{"$ne": nil}
+	}
+
+	return query
 }
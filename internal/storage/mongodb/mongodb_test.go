@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
 	"testing"
 	"time"
 
@@ -10,7 +11,6 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MockJobRepository struct {
@@ -47,9 +47,9 @@ func (m *MockJobRepository) GetExistingURLs(ctx context.Context) (map[string]boo
 	return args.Get(0).(map[string]bool), args.Error(1)
 }
 
-func (m *MockJobRepository) AggregateJobs(ctx context.Context, pipeline mongo.Pipeline) ([]bson.M, error) {
-	args := m.Called(ctx, pipeline)
-	return args.Get(0).([]bson.M), args.Error(1)
+func (m *MockJobRepository) AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]storage.StatsRow), args.Error(1)
 }
 
 func TestSaveJob(t *testing.T) {
@@ -86,3 +86,22 @@ func TestGetJobByID(t *testing.T) {
 	assert.Equal(t, job, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestJobFilterToBSON(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	query := jobFilterToBSON(storage.JobFilter{
+		PostedFrom:    from,
+		PostedTo:      to,
+		Category:      "ENGINEER",
+		PromptVersion: "1",
+	})
+
+	assert.Equal(t, bson.M{
+		"postingDate":   bson.M{"$gte": from, "$lt": to},
+		"jobCategories": "ENGINEER",
+		"promptVersion": "1",
+	}, query)
+	assert.Empty(t, jobFilterToBSON(storage.JobFilter{}))
+}
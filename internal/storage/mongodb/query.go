@@ -0,0 +1,193 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pageCursor ist die Position nach dem letzten Job einer Seite
+type pageCursor struct {
+	SortBy string             `bson:"s"`
+	Value  interface{}        `bson:"v"`
+	ID     primitive.ObjectID `bson:"id"`
+}
+
+// QueryJobs returns a page of the jobs matching the query. Pages are continued with
+// the cursor of the previous page, which stays stable while new jobs are stored.
+func (c *Client) QueryJobs(ctx context.Context, query storage.JobQuery) (storage.JobPage, error) {
+	page := storage.JobPage{Jobs: []models.Job{}}
+
+	sortBy, direction, limit, err := queryOrder(query)
+	if err != nil {
+		return page, err
+	}
+
+	filter := jobQueryToBSON(query)
+	total, err := c.db.Collection("jobs").CountDocuments(ctx, filter)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count jobs", err)
+	}
+	page.Total = int(total)
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, sortBy)
+		if err != nil {
+			return page, err
+		}
+		filter = bson.M{"$and": bson.A{filter, cursorFilter(cursor, direction)}}
+	}
+
+	// Ein zusätzlicher Job zeigt an, ob es eine weitere Seite gibt
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortBy, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(limit + 1))
+	findCursor, err := c.db.Collection("jobs").Find(ctx, filter, opts)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to query jobs", err)
+	}
+	defer findCursor.Close(ctx)
+
+	if err := findCursor.All(ctx, &page.Jobs); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode jobs", err)
+	}
+
+	if len(page.Jobs) > limit {
+		page.Jobs = page.Jobs[:limit]
+		if page.NextCursor, err = encodeCursor(page.Jobs[limit-1], sortBy); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// queryOrder returns the sort field, the sort direction and the page size of a query
+func queryOrder(query storage.JobQuery) (string, int, int, error) {
+	sortBy, err := query.SortField()
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	direction := 1
+	if query.SortDescending {
+		direction = -1
+	}
+	return sortBy, direction, query.PageSize(), nil
+}
+
+// jobQueryToBSON translates the filter fields of a storage.JobQuery into a MongoDB query
+func jobQueryToBSON(query storage.JobQuery) bson.M {
+	filter := bson.M{}
+	var conditions bson.A
+
+	if query.Category != "" {
+		filter["jobCategories"] = strings.ToUpper(strings.TrimSpace(query.Category))
+	}
+	if query.Skill != "" {
+		skill := exactMatch(query.Skill)
+		switch query.SkillType {
+		case storage.SkillTypeMust:
+			filter["mustSkills"] = skill
+		case storage.SkillTypeOptional:
+			filter["optionalSkills"] = skill
+		default:
+			conditions = append(conditions, bson.M{"$or": bson.A{
+				bson.M{"mustSkills": skill},
+				bson.M{"optionalSkills": skill},
+			}})
+		}
+	}
+	if query.Company != "" {
+		filter["company"] = containsMatch(query.Company)
+	}
+	if query.Location != "" {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"location": containsMatch(query.Location)},
+			bson.M{"locations.city": exactMatch(query.Location)},
+			bson.M{"locations.canton": strings.ToUpper(strings.TrimSpace(query.Location))},
+		}})
+	}
+	if query.Remote != nil {
+		filter["remote"] = *query.Remote
+	}
+	if query.EmploymentType != "" {
+		filter["employmentType"] = exactMatch(query.EmploymentType)
+	}
+
+	postingDate := bson.M{}
+	if !query.PostedFrom.IsZero() {
+		postingDate["$gte"] = query.PostedFrom
+	}
+	if !query.PostedTo.IsZero() {
+		postingDate["$lt"] = query.PostedTo
+	}
+	if len(postingDate) > 0 {
+		filter["postingDate"] = postingDate
+	}
+
+	if query.MinExperience > 0 {
+		filter["yearsOfExperience"] = bson.M{"$gte": query.MinExperience}
+	}
+
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
+	}
+	return filter
+}
+
+// cursorFilter selects the jobs after the cursor in sort order. Jobs with the
+// same sort value are ordered by ID.
+func cursorFilter(cursor pageCursor, direction int) bson.M {
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{cursor.SortBy: bson.M{op: cursor.Value}},
+		bson.M{cursor.SortBy: cursor.Value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+func encodeCursor(job models.Job, sortBy string) (string, error) {
+	doc, err := storage.ToDocument(job)
+	if err != nil {
+		return "", err
+	}
+	data, err := bson.Marshal(pageCursor{SortBy: sortBy, Value: doc[sortBy], ID: job.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value, sortBy string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = bson.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	if cursor.SortBy != sortBy {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "page cursor belongs to a different sort order", nil)
+	}
+	return cursor, nil
+}
+
+func exactMatch(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(value)) + "$", Options: "i"}
+}
+
+func containsMatch(value string) primitive.Regex {
+	return primitive.Regex{Pattern: regexp.QuoteMeta(strings.TrimSpace(value)), Options: "i"}
+}
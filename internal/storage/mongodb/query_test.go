@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestJobQueryToBSON(t *testing.T) {
+	remote := true
+	filter := jobQueryToBSON(storage.JobQuery{
+		Category:      "software engineer",
+		Skill:         "C++",
+		SkillType:     storage.SkillTypeMust,
+		Company:       "Example (Schweiz)",
+		Remote:        &remote,
+		PostedFrom:    time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		MinExperience: 3,
+	})
+
+	assert.Equal(t, "SOFTWARE ENGINEER", filter["jobCategories"])
+	assert.Equal(t, primitive.Regex{Pattern: `^C\+\+$`, Options: "i"}, filter["mustSkills"])
+	assert.Equal(t, primitive.Regex{Pattern: `Example \(Schweiz\)`, Options: "i"}, filter["company"])
+	assert.Equal(t, true, filter["remote"])
+	assert.Equal(t, bson.M{"$gte": time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)}, filter["postingDate"])
+	assert.Equal(t, bson.M{"$gte": 3}, filter["yearsOfExperience"])
+	assert.NotContains(t, filter, "$and")
+
+	filter = jobQueryToBSON(storage.JobQuery{Skill: "Go", Location: "zh"})
+	assert.Len(t, filter["$and"], 2)
+}
+
+func TestPageCursor(t *testing.T) {
+	job := models.Job{ID: primitive.NewObjectID(), Title: "Go Developer", PostingDate: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)}
+
+	encoded, err := encodeCursor(job, storage.SortPostingDate)
+	require.NoError(t, err)
+
+	cursor, err := decodeCursor(encoded, storage.SortPostingDate)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, cursor.ID)
+	assert.Equal(t, primitive.NewDateTimeFromTime(job.PostingDate), cursor.Value)
+
+	_, err = decodeCursor(encoded, storage.SortTitle)
+	assert.Error(t, err, "cursor of another sort order")
+
+	_, err = decodeCursor("not a cursor", storage.SortPostingDate)
+	assert.Error(t, err)
+}
+
+func TestSearchCursor(t *testing.T) {
+	encoded, err := encodeSearchCursor(50)
+	require.NoError(t, err)
+
+	offset, err := decodeSearchCursor(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, 50, offset)
+
+	offset, err = decodeSearchCursor("")
+	require.NoError(t, err)
+	assert.Zero(t, offset)
+
+	_, err = decodeSearchCursor("not a cursor")
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchCursor ist die Anzahl Treffer auf den vorherigen Seiten. Die Relevanz lässt
+// sich nicht filtern, deshalb wird hier mit Offset statt mit Keyset geblättert.
+type searchCursor struct {
+	Offset int `bson:"o"`
+}
+
+// SearchJobs finds jobs with the text index, most relevant first
+func (c *Client) SearchJobs(ctx context.Context, search storage.JobSearch) (storage.SearchPage, error) {
+	page := storage.SearchPage{Results: []storage.SearchResult{}}
+
+	if strings.TrimSpace(search.Text) == "" {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeValidation, "search text is required", nil)
+	}
+	limit := search.PageSize()
+	offset, err := decodeSearchCursor(search.Cursor)
+	if err != nil {
+		return page, err
+	}
+
+	filter := jobQueryToBSON(search.JobQuery)
+	filter["$text"] = bson.M{"$search": search.Text}
+
+	total, err := c.db.Collection("jobs").CountDocuments(ctx, filter)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count search results", err)
+	}
+	page.Total = int(total)
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := c.db.Collection("jobs").Find(ctx, filter, opts)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to search jobs", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		models.Job `bson:",inline"`
+		Score      float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode search results", err)
+	}
+
+	for _, result := range results {
+		page.Results = append(page.Results, storage.SearchResult{Job: result.Job, Score: result.Score})
+	}
+	if next := offset + len(results); next < page.Total {
+		if page.NextCursor, err = encodeSearchCursor(next); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+func encodeSearchCursor(offset int) (string, error) {
+	data, err := bson.Marshal(searchCursor{Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSearchCursor(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = bson.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Offset < 0 {
+		return 0, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	return cursor.Offset, nil
+}
@@ -0,0 +1,338 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AggregateStats runs a statistic as aggregation pipeline, see statsPipeline
+func (c *Client) AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.db.Collection("jobs").Aggregate(ctx, statsPipeline(query))
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to aggregate jobs", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode aggregation results", err)
+	}
+
+	rows := make([]storage.StatsRow, 0, len(results))
+	for _, result := range results {
+		id, _ := result["_id"].(bson.M)
+		row := storage.StatsRow{Keys: make([]string, len(query.GroupBy)), Values: make(map[string]float64)}
+		for i := range query.GroupBy {
+			row.Keys[i], _ = id[fmt.Sprintf("k%d", i)].(string)
+		}
+		count, _ := toFloat(result["count"])
+		row.Count = int(count)
+		for i, measure := range query.Measures {
+			value := result[fmt.Sprintf("m%d", i)]
+			if measure.Aggregate.IsPercentile() {
+				if samples := floats(value); len(samples) > 0 {
+					row.Values[measure.Name] = storage.Percentile(samples, measure.Aggregate.Fraction())
+				}
+			} else if value, ok := toFloat(value); ok {
+				row.Values[measure.Name] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	if sortsByPercentile(query) {
+		rows = storage.SortStats(rows, query)
+	}
+	return rows, nil
+}
+
+// statsPipeline translates a StatsQuery. Every dimension becomes an array of the
+// job's values, which is unwound for grouping. Grouping by job first makes sure
+// a job counts once per group, even if a list contains a value twice. Percentiles
+// collect the values of a group and are computed by AggregateStats, which then
+// also sorts if the query is ordered by a percentile.
+func statsPipeline(query storage.StatsQuery) mongo.Pipeline {
+	pipeline := mongo.Pipeline{}
+	if filter := jobFilterToBSON(query.Filter); len(filter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: filter}})
+	}
+
+	project := bson.D{}
+	for i, dimension := range query.GroupBy {
+		project = append(project, bson.E{Key: fmt.Sprintf("k%d", i), Value: dimensionExpr(dimension, query.TimeBucket())})
+	}
+	for i, condition := range query.Where {
+		project = append(project, bson.E{Key: fmt.Sprintf("w%d", i), Value: dimensionExpr(condition.Dimension, query.TimeBucket())})
+	}
+	for i, measure := range query.Measures {
+		project = append(project, bson.E{Key: fmt.Sprintf("m%d", i), Value: measureExpr(measure.Field)})
+	}
+	if len(project) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: project}})
+	}
+
+	if len(query.Where) > 0 {
+		conditions := bson.A{}
+		for i, condition := range query.Where {
+			wanted := make(bson.A, 0, len(condition.Values))
+			for _, value := range condition.Values {
+				wanted = append(wanted, strings.ToLower(value))
+			}
+			lowered := bson.D{{Key: "$map", Value: bson.D{
+				{Key: "input", Value: fmt.Sprintf("$w%d", i)},
+				{Key: "in", Value: bson.D{{Key: "$toLower", Value: "$$this"}}},
+			}}}
+			conditions = append(conditions, bson.D{{Key: "$gt", Value: bson.A{
+				bson.D{{Key: "$size", Value: bson.D{{Key: "$setIntersection", Value: bson.A{lowered, wanted}}}}}, 0,
+			}}})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "$expr", Value: bson.D{{Key: "$and", Value: conditions}}}}}})
+	}
+
+	for i := range query.GroupBy {
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: fmt.Sprintf("$k%d", i)}})
+	}
+
+	byJob := bson.D{{Key: "job", Value: "$_id"}}
+	byGroup := bson.D{}
+	for i := range query.GroupBy {
+		key := fmt.Sprintf("k%d", i)
+		byJob = append(byJob, bson.E{Key: key, Value: "$" + key})
+		byGroup = append(byGroup, bson.E{Key: key, Value: "$_id." + key})
+	}
+	jobGroup := bson.D{{Key: "_id", Value: byJob}}
+	group := bson.D{{Key: "_id", Value: byGroup}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}
+	for i, measure := range query.Measures {
+		key := fmt.Sprintf("m%d", i)
+		jobGroup = append(jobGroup, bson.E{Key: key, Value: bson.D{{Key: "$first", Value: "$" + key}}})
+		aggregate := bson.D{{Key: "$" + string(measure.Aggregate), Value: "$" + key}}
+		switch measure.Aggregate {
+		case storage.AggregateMedian, storage.AggregateP90:
+			aggregate = bson.D{{Key: "$push", Value: "$" + key}}
+		case storage.AggregateCount:
+			aggregate = bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + key, nil}}}, nil}}}, 0, 1,
+			}}}}}
+		}
+		group = append(group, bson.E{Key: key, Value: aggregate})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: jobGroup}},
+		bson.D{{Key: "$group", Value: group}},
+	)
+	if sortsByPercentile(query) {
+		return pipeline
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: statsSort(query)}})
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit}})
+	}
+	return pipeline
+}
+
+// sortsByPercentile reports whether the query is ordered by a percentile measure
+func sortsByPercentile(query storage.StatsQuery) bool {
+	for _, order := range query.OrderBy {
+		for _, measure := range query.Measures {
+			if measure.Name == order.Key && measure.Aggregate.IsPercentile() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// statsSort sorts by the OrderBy keys and then by the dimension values
+func statsSort(query storage.StatsQuery) bson.D {
+	sort := bson.D{}
+	added := make(map[string]bool)
+	add := func(field string, descending bool) {
+		if added[field] {
+			return
+		}
+		added[field] = true
+		direction := 1
+		if descending {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: field, Value: direction})
+	}
+
+	for _, order := range query.OrderBy {
+		field := order.Key
+		for i, dimension := range query.GroupBy {
+			if string(dimension) == order.Key {
+				field = fmt.Sprintf("_id.k%d", i)
+			}
+		}
+		for i, measure := range query.Measures {
+			if measure.Name == order.Key {
+				field = fmt.Sprintf("m%d", i)
+			}
+		}
+		add(field, order.Descending)
+	}
+	for i := range query.GroupBy {
+		add(fmt.Sprintf("_id.k%d", i), false)
+	}
+	if len(sort) == 0 {
+		sort = bson.D{{Key: "_id", Value: 1}}
+	}
+	return sort
+}
+
+// dimensionExpr returns an expression for the array of the job's values in a dimension
+func dimensionExpr(dimension storage.Dimension, bucket storage.TimeBucket) interface{} {
+	field := func(name string) interface{} {
+		return bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + name, ""}}}}
+	}
+	list := func(name string) interface{} {
+		return bson.D{{Key: "$ifNull", Value: bson.A{"$" + name, bson.A{}}}}
+	}
+	// classes returns the label of the first limit the field does not exceed
+	classes := func(name string, limits []int, labels []string, other string) interface{} {
+		branches := bson.A{}
+		for i, limit := range limits {
+			branches = append(branches, bson.D{
+				{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + name, 0}}}, limit}}}},
+				{Key: "then", Value: labels[i]},
+			})
+		}
+		return bson.A{bson.D{{Key: "$switch", Value: bson.D{{Key: "branches", Value: branches}, {Key: "default", Value: other}}}}}
+	}
+
+	switch dimension {
+	case storage.DimCategory:
+		return list("jobCategories")
+	case storage.DimMustSkill:
+		return list("mustSkills")
+	case storage.DimOptionalSkill:
+		return list("optionalSkills")
+	case storage.DimBenefit:
+		return list("benefits")
+	case storage.DimLanguage:
+		return list("languages")
+	case storage.DimCompany:
+		return field("company")
+	case storage.DimEmploymentType:
+		return field("employmentType")
+	case storage.DimEducationLevel:
+		return field("educationLevel")
+	case storage.DimRemote:
+		return bson.A{bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$remote", true}}}, "true", "false"}}}}
+	case storage.DimCompanySize:
+		return classes("companySize", []int{-1, 50, 250}, []string{"Unknown", "Small", "Medium"}, "Large")
+	case storage.DimExperience:
+		return classes("yearsOfExperience", []int{2, 5}, []string{"Junior", "Mid-Level"}, "Senior")
+	case storage.DimLocation:
+		location := bson.D{{Key: "$ifNull", Value: bson.A{"$location", ""}}}
+		cities := bson.D{{Key: "$map", Value: bson.D{
+			{Key: "input", Value: "$locations"},
+			{Key: "in", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$$this.city", ""}}}, ""}}},
+				"$$this.city",
+				location,
+			}}}},
+		}}}
+		return bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gt", Value: bson.A{bson.D{{Key: "$size", Value: list("locations")}}, 0}}},
+			bson.D{{Key: "$setUnion", Value: bson.A{cities}}},
+			bson.A{location},
+		}}}
+	case storage.DimCanton:
+		cantons := bson.D{{Key: "$map", Value: bson.D{
+			{Key: "input", Value: list("locations")},
+			{Key: "in", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$$this.canton", ""}}}},
+		}}}
+		return bson.D{{Key: "$setDifference", Value: bson.A{cantons, bson.A{""}}}}
+	case storage.DimPostingDate:
+		return bson.A{dateExpr("$postingDate", bucket)}
+	case storage.DimClosedDate:
+		return bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$closedAt", nil}}}, nil}}},
+			bson.A{},
+			bson.A{dateExpr("$closedAt", bucket)},
+		}}}
+	}
+	return bson.A{}
+}
+
+// dateExpr formats a date in UTC, cut to the bucket
+func dateExpr(date string, bucket storage.TimeBucket) interface{} {
+	format := "%Y-%m-%d"
+	if bucket == storage.BucketMonth {
+		format = "%Y-%m"
+	}
+	return bson.D{{Key: "$dateToString", Value: bson.D{{Key: "format", Value: format}, {Key: "date", Value: date}}}}
+}
+
+// measureExpr returns an expression for the value of a measure field, null if the job has none
+func measureExpr(field storage.MeasureField) interface{} {
+	positive := func(value interface{}) interface{} {
+		return bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$gt", Value: bson.A{value, 0}}}, value, nil}}}
+	}
+
+	switch field {
+	case storage.FieldYearsOfExperience:
+		return positive("$yearsOfExperience")
+	case storage.FieldCompanySize:
+		return positive("$companySize")
+	case storage.FieldAnnualSalary:
+		// $avg ignores null, also if only one end of the range is known
+		return bson.D{{Key: "$avg", Value: bson.A{positive("$salary.annualMinChf"), positive("$salary.annualMaxChf")}}}
+	case storage.FieldPostingDate:
+		return bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gt", Value: bson.A{"$postingDate", time.Time{}}}},
+			bson.D{{Key: "$divide", Value: bson.A{bson.D{{Key: "$toLong", Value: "$postingDate"}}, 1000}}},
+			nil,
+		}}}
+	case storage.FieldLifetime:
+		// Ohne closedAt ist der Vergleich mit dem Datum falsch, da null vor allen Daten sortiert
+		return bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$postingDate", time.Time{}}}},
+				bson.D{{Key: "$gte", Value: bson.A{"$closedAt", "$postingDate"}}},
+			}}},
+			bson.D{{Key: "$divide", Value: bson.A{bson.D{{Key: "$subtract", Value: bson.A{"$closedAt", "$postingDate"}}}, 86400000}}},
+			nil,
+		}}}
+	}
+	return nil
+}
+
+// floats converts the numbers of an array of an aggregation result, skipping null
+func floats(value interface{}) []float64 {
+	array, _ := value.(bson.A)
+	values := make([]float64, 0, len(array))
+	for _, element := range array {
+		if v, ok := toFloat(element); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// toFloat converts a number of an aggregation result
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
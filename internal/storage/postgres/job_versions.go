@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetJobVersions returns the prior versions of a job, newest first
+func (c *Client) GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error) {
+	if _, err := primitive.ObjectIDFromHex(jobID); err != nil {
+		return nil, apperrors.NewNotFoundError("Job", jobID)
+	}
+
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, job_id, url, version, replaced_at, changes, job
+		FROM job_versions WHERE job_id = $1 ORDER BY version DESC`, jobID)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch job versions", err)
+	}
+	defer rows.Close()
+
+	versions := []models.JobVersion{}
+	for rows.Next() {
+		var version models.JobVersion
+		var id, versionJobID string
+		var changes, job []byte
+		if err := rows.Scan(&id, &versionJobID, &version.URL, &version.Version, &version.ReplacedAt, &changes, &job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		version.ID, _ = primitive.ObjectIDFromHex(id)
+		version.JobID, _ = primitive.ObjectIDFromHex(versionJobID)
+		if err := fromJSON(changes, &version.Changes); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		if err := fromJSON(job, &version.Job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch job versions", err)
+	}
+	return versions, nil
+}
+
+// replaceJob overwrites a stored job with a new scrape of the same posting. If the
+// content changed, the stored job is kept as prior version together with the diff.
+// The stored job must be locked by the transaction.
+func replaceJob(ctx context.Context, tx pgx.Tx, existing, job models.Job) error {
+	job.ID = existing.ID
+	// Ein Repost derselben URL ist kein Duplikat von sich selbst
+	if job.DuplicateOf != nil && *job.DuplicateOf == existing.ID {
+		job.DuplicateOf = nil
+	}
+
+	changes, err := storage.DiffJobs(existing, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to compare job versions", err)
+	}
+	if len(changes) == 0 {
+		// Ohne neue Version werden nur Felder wie lastSeenAt, duplicateOf und source übernommen
+		if _, err := updateJob(ctx, tx, job); err != nil {
+			return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+		}
+		return nil
+	}
+
+	changesJSON, err := toJSON(changes)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+	jobJSON, err := toJSON(existing)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO job_versions (id, job_id, url, version, replaced_at, changes, job)
+		SELECT $1::text, $2::text, $3::text, COALESCE(MAX(version), 0) + 1, now(), $4::jsonb, $5::jsonb
+		FROM job_versions WHERE job_id = $2`,
+		primitive.NewObjectID().Hex(), existing.ID.Hex(), existing.URL, changesJSON, jobJSON,
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+
+	if _, err := updateJob(ctx, tx, job); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	return nil
+}
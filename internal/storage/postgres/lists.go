@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+
+	"job-scraper/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// jobList describes a list of a job stored in a join table. The names are kept in
+// a lookup table, so each category, skill and benefit is stored once.
+type jobList struct {
+	lookup string // table of the names
+	join   string // table linking jobs and names
+	key    string // column of join referencing lookup
+	// required tells the required and optional skills in job_skills apart
+	required *bool
+}
+
+var (
+	required, optional = true, false
+
+	categoryList      = jobList{lookup: "categories", join: "job_categories", key: "category_id"}
+	mustSkillList     = jobList{lookup: "skills", join: "job_skills", key: "skill_id", required: &required}
+	optionalSkillList = jobList{lookup: "skills", join: "job_skills", key: "skill_id", required: &optional}
+	benefitList       = jobList{lookup: "benefits", join: "job_benefits", key: "benefit_id"}
+)
+
+// saveJobLists replaces the lists of a job in the join tables and updates its search column
+func saveJobLists(ctx context.Context, tx pgx.Tx, job models.Job) error {
+	id := job.ID.Hex()
+	for _, list := range []struct {
+		list  jobList
+		names []string
+	}{
+		{categoryList, job.JobCategories},
+		{mustSkillList, job.MustSkills},
+		{optionalSkillList, job.OptionalSkills},
+		{benefitList, job.Benefits},
+	} {
+		if err := saveList(ctx, tx, id, list.list, list.names); err != nil {
+			return err
+		}
+	}
+	return updateSearch(ctx, tx, id)
+}
+
+func saveList(ctx context.Context, tx pgx.Tx, jobID string, list jobList, names []string) error {
+	// Positionen erhalten die Reihenfolge der Liste
+	args := []interface{}{jobID}
+	condition := ""
+	if list.required != nil {
+		args = append(args, *list.required)
+		condition = " AND required = $2"
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM "+list.join+" WHERE job_id = $1"+condition, args...); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// Sortiert einfügen, damit gleichzeitige Transaktionen nicht gegenseitig warten
+	_, err := tx.Exec(ctx,
+		"INSERT INTO "+list.lookup+" (name) SELECT DISTINCT name FROM unnest($1::text[]) AS t (name) ORDER BY name ON CONFLICT (name) DO NOTHING",
+		names,
+	)
+	if err != nil {
+		return err
+	}
+
+	columns, values := "job_id, "+list.key+", position", "$1::text, l.id, t.position"
+	if list.required != nil {
+		columns, values = columns+", required", values+", $3::boolean"
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO `+list.join+` (`+columns+`)
+		SELECT `+values+`
+		FROM unnest($2::text[]) WITH ORDINALITY AS t (name, position)
+		JOIN `+list.lookup+` l ON l.name = t.name`,
+		append([]interface{}{jobID, names}, args[1:]...)...,
+	)
+	return err
+}
+
+// updateSearch computes the full-text search column of a job. The weights match
+// the MongoDB text index: title, required skills, optional skills with company
+// and location, description.
+func updateSearch(ctx context.Context, tx pgx.Tx, jobID string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE jobs j SET search =
+			setweight(to_tsvector('simple', j.title), 'A') ||
+			setweight(to_tsvector('simple', coalesce(array_to_string(d.must_skills, ' '), '')), 'B') ||
+			setweight(to_tsvector('simple', concat_ws(' ', array_to_string(d.optional_skills, ' '), j.company, j.location)), 'C') ||
+			setweight(to_tsvector('simple', j.description), 'D')
+		FROM job_details d
+		WHERE j.id = $1 AND d.id = j.id`, jobID)
+	return err
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// migrationLock is the advisory lock key held while a migration is applied, so that
+// instances starting at the same time apply every step once
+const migrationLock = 4200451
+
+// Migration ist ein versionierter Schritt des Datenbankschemas
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, tx pgx.Tx) error
+}
+
+// migrations are applied in version order. New steps are appended with the next
+// version number, applied steps are never changed.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create jobs with categories, skills and benefits in join tables",
+		Up: execAll(
+			`CREATE TABLE jobs (
+				id                  TEXT PRIMARY KEY,
+				url                 TEXT NOT NULL UNIQUE,
+				title               TEXT NOT NULL DEFAULT '',
+				description         TEXT NOT NULL DEFAULT '',
+				company             TEXT NOT NULL DEFAULT '',
+				location            TEXT NOT NULL DEFAULT '',
+				locations           JSONB,
+				employment_type     TEXT NOT NULL DEFAULT '',
+				posting_date        TIMESTAMPTZ NOT NULL,
+				expiration_date     TIMESTAMPTZ NOT NULL,
+				is_active           BOOLEAN NOT NULL DEFAULT false,
+				salary              JSONB,
+				years_of_experience INTEGER NOT NULL DEFAULT 0,
+				education_level     TEXT NOT NULL DEFAULT '',
+				company_size        INTEGER NOT NULL DEFAULT 0,
+				work_culture        TEXT NOT NULL DEFAULT '',
+				remote              BOOLEAN NOT NULL DEFAULT false,
+				languages           TEXT[],
+				posting_language    TEXT NOT NULL DEFAULT '',
+				prompt_version      TEXT NOT NULL DEFAULT '',
+				extraction_method   TEXT NOT NULL DEFAULT '',
+				provider            TEXT NOT NULL DEFAULT '',
+				skill_taxonomy      TEXT NOT NULL DEFAULT '',
+				fingerprint         TEXT NOT NULL DEFAULT '',
+				duplicate_of        TEXT REFERENCES jobs (id) ON DELETE SET NULL,
+				source              JSONB,
+				created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+				search              TSVECTOR
+			)`,
+			`CREATE INDEX jobs_posting_date ON jobs (posting_date)`,
+			`CREATE INDEX jobs_company ON jobs (lower(company))`,
+			`CREATE INDEX jobs_posting_language ON jobs (posting_language)`,
+			`CREATE INDEX jobs_created_at ON jobs (created_at)`,
+			`CREATE INDEX jobs_search ON jobs USING GIN (search)`,
+
+			`CREATE TABLE categories (id SERIAL PRIMARY KEY, name TEXT NOT NULL UNIQUE)`,
+			`CREATE TABLE job_categories (
+				job_id      TEXT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				category_id INTEGER NOT NULL REFERENCES categories (id),
+				position    INTEGER NOT NULL,
+				PRIMARY KEY (job_id, position)
+			)`,
+			`CREATE INDEX job_categories_category ON job_categories (category_id)`,
+
+			`CREATE TABLE skills (id SERIAL PRIMARY KEY, name TEXT NOT NULL UNIQUE)`,
+			`CREATE INDEX skills_name ON skills (lower(name))`,
+			`CREATE TABLE job_skills (
+				job_id   TEXT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				skill_id INTEGER NOT NULL REFERENCES skills (id),
+				required BOOLEAN NOT NULL,
+				position INTEGER NOT NULL,
+				PRIMARY KEY (job_id, required, position)
+			)`,
+			`CREATE INDEX job_skills_skill ON job_skills (skill_id, required)`,
+
+			`CREATE TABLE benefits (id SERIAL PRIMARY KEY, name TEXT NOT NULL UNIQUE)`,
+			`CREATE TABLE job_benefits (
+				job_id     TEXT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				benefit_id INTEGER NOT NULL REFERENCES benefits (id),
+				position   INTEGER NOT NULL,
+				PRIMARY KEY (job_id, position)
+			)`,
+			`CREATE INDEX job_benefits_benefit ON job_benefits (benefit_id)`,
+
+			`CREATE TABLE job_versions (
+				id          TEXT PRIMARY KEY,
+				job_id      TEXT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				url         TEXT NOT NULL,
+				version     INTEGER NOT NULL,
+				replaced_at TIMESTAMPTZ NOT NULL,
+				changes     JSONB,
+				job         JSONB NOT NULL,
+				UNIQUE (job_id, version)
+			)`,
+
+			`CREATE TABLE failed_jobs (
+				url             TEXT PRIMARY KEY,
+				description     TEXT NOT NULL DEFAULT '',
+				source          JSONB,
+				error           TEXT NOT NULL DEFAULT '',
+				attempts        INTEGER NOT NULL DEFAULT 0,
+				first_failed_at TIMESTAMPTZ NOT NULL,
+				last_failed_at  TIMESTAMPTZ NOT NULL
+			)`,
+
+			`CREATE TABLE queued_jobs (
+				url       TEXT PRIMARY KEY,
+				job       JSONB NOT NULL,
+				source    JSONB,
+				queued_at TIMESTAMPTZ NOT NULL
+			)`,
+		),
+	},
+	{
+		Version:     2,
+		Description: "create job_details view with the lists of each job",
+		// Die Ansicht liefert Jobs wie in der API, für Abfragen mit SQL
+		Up: execAll(jobDetailsView),
+	},
+	{
+		Version:     3,
+		Description: "add closed and last seen time of jobs",
+		// j.* wird beim Anlegen der Ansicht aufgelöst, sie muss für neue Spalten neu erstellt werden
+		Up: execAll(
+			`ALTER TABLE jobs ADD COLUMN closed_at TIMESTAMPTZ, ADD COLUMN last_seen_at TIMESTAMPTZ`,
+			`CREATE INDEX jobs_closed_at ON jobs (closed_at)`,
+			`DROP VIEW job_details`,
+			jobDetailsView,
+		),
+	},
+}
+
+// jobDetailsView creates the job_details view with the lists of each job
+const jobDetailsView = `CREATE VIEW job_details AS
+	SELECT j.*,
+		ARRAY(SELECT c.name FROM job_categories jc JOIN categories c ON c.id = jc.category_id
+			WHERE jc.job_id = j.id ORDER BY jc.position) AS job_categories,
+		ARRAY(SELECT s.name FROM job_skills js JOIN skills s ON s.id = js.skill_id
+			WHERE js.job_id = j.id AND js.required ORDER BY js.position) AS must_skills,
+		ARRAY(SELECT s.name FROM job_skills js JOIN skills s ON s.id = js.skill_id
+			WHERE js.job_id = j.id AND NOT js.required ORDER BY js.position) AS optional_skills,
+		ARRAY(SELECT b.name FROM job_benefits jb JOIN benefits b ON b.id = jb.benefit_id
+			WHERE jb.job_id = j.id ORDER BY jb.position) AS benefits
+	FROM jobs j`
+
+func execAll(statements ...string) func(ctx context.Context, tx pgx.Tx) error {
+	return func(ctx context.Context, tx pgx.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Migrate applies the migrations that have not been applied yet, in version order.
+// It stops at the first failing step and returns the steps applied before.
+func (c *Client) Migrate(ctx context.Context) ([]storage.MigrationStatus, error) {
+	_, err := c.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version     INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at  TIMESTAMPTZ NOT NULL
+		)`)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to create schema_migrations", err)
+	}
+
+	var result []storage.MigrationStatus
+	for _, migration := range migrations {
+		start := time.Now()
+		status, applied, err := c.apply(ctx, migration)
+		if err != nil {
+			return result, apperrors.NewBaseError(
+				apperrors.ErrCodeStorage,
+				fmt.Sprintf("migration %d (%s) failed", migration.Version, migration.Description),
+				err,
+			)
+		}
+		if !applied {
+			continue
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("description", migration.Description).
+			Dur("duration", time.Since(start)).
+			Msg("Applied schema migration")
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// apply runs a migration unless it has been applied already, which is checked
+// while holding the migration lock
+func (c *Client) apply(ctx context.Context, migration Migration) (storage.MigrationStatus, bool, error) {
+	status := storage.MigrationStatus{
+		Version:     migration.Version,
+		Description: migration.Description,
+		Applied:     true,
+		AppliedAt:   time.Now(),
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return status, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLock); err != nil {
+		return status, false, err
+	}
+	var done bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", migration.Version).Scan(&done)
+	if err != nil || done {
+		return status, false, err
+	}
+
+	if err := migration.Up(ctx, tx); err != nil {
+		return status, false, err
+	}
+	_, err = tx.Exec(ctx,
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)",
+		status.Version, status.Description, status.AppliedAt,
+	)
+	if err != nil {
+		return status, false, err
+	}
+	return status, true, tx.Commit(ctx)
+}
+
+// MigrationStatus returns all known migrations and whether they have been applied
+func (c *Client) MigrationStatus(ctx context.Context) ([]storage.MigrationStatus, error) {
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := storage.MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// appliedMigrations returns the recorded migrations, none if the table does not exist yet
+func (c *Client) appliedMigrations(ctx context.Context) (map[int]storage.MigrationStatus, error) {
+	var exists bool
+	if err := c.pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil || !exists {
+		return map[int]storage.MigrationStatus{}, err
+	}
+
+	rows, err := c.pool.Query(ctx, "SELECT version, description, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to read schema migrations", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]storage.MigrationStatus)
+	for rows.Next() {
+		var record storage.MigrationStatus
+		if err := rows.Scan(&record.Version, &record.Description, &record.AppliedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to read schema migrations", err)
+		}
+		record.Applied = true
+		applied[record.Version] = record
+	}
+	return applied, rows.Err()
+}
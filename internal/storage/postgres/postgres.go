@@ -0,0 +1,548 @@
+// Package postgres stores jobs in PostgreSQL. Categories, skills and benefits are
+// normalized into join tables, so jobs can be analysed with plain SQL.
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// jobColumns are the columns of the job_details view in the order of scanJob
+const jobColumns = `j.id, j.url, j.title, j.description, j.company, j.location, j.locations, j.employment_type,
+	j.posting_date, j.expiration_date, j.is_active, j.job_categories, j.must_skills, j.optional_skills, j.salary,
+	j.years_of_experience, j.education_level, j.benefits, j.company_size, j.work_culture, j.remote, j.languages,
+	j.posting_language, j.prompt_version, j.extraction_method, j.provider, j.skill_taxonomy, j.fingerprint,
+	j.duplicate_of, j.source, j.closed_at, j.last_seen_at`
+
+// tableColumns are the columns of the jobs table in the order of jobValues
+const tableColumns = `id, url, title, description, company, location, locations, employment_type,
+	posting_date, expiration_date, is_active, salary, years_of_experience, education_level, company_size,
+	work_culture, remote, languages, posting_language, prompt_version, extraction_method, provider,
+	skill_taxonomy, fingerprint, duplicate_of, source, closed_at, last_seen_at`
+
+type Client struct {
+	pool *pgxpool.Pool
+}
+
+// NewClient connects to the database at uri. maxConns limits the connection pool,
+// 0 keeps the pgx default. The schema is created by Migrate.
+func NewClient(ctx context.Context, uri string, maxConns int) (*Client, error) {
+	config, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres URI: %w", err)
+	}
+	if maxConns > 0 {
+		config.MaxConns = int32(maxConns)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return &Client{pool: pool}, nil
+}
+
+func (c *Client) GetJobs(ctx context.Context) ([]models.Job, error) {
+	return c.queryJobs(ctx, "SELECT "+jobColumns+" FROM job_details j ORDER BY j.created_at, j.id")
+}
+
+func (c *Client) GetJobByID(ctx context.Context, id string) (*models.Job, error) {
+	job, err := scanJob(c.pool.QueryRow(ctx, "SELECT "+jobColumns+" FROM job_details j WHERE j.id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("Job", id)
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "database error", err)
+	}
+	return &job, nil
+}
+
+// GetJobByURL returns the job stored for a posting URL
+func (c *Client) GetJobByURL(ctx context.Context, url string) (*models.Job, error) {
+	job, err := findJobByURL(ctx, c.pool, url)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, apperrors.NewNotFoundError("Job", url)
+	}
+	return job, nil
+}
+
+// SaveJob inserts a job or, if a job with the same URL is already stored, replaces it.
+// Prior versions of changed postings are kept in the job_versions table.
+func (c *Client) SaveJob(ctx context.Context, job models.Job) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	defer tx.Rollback(ctx)
+
+	existing, err := lockJobByURL(ctx, tx, job.URL)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		inserted, err := insertJob(ctx, tx, job)
+		if err != nil {
+			return err
+		}
+		// Eine andere Instanz hat dieselbe URL gleichzeitig gespeichert
+		if !inserted {
+			if existing, err = lockJobByURL(ctx, tx, job.URL); err != nil {
+				return err
+			}
+		}
+	}
+	if existing != nil {
+		if err := replaceJob(ctx, tx, *existing, job); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	return nil
+}
+
+func (c *Client) FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	w := &where{}
+	addJobFilter(w, filter)
+	return c.queryJobs(ctx, "SELECT "+jobColumns+" FROM job_details j"+w.String()+" ORDER BY j.created_at, j.id", w.args...)
+}
+
+func (c *Client) UpdateJob(ctx context.Context, job models.Job) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updated, err := updateJob(ctx, tx, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	if !updated {
+		return apperrors.NewNotFoundError("Job", job.ID.Hex())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	return nil
+}
+
+func (c *Client) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	source, err := toJSON(job.Source)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save failed job", err)
+	}
+	_, err = c.pool.Exec(ctx, `
+		INSERT INTO failed_jobs (url, description, source, error, attempts, first_failed_at, last_failed_at)
+		VALUES ($1, $2, $3, $4, 1, now(), now())
+		ON CONFLICT (url) DO UPDATE SET
+			description = excluded.description,
+			source = excluded.source,
+			error = excluded.error,
+			attempts = failed_jobs.attempts + 1,
+			last_failed_at = excluded.last_failed_at`,
+		job.URL, job.Description, source, job.Error,
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save failed job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT url, description, source, error, attempts, first_failed_at, last_failed_at
+		FROM failed_jobs ORDER BY first_failed_at`)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch failed jobs", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.FailedJob
+	for rows.Next() {
+		var job models.FailedJob
+		var source []byte
+		if err := rows.Scan(&job.URL, &job.Description, &source, &job.Error, &job.Attempts, &job.FirstFailedAt, &job.LastFailedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode failed jobs", err)
+		}
+		if err := fromJSON(source, &job.Source); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode failed jobs", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch failed jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteFailedJob(ctx context.Context, url string) error {
+	if _, err := c.pool.Exec(ctx, "DELETE FROM failed_jobs WHERE url = $1", url); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete failed job", err)
+	}
+	return nil
+}
+
+// QueueJob stores a job waiting for the LLM budget. A job queued again keeps its queue time.
+// The raw payload is kept in its own column, as for failed jobs.
+func (c *Client) QueueJob(ctx context.Context, job models.Job) error {
+	source, err := toJSON(job.Source)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	data, err := toJSON(job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	_, err = c.pool.Exec(ctx, `
+		INSERT INTO queued_jobs (url, job, source, queued_at) VALUES ($1, $2, $3, now())
+		ON CONFLICT (url) DO UPDATE SET job = excluded.job, source = excluded.source`,
+		job.URL, data, source,
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	rows, err := c.pool.Query(ctx, "SELECT url, job, source, queued_at FROM queued_jobs ORDER BY queued_at")
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch queued jobs", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.QueuedJob
+	for rows.Next() {
+		var queued models.QueuedJob
+		var job, source []byte
+		if err := rows.Scan(&queued.URL, &job, &source, &queued.QueuedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		if err := fromJSON(job, &queued.Job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		if err := fromJSON(source, &queued.Job.Source); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		jobs = append(jobs, queued)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch queued jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteQueuedJob(ctx context.Context, url string) error {
+	if _, err := c.pool.Exec(ctx, "DELETE FROM queued_jobs WHERE url = $1", url); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete queued job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetJobCountByCategory(ctx context.Context) (map[string]int, error) {
+	return c.countValues(ctx, `
+		SELECT c.name, COUNT(*)
+		FROM job_categories jc JOIN categories c ON c.id = jc.category_id
+		GROUP BY c.name`)
+}
+
+// GetSkillCounts returns the number of jobs per skill, required and optional skills combined
+func (c *Client) GetSkillCounts(ctx context.Context) (map[string]int, error) {
+	return c.countValues(ctx, `
+		SELECT s.name, COUNT(DISTINCT js.job_id)
+		FROM job_skills js JOIN skills s ON s.id = js.skill_id
+		GROUP BY s.name`)
+}
+
+func (c *Client) GetTotalJobCount(ctx context.Context) (int, error) {
+	var count int
+	err := c.pool.QueryRow(ctx, "SELECT COUNT(*) FROM jobs").Scan(&count)
+	return count, err
+}
+
+func (c *Client) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
+	rows, err := c.pool.Query(ctx, "SELECT url FROM jobs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls[url] = true
+	}
+	return urls, rows.Err()
+}
+
+// GetFingerprints returns the fingerprints of the original jobs stored since the given time.
+// Jobs marked as duplicates are left out, so new duplicates link to the original.
+func (c *Client) GetFingerprints(ctx context.Context, since time.Time) ([]storage.JobFingerprint, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, fingerprint FROM jobs
+		WHERE fingerprint <> '' AND duplicate_of IS NULL AND created_at >= $1
+		ORDER BY created_at, id`, since)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch fingerprints", err)
+	}
+	defer rows.Close()
+
+	fingerprints := []storage.JobFingerprint{}
+	for rows.Next() {
+		var id, fingerprint string
+		if err := rows.Scan(&id, &fingerprint); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode fingerprints", err)
+		}
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode fingerprints", err)
+		}
+		fingerprints = append(fingerprints, storage.JobFingerprint{ID: objectID, Fingerprint: fingerprint})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch fingerprints", err)
+	}
+	return fingerprints, nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	c.pool.Close()
+	return nil
+}
+
+// queryer is implemented by *pgxpool.Pool and pgx.Tx
+type queryer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func (c *Client) queryJobs(ctx context.Context, query string, args ...interface{}) ([]models.Job, error) {
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch jobs", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode jobs", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) countValues(ctx context.Context, query string) (map[string]int, error) {
+	rows, err := c.pool.Query(ctx, query)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count jobs", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode counts", err)
+		}
+		counts[value] = count
+	}
+	return counts, rows.Err()
+}
+
+// findJobByURL returns nil if no job is stored for the URL
+func findJobByURL(ctx context.Context, q queryer, url string) (*models.Job, error) {
+	job, err := scanJob(q.QueryRow(ctx, "SELECT "+jobColumns+" FROM job_details j WHERE j.url = $1", url))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to find job by URL", err)
+	}
+	return &job, nil
+}
+
+// lockJobByURL returns the job stored for the URL and locks it until the end of the
+// transaction, nil if no job is stored
+func lockJobByURL(ctx context.Context, tx pgx.Tx, url string) (*models.Job, error) {
+	var id string
+	err := tx.QueryRow(ctx, "SELECT id FROM jobs WHERE url = $1 FOR UPDATE", url).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to find job by URL", err)
+	}
+	return findJobByURL(ctx, tx, url)
+}
+
+// insertJob inserts a new job, or reports false if a job with the same URL exists
+func insertJob(ctx context.Context, tx pgx.Tx, job models.Job) (bool, error) {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	values, err := jobValues(job)
+	if err != nil {
+		return false, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		"INSERT INTO jobs ("+tableColumns+") VALUES ("+placeholders(1, len(values))+") ON CONFLICT (url) DO NOTHING",
+		values...,
+	)
+	if err != nil {
+		return false, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := saveJobLists(ctx, tx, job); err != nil {
+		return false, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	return true, nil
+}
+
+// updateJob overwrites the job with the same ID and reports whether it exists
+func updateJob(ctx context.Context, tx pgx.Tx, job models.Job) (bool, error) {
+	values, err := jobValues(job)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE jobs SET ("+tableColumns+") = ROW("+placeholders(1, len(values))+") WHERE id = $1",
+		values...,
+	)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+	return true, saveJobLists(ctx, tx, job)
+}
+
+// jobValues returns the column values of a job in the order of tableColumns
+func jobValues(job models.Job) ([]interface{}, error) {
+	encoded := make([]interface{}, 0, 3)
+	for _, value := range []interface{}{job.Locations, job.Salary, job.Source} {
+		column, err := toJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, column)
+	}
+	locations, salary, source := encoded[0], encoded[1], encoded[2]
+
+	var duplicateOf *string
+	if job.DuplicateOf != nil {
+		original := job.DuplicateOf.Hex()
+		duplicateOf = &original
+	}
+
+	return []interface{}{
+		job.ID.Hex(), job.URL, job.Title, job.Description, job.Company, job.Location, locations, job.EmploymentType,
+		job.PostingDate, job.ExpirationDate, job.IsActive, salary, job.YearsOfExperience, job.EducationLevel, job.CompanySize,
+		job.WorkCulture, job.Remote, job.Languages, job.PostingLanguage, job.PromptVersion, job.ExtractionMethod, job.Provider,
+		job.SkillTaxonomy, job.Fingerprint, duplicateOf, source, job.ClosedAt, job.LastSeenAt,
+	}, nil
+}
+
+// scanJob reads the columns of jobColumns and, if given, further columns into extra
+func scanJob(row pgx.Row, extra ...interface{}) (models.Job, error) {
+	var job models.Job
+	var id string
+	var duplicateOf *string
+	var locations, salary, source []byte
+
+	dest := []interface{}{
+		&id, &job.URL, &job.Title, &job.Description, &job.Company, &job.Location, &locations, &job.EmploymentType,
+		&job.PostingDate, &job.ExpirationDate, &job.IsActive, &job.JobCategories, &job.MustSkills, &job.OptionalSkills, &salary,
+		&job.YearsOfExperience, &job.EducationLevel, &job.Benefits, &job.CompanySize, &job.WorkCulture, &job.Remote, &job.Languages,
+		&job.PostingLanguage, &job.PromptVersion, &job.ExtractionMethod, &job.Provider, &job.SkillTaxonomy, &job.Fingerprint,
+		&duplicateOf, &source, &job.ClosedAt, &job.LastSeenAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return job, err
+	}
+
+	var err error
+	if job.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return job, err
+	}
+	if duplicateOf != nil {
+		original, err := primitive.ObjectIDFromHex(*duplicateOf)
+		if err != nil {
+			return job, err
+		}
+		job.DuplicateOf = &original
+	}
+	job.PostingDate = job.PostingDate.UTC()
+	job.ExpirationDate = job.ExpirationDate.UTC()
+	for _, t := range []*time.Time{job.ClosedAt, job.LastSeenAt} {
+		if t != nil {
+			*t = t.UTC()
+		}
+	}
+
+	for _, column := range []struct {
+		value  []byte
+		target interface{}
+	}{
+		{locations, &job.Locations}, {salary, &job.Salary}, {source, &job.Source},
+	} {
+		if err := fromJSON(column.value, column.target); err != nil {
+			return job, err
+		}
+	}
+	return job, nil
+}
+
+// toJSON encodes a value for a JSONB column. Nil slices and pointers are stored as NULL.
+func toJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+func fromJSON(column []byte, target interface{}) error {
+	if column == nil {
+		return nil
+	}
+	return json.Unmarshal(column, target)
+}
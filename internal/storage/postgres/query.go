@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+)
+
+// sortColumns maps the sort fields of storage.JobQuery to columns
+var sortColumns = map[string]string{
+	storage.SortPostingDate:       "posting_date",
+	storage.SortTitle:             "title",
+	storage.SortCompany:           "company",
+	storage.SortYearsOfExperience: "years_of_experience",
+}
+
+// pageCursor ist die Position nach dem letzten Job einer Seite
+type pageCursor struct {
+	SortBy string      `json:"s"`
+	Value  interface{} `json:"v"`
+	ID     string      `json:"id"`
+}
+
+// where collects the conditions of a query on jobs aliased as j. Arguments are
+// numbered after those already in args.
+type where struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition with ? placeholders for the arguments
+func (w *where) add(condition string, args ...interface{}) {
+	for _, arg := range args {
+		condition = strings.Replace(condition, "?", w.arg(arg), 1)
+	}
+	w.conditions = append(w.conditions, condition)
+}
+
+// arg appends an argument and returns its placeholder
+func (w *where) arg(value interface{}) string {
+	w.args = append(w.args, value)
+	return "$" + strconv.Itoa(len(w.args))
+}
+
+func (w *where) String() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// QueryJobs returns a page of the jobs matching the query. Pages are continued with
+// the cursor of the previous page, which stays stable while new jobs are stored.
+func (c *Client) QueryJobs(ctx context.Context, query storage.JobQuery) (storage.JobPage, error) {
+	page := storage.JobPage{Jobs: []models.Job{}}
+
+	sortBy, err := query.SortField()
+	if err != nil {
+		return page, err
+	}
+	column := sortColumns[sortBy]
+	direction, op := "ASC", ">"
+	if query.SortDescending {
+		direction, op = "DESC", "<"
+	}
+	limit := query.PageSize()
+
+	w := &where{}
+	addJobQuery(w, query)
+	if err := c.pool.QueryRow(ctx, "SELECT COUNT(*) FROM jobs j"+w.String(), w.args...).Scan(&page.Total); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count jobs", err)
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, sortBy)
+		if err != nil {
+			return page, err
+		}
+		value, err := cursorValue(cursor)
+		if err != nil {
+			return page, err
+		}
+		// Jobs mit gleichem Sortierwert sind nach ID geordnet
+		w.add("(j."+column+", j.id) "+op+" (?, ?)", value, cursor.ID)
+	}
+
+	// Ein zusätzlicher Job zeigt an, ob es eine weitere Seite gibt
+	jobs, err := c.queryJobs(ctx,
+		"SELECT "+jobColumns+" FROM job_details j"+w.String()+
+			" ORDER BY j."+column+" "+direction+", j.id "+direction+" LIMIT "+w.arg(limit+1),
+		w.args...,
+	)
+	if err != nil {
+		return page, err
+	}
+	page.Jobs = jobs
+
+	if len(page.Jobs) > limit {
+		page.Jobs = page.Jobs[:limit]
+		if page.NextCursor, err = encodeCursor(page.Jobs[limit-1], sortBy); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// addJobFilter adds the conditions of a storage.JobFilter
+func addJobFilter(w *where, filter storage.JobFilter) {
+	if !filter.PostedFrom.IsZero() {
+		w.add("j.posting_date >= ?", filter.PostedFrom)
+	}
+	if !filter.PostedTo.IsZero() {
+		w.add("j.posting_date < ?", filter.PostedTo)
+	}
+	if filter.Category != "" {
+		w.add(`EXISTS (SELECT 1 FROM job_categories jc JOIN categories c ON c.id = jc.category_id
+			WHERE jc.job_id = j.id AND c.name = ?)`, filter.Category)
+	}
+	if filter.PromptVersion != "" {
+		w.add("j.prompt_version = ?", filter.PromptVersion)
+	}
+	if filter.ExtractionMethod != "" {
+		w.add("j.extraction_method = ?", filter.ExtractionMethod)
+	}
+	if filter.PostingLanguage != "" {
+		w.add("j.posting_language = ?", filter.PostingLanguage)
+	}
+	if filter.OriginalsOnly {
+		w.add("j.duplicate_of IS NULL")
+	}
+	if filter.OpenOnly {
+		w.add("j.closed_at IS NULL")
+	}
+	if filter.ClosedOnly {
+		w.add("j.closed_at IS NOT NULL")
+	}
+}
+
+// addJobQuery adds the filter fields of a storage.JobQuery. Like in MongoDB, text
+// is compared ignoring case.
+func addJobQuery(w *where, query storage.JobQuery) {
+	if query.Category != "" {
+		w.add(`EXISTS (SELECT 1 FROM job_categories jc JOIN categories c ON c.id = jc.category_id
+			WHERE jc.job_id = j.id AND c.name = ?)`, strings.ToUpper(strings.TrimSpace(query.Category)))
+	}
+	if query.Skill != "" {
+		condition := `EXISTS (SELECT 1 FROM job_skills js JOIN skills s ON s.id = js.skill_id
+			WHERE js.job_id = j.id AND lower(s.name) = lower(?)`
+		switch query.SkillType {
+		case storage.SkillTypeMust:
+			condition += " AND js.required"
+		case storage.SkillTypeOptional:
+			condition += " AND NOT js.required"
+		}
+		w.add(condition+")", strings.TrimSpace(query.Skill))
+	}
+	if query.Company != "" {
+		w.add("strpos(lower(j.company), lower(?)) > 0", strings.TrimSpace(query.Company))
+	}
+	if query.Location != "" {
+		location := strings.TrimSpace(query.Location)
+		w.add(`(strpos(lower(j.location), lower(?)) > 0 OR EXISTS (
+			SELECT 1 FROM jsonb_array_elements(COALESCE(j.locations, '[]')) AS p (place)
+			WHERE lower(p.place->>'city') = lower(?) OR p.place->>'canton' = ?))`,
+			location, location, strings.ToUpper(location))
+	}
+	if query.Remote != nil {
+		w.add("j.remote = ?", *query.Remote)
+	}
+	if query.EmploymentType != "" {
+		w.add("lower(j.employment_type) = lower(?)", strings.TrimSpace(query.EmploymentType))
+	}
+	if !query.PostedFrom.IsZero() {
+		w.add("j.posting_date >= ?", query.PostedFrom)
+	}
+	if !query.PostedTo.IsZero() {
+		w.add("j.posting_date < ?", query.PostedTo)
+	}
+	if query.MinExperience > 0 {
+		w.add("j.years_of_experience >= ?", query.MinExperience)
+	}
+}
+
+// placeholders returns n numbered placeholders starting at $first
+func placeholders(first, n int) string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = "$" + strconv.Itoa(first+i)
+	}
+	return strings.Join(list, ", ")
+}
+
+func encodeCursor(job models.Job, sortBy string) (string, error) {
+	cursor := pageCursor{SortBy: sortBy, ID: job.ID.Hex()}
+	switch sortBy {
+	case storage.SortPostingDate:
+		cursor.Value = job.PostingDate.UTC().Format(time.RFC3339Nano)
+	case storage.SortTitle:
+		cursor.Value = job.Title
+	case storage.SortCompany:
+		cursor.Value = job.Company
+	case storage.SortYearsOfExperience:
+		cursor.Value = job.YearsOfExperience
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value, sortBy string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	if cursor.SortBy != sortBy {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "page cursor belongs to a different sort order", nil)
+	}
+	return cursor, nil
+}
+
+// cursorValue converts the JSON value of a cursor to the type of its sort column
+func cursorValue(cursor pageCursor) (interface{}, error) {
+	invalid := apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", nil)
+	switch cursor.SortBy {
+	case storage.SortPostingDate:
+		text, _ := cursor.Value.(string)
+		t, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return nil, invalid
+		}
+		return t, nil
+	case storage.SortYearsOfExperience:
+		number, ok := cursor.Value.(float64)
+		if !ok {
+			return nil, invalid
+		}
+		return int(number), nil
+	default:
+		text, ok := cursor.Value.(string)
+		if !ok {
+			return nil, invalid
+		}
+		return text, nil
+	}
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWhereNumbersPlaceholders(t *testing.T) {
+	remote := true
+	w := where{}
+	addJobFilter(&w, storage.JobFilter{PostingLanguage: "de", OriginalsOnly: true})
+	addJobQuery(&w, storage.JobQuery{Company: " Example ", Remote: &remote, MinExperience: 3})
+
+	assert.Equal(t, " WHERE j.posting_language = $1 AND j.duplicate_of IS NULL AND "+
+		"strpos(lower(j.company), lower($2)) > 0 AND j.remote = $3 AND j.years_of_experience >= $4", w.String())
+	assert.Equal(t, []interface{}{"de", "Example", true, 3}, w.args)
+	assert.Equal(t, "$5", w.arg(10))
+
+	assert.Empty(t, (&where{}).String())
+}
+
+func TestTsQuery(t *testing.T) {
+	w := where{}
+	query := tsQuery(&w, storage.ParseSearchText(`golang kubernetes -java`))
+	assert.Equal(t, "(plainto_tsquery('simple', $1) || plainto_tsquery('simple', $2)) && !! phraseto_tsquery('simple', $3)", query)
+	assert.Equal(t, []interface{}{"golang", "kubernetes", "java"}, w.args)
+
+	w = where{}
+	query = tsQuery(&w, storage.ParseSearchText(`"machine learning" python`))
+	assert.Equal(t, "(phraseto_tsquery('simple', $1))", query)
+	assert.Equal(t, []interface{}{"machine learning"}, w.args)
+}
+
+func TestPageCursor(t *testing.T) {
+	job := models.Job{ID: primitive.NewObjectID(), YearsOfExperience: 4, PostingDate: time.Date(2024, 10, 1, 8, 30, 0, 0, time.UTC)}
+
+	encoded, err := encodeCursor(job, storage.SortPostingDate)
+	require.NoError(t, err)
+	cursor, err := decodeCursor(encoded, storage.SortPostingDate)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID.Hex(), cursor.ID)
+	value, err := cursorValue(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, job.PostingDate, value)
+
+	encoded, err = encodeCursor(job, storage.SortYearsOfExperience)
+	require.NoError(t, err)
+	cursor, err = decodeCursor(encoded, storage.SortYearsOfExperience)
+	require.NoError(t, err)
+	value, err = cursorValue(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, 4, value)
+
+	_, err = decodeCursor(encoded, storage.SortTitle)
+	assert.Error(t, err, "cursor of another sort order")
+
+	_, err = decodeCursor("not a cursor", storage.SortPostingDate)
+	assert.Error(t, err)
+}
+
+func TestStatsSQL(t *testing.T) {
+	sql, args := statsSQL(storage.StatsQuery{
+		Filter:   storage.JobFilter{PostingLanguage: "de"},
+		Where:    []storage.StatsCondition{{Dimension: storage.DimCompanySize, Values: []string{"Small"}}},
+		GroupBy:  []storage.Dimension{storage.DimMustSkill},
+		Measures: []storage.Measure{{Name: "avgExperience", Aggregate: storage.AggregateAvg, Field: storage.FieldYearsOfExperience}},
+		OrderBy:  []storage.StatsOrder{{Key: storage.CountKey, Descending: true}},
+		Limit:    10,
+	})
+
+	assert.True(t, strings.HasPrefix(sql, "SELECT k0, COUNT(*) AS count, AVG(m0)::float8 AS m0 FROM (SELECT DISTINCT j.id, d0.value AS k0, "))
+	assert.Contains(t, sql, " WHERE j.posting_language = $1 AND EXISTS (")
+	assert.Contains(t, sql, "lower(w0.value) = ANY($2))")
+	assert.True(t, strings.HasSuffix(sql, `) t GROUP BY k0 ORDER BY count DESC NULLS LAST, k0 COLLATE "C" LIMIT $3`))
+	assert.Equal(t, []interface{}{"de", []string{"small"}, 10}, args)
+
+	sql, _ = statsSQL(storage.StatsQuery{
+		GroupBy:  []storage.Dimension{storage.DimClosedDate},
+		Bucket:   storage.BucketMonth,
+		Measures: []storage.Measure{{Name: "p90Days", Aggregate: storage.AggregateP90, Field: storage.FieldLifetime}},
+	})
+	assert.True(t, strings.HasPrefix(sql, "SELECT k0, COUNT(*) AS count, percentile_cont(0.9) WITHIN GROUP (ORDER BY m0)::float8 AS m0 FROM "))
+	assert.Contains(t, sql, "to_char(j.closed_at AT TIME ZONE 'UTC', 'YYYY-MM') WHERE j.closed_at IS NOT NULL")
+
+	// Ohne Gruppierung bilden alle Jobs eine Gruppe
+	sql, args = statsSQL(storage.StatsQuery{})
+	assert.Equal(t, "SELECT COUNT(*) AS count FROM (SELECT DISTINCT j.id FROM jobs j) t HAVING COUNT(*) > 0", sql)
+	assert.Empty(t, args)
+}
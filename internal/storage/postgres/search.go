@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+)
+
+// searchWeights gewichtet die Teile der search-Spalte (D, C, B, A) wie der MongoDB-Textindex
+const searchWeights = "'{0.1, 0.3, 0.5, 1.0}'"
+
+// searchCursor ist die Anzahl Treffer auf den vorherigen Seiten
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+// SearchJobs finds jobs with the full-text index, most relevant first
+func (c *Client) SearchJobs(ctx context.Context, search storage.JobSearch) (storage.SearchPage, error) {
+	page := storage.SearchPage{Results: []storage.SearchResult{}}
+
+	if strings.TrimSpace(search.Text) == "" {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeValidation, "search text is required", nil)
+	}
+	limit := search.PageSize()
+	offset, err := decodeSearchCursor(search.Cursor)
+	if err != nil {
+		return page, err
+	}
+
+	terms := storage.ParseSearchText(search.Text)
+	if terms.Empty() {
+		// Nur ausgeschlossene Wörter finden wie in MongoDB nichts
+		return page, nil
+	}
+	w := &where{}
+	query := tsQuery(w, terms)
+	w.add("j.search @@ q.tsq")
+	addJobQuery(w, search.JobQuery)
+	from := " FROM job_details j CROSS JOIN (SELECT " + query + " AS tsq) q" + w.String()
+
+	if err := c.pool.QueryRow(ctx, "SELECT COUNT(*)"+from, w.args...).Scan(&page.Total); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count search results", err)
+	}
+
+	rows, err := c.pool.Query(ctx,
+		"SELECT "+jobColumns+", ts_rank("+searchWeights+", j.search, q.tsq)::float8 AS score"+from+
+			" ORDER BY score DESC, j.id LIMIT "+w.arg(limit)+" OFFSET "+w.arg(offset),
+		w.args...,
+	)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to search jobs", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result storage.SearchResult
+		if result.Job, err = scanJob(rows, &result.Score); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode search results", err)
+		}
+		page.Results = append(page.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to search jobs", err)
+	}
+
+	if next := offset + len(page.Results); next < page.Total {
+		if page.NextCursor, err = encodeSearchCursor(next); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// tsQuery returns a tsquery expression for the search terms and adds the terms to
+// the arguments of w. Words are or-ed unless the search has phrases, which are all
+// required. The terms must not be empty.
+func tsQuery(w *where, terms storage.SearchTerms) string {
+	var positive []string
+	operator := " || "
+	if len(terms.Phrases) > 0 {
+		for _, phrase := range terms.Phrases {
+			positive = append(positive, "phraseto_tsquery('simple', "+w.arg(phrase)+")")
+		}
+		operator = " && "
+	} else {
+		for _, word := range terms.Words {
+			positive = append(positive, "plainto_tsquery('simple', "+w.arg(word)+")")
+		}
+	}
+
+	query := "(" + strings.Join(positive, operator) + ")"
+	for _, term := range terms.Excluded {
+		query += " && !! phraseto_tsquery('simple', " + w.arg(term) + ")"
+	}
+	return query
+}
+
+func encodeSearchCursor(offset int) (string, error) {
+	data, err := json.Marshal(searchCursor{Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSearchCursor(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Offset < 0 {
+		return 0, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	return cursor.Offset, nil
+}
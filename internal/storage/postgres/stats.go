@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+)
+
+// AggregateStats runs a statistic as SQL query, see statsSQL
+func (c *Client) AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	sql, args := statsSQL(query)
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to aggregate jobs", err)
+	}
+	defer rows.Close()
+
+	results := []storage.StatsRow{}
+	for rows.Next() {
+		row := storage.StatsRow{Keys: make([]string, len(query.GroupBy)), Values: make(map[string]float64)}
+		var count int64
+		values := make([]*float64, len(query.Measures))
+		targets := make([]interface{}, 0, len(row.Keys)+1+len(values))
+		for i := range row.Keys {
+			targets = append(targets, &row.Keys[i])
+		}
+		targets = append(targets, &count)
+		for i := range values {
+			targets = append(targets, &values[i])
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode aggregation results", err)
+		}
+
+		row.Count = int(count)
+		for i, value := range values {
+			if value != nil {
+				row.Values[query.Measures[i].Name] = *value
+			}
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to aggregate jobs", err)
+	}
+	return results, nil
+}
+
+// statsSQL translates a StatsQuery. Every dimension is a lateral subquery for the
+// job's values. The inner query keeps one row per job and group, so a job counts
+// once per group and measures are not weighted by the number of values.
+func statsSQL(query storage.StatsQuery) (string, []interface{}) {
+	w := &where{}
+	addJobFilter(w, query.Filter)
+	for i, condition := range query.Where {
+		values := make([]string, 0, len(condition.Values))
+		for _, value := range condition.Values {
+			values = append(values, strings.ToLower(value))
+		}
+		w.add(fmt.Sprintf("EXISTS (SELECT 1 FROM (%s) w%d (value) WHERE lower(w%d.value) = ANY(?))",
+			dimensionSQL(condition.Dimension, query.TimeBucket()), i, i), values)
+	}
+
+	inner := []string{"j.id"}
+	from := "jobs j"
+	var keys, outer []string
+	for i, dimension := range query.GroupBy {
+		key := fmt.Sprintf("k%d", i)
+		inner = append(inner, fmt.Sprintf("d%d.value AS %s", i, key))
+		from += fmt.Sprintf(" CROSS JOIN LATERAL (%s) d%d (value)", dimensionSQL(dimension, query.TimeBucket()), i)
+		keys = append(keys, key)
+	}
+	outer = append(outer, keys...)
+	outer = append(outer, "COUNT(*) AS count")
+	for i, measure := range query.Measures {
+		inner = append(inner, fmt.Sprintf("%s AS m%d", measureSQL(measure.Field), i))
+		outer = append(outer, fmt.Sprintf("%s::float8 AS m%d", aggregateSQL(measure.Aggregate, fmt.Sprintf("m%d", i)), i))
+	}
+
+	sql := "SELECT " + strings.Join(outer, ", ") +
+		" FROM (SELECT DISTINCT " + strings.Join(inner, ", ") + " FROM " + from + w.String() + ") t"
+	if len(keys) > 0 {
+		sql += " GROUP BY " + strings.Join(keys, ", ")
+	} else {
+		// Ohne Jobs gibt es wie in MongoDB keine Gruppe
+		sql += " HAVING COUNT(*) > 0"
+	}
+	if order := statsOrder(query); order != "" {
+		sql += " ORDER BY " + order
+	}
+	if query.Limit > 0 {
+		sql += " LIMIT " + w.arg(query.Limit)
+	}
+	return sql, w.args
+}
+
+// statsOrder sorts by the OrderBy keys and then by the dimension values. Texts are
+// compared bytewise and missing values sort first, as in MongoDB.
+func statsOrder(query storage.StatsQuery) string {
+	var order []string
+	for _, o := range query.OrderBy {
+		column := o.Key
+		for i, dimension := range query.GroupBy {
+			if string(dimension) == o.Key {
+				column = fmt.Sprintf(`k%d COLLATE "C"`, i)
+			}
+		}
+		for i, measure := range query.Measures {
+			if measure.Name == o.Key {
+				column = fmt.Sprintf("m%d", i)
+			}
+		}
+		if o.Descending {
+			order = append(order, column+" DESC NULLS LAST")
+		} else {
+			order = append(order, column+" NULLS FIRST")
+		}
+	}
+	for i := range query.GroupBy {
+		order = append(order, fmt.Sprintf(`k%d COLLATE "C"`, i))
+	}
+	return strings.Join(order, ", ")
+}
+
+// dimensionSQL returns a query for the values of the job j in a dimension
+func dimensionSQL(dimension storage.Dimension, bucket storage.TimeBucket) string {
+	switch dimension {
+	case storage.DimCategory:
+		return `SELECT c.name FROM job_categories jc JOIN categories c ON c.id = jc.category_id WHERE jc.job_id = j.id`
+	case storage.DimMustSkill:
+		return `SELECT s.name FROM job_skills js JOIN skills s ON s.id = js.skill_id WHERE js.job_id = j.id AND js.required`
+	case storage.DimOptionalSkill:
+		return `SELECT s.name FROM job_skills js JOIN skills s ON s.id = js.skill_id WHERE js.job_id = j.id AND NOT js.required`
+	case storage.DimBenefit:
+		return `SELECT b.name FROM job_benefits jb JOIN benefits b ON b.id = jb.benefit_id WHERE jb.job_id = j.id`
+	case storage.DimLanguage:
+		return `SELECT unnest(j.languages)`
+	case storage.DimCompany:
+		return `SELECT j.company`
+	case storage.DimEmploymentType:
+		return `SELECT j.employment_type`
+	case storage.DimEducationLevel:
+		return `SELECT j.education_level`
+	case storage.DimRemote:
+		return `SELECT j.remote::text`
+	case storage.DimCompanySize:
+		return `SELECT CASE WHEN j.company_size < 0 THEN 'Unknown' WHEN j.company_size <= 50 THEN 'Small'
+			WHEN j.company_size <= 250 THEN 'Medium' ELSE 'Large' END`
+	case storage.DimExperience:
+		return `SELECT CASE WHEN j.years_of_experience <= 2 THEN 'Junior' WHEN j.years_of_experience <= 5 THEN 'Mid-Level'
+			ELSE 'Senior' END`
+	case storage.DimLocation:
+		return `SELECT COALESCE(NULLIF(p.place->>'city', ''), j.location)
+			FROM jsonb_array_elements(COALESCE(j.locations, '[]')) AS p (place)
+			UNION SELECT j.location WHERE COALESCE(jsonb_array_length(j.locations), 0) = 0`
+	case storage.DimCanton:
+		return `SELECT DISTINCT p.place->>'canton' FROM jsonb_array_elements(COALESCE(j.locations, '[]')) AS p (place)
+			WHERE p.place->>'canton' <> ''`
+	case storage.DimPostingDate:
+		return `SELECT ` + dateSQL("j.posting_date", bucket)
+	case storage.DimClosedDate:
+		return `SELECT ` + dateSQL("j.closed_at", bucket) + ` WHERE j.closed_at IS NOT NULL`
+	}
+	return `SELECT NULL::text WHERE false`
+}
+
+// dateSQL formats a date column in UTC, cut to the bucket
+func dateSQL(column string, bucket storage.TimeBucket) string {
+	if bucket == storage.BucketMonth {
+		return `to_char(` + column + ` AT TIME ZONE 'UTC', 'YYYY-MM')`
+	}
+	return `to_char(` + column + ` AT TIME ZONE 'UTC', 'YYYY-MM-DD')`
+}
+
+// aggregateSQL returns the aggregate of a measure column. Percentiles interpolate
+// like storage.Percentile.
+func aggregateSQL(aggregate storage.Aggregate, column string) string {
+	if aggregate.IsPercentile() {
+		return fmt.Sprintf("percentile_cont(%v) WITHIN GROUP (ORDER BY %s)", aggregate.Fraction(), column)
+	}
+	return strings.ToUpper(string(aggregate)) + "(" + column + ")"
+}
+
+// measureSQL returns an expression for the value of a measure field of the job j,
+// NULL if the job has none
+func measureSQL(field storage.MeasureField) string {
+	switch field {
+	case storage.FieldYearsOfExperience:
+		return `CASE WHEN j.years_of_experience > 0 THEN j.years_of_experience END`
+	case storage.FieldCompanySize:
+		return `CASE WHEN j.company_size > 0 THEN j.company_size END`
+	case storage.FieldAnnualSalary:
+		return `(SELECT AVG(v) FROM unnest(ARRAY[(j.salary->>'annualMinChf')::float8, (j.salary->>'annualMaxChf')::float8]) AS v
+			WHERE v > 0)`
+	case storage.FieldPostingDate:
+		return `CASE WHEN j.posting_date > '0001-01-01T00:00:00Z' THEN extract(epoch FROM j.posting_date) END`
+	case storage.FieldLifetime:
+		return `CASE WHEN j.posting_date > '0001-01-01T00:00:00Z' AND j.closed_at >= j.posting_date
+			THEN extract(epoch FROM j.closed_at - j.posting_date) / 86400 END`
+	}
+	return `NULL`
+}
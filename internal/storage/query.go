@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+)
+
+// Skill-Listen, in denen JobQuery.Skill gesucht wird
+const (
+	SkillTypeAny      = ""
+	SkillTypeMust     = "must"
+	SkillTypeOptional = "optional"
+)
+
+// Sortierbare Felder von QueryJobs
+const (
+	SortPostingDate       = "postingDate"
+	SortTitle             = "title"
+	SortCompany           = "company"
+	SortYearsOfExperience = "yearsOfExperience"
+)
+
+// DefaultQueryLimit and MaxQueryLimit bound the page size of QueryJobs
+const (
+	DefaultQueryLimit = 50
+	MaxQueryLimit     = 200
+)
+
+// JobQuery selects, sorts and pages jobs. Zero values are ignored.
+// Text fields match case-insensitively, Company and Location also match parts.
+type JobQuery struct {
+	Category       string
+	Skill          string
+	SkillType      string // SkillTypeMust, SkillTypeOptional or SkillTypeAny
+	Company        string
+	Location       string // matches the location text, the resolved city or the canton
+	Remote         *bool
+	EmploymentType string
+	PostedFrom     time.Time
+	PostedTo       time.Time // exclusive
+	MinExperience  int
+
+	SortBy         string // one of the Sort constants, SortPostingDate if empty
+	SortDescending bool
+	Cursor         string // NextCursor of the previous page
+	Limit          int
+}
+
+// JobPage is a page of QueryJobs results
+type JobPage struct {
+	Jobs       []models.Job `json:"jobs"`
+	Total      int          `json:"total"`                // jobs matching the query on all pages
+	NextCursor string       `json:"nextCursor,omitempty"` // empty on the last page
+}
+
+// IsSortField reports whether jobs can be sorted by the given field
+func IsSortField(field string) bool {
+	switch field {
+	case SortPostingDate, SortTitle, SortCompany, SortYearsOfExperience:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortField returns the field to sort by, SortPostingDate if none is set
+func (q JobQuery) SortField() (string, error) {
+	if q.SortBy == "" {
+		return SortPostingDate, nil
+	}
+	if !IsSortField(q.SortBy) {
+		return "", apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid sort field: "+q.SortBy, nil)
+	}
+	return q.SortBy, nil
+}
+
+// PageSize returns the number of jobs per page, DefaultQueryLimit if none is set
+func (q JobQuery) PageSize() int {
+	if q.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	return min(q.Limit, MaxQueryLimit)
+}
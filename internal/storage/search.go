@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+
+	"job-scraper/internal/models"
+)
+
+// JobSearch is a full-text search restricted by the filters of a JobQuery.
+// Results are ordered by relevance, the sort fields of the query are ignored.
+type JobSearch struct {
+	Text string // words, "quoted phrases" and -excluded words
+	JobQuery
+}
+
+// SearchResult is a job found by SearchJobs with its relevance score
+type SearchResult struct {
+	Job   models.Job `json:"job"`
+	Score float64    `json:"score"`
+}
+
+// SearchPage is a page of SearchJobs results, most relevant first
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	Total      int            `json:"total"`                // jobs matching the search on all pages
+	NextCursor string         `json:"nextCursor,omitempty"` // empty on the last page
+}
+
+// SearchTerms are the parts of a JobSearch text. Like in MongoDB, any of the words
+// matches, but all phrases are required and excluded terms must not occur.
+type SearchTerms struct {
+	Words    []string
+	Phrases  []string
+	Excluded []string // words or phrases
+}
+
+// ParseSearchText splits a search text into its terms. Terms without letters or
+// digits are left out, as full-text indexes have no tokens for them.
+func ParseSearchText(text string) SearchTerms {
+	var terms SearchTerms
+
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		negated := strings.HasPrefix(rest, "-")
+		if negated {
+			rest = rest[1:]
+		}
+
+		var term string
+		isPhrase := strings.HasPrefix(rest, `"`)
+		if isPhrase {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				term, rest = rest[1:], ""
+			} else {
+				term, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		switch {
+		case negated:
+			terms.Excluded = append(terms.Excluded, term)
+		case isPhrase:
+			terms.Phrases = append(terms.Phrases, term)
+		default:
+			terms.Words = append(terms.Words, term)
+		}
+	}
+	return terms
+}
+
+// Empty reports whether the terms match no job, as there is nothing to search for
+func (t SearchTerms) Empty() bool {
+	return len(t.Words) == 0 && len(t.Phrases) == 0
+}
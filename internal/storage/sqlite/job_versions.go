@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetJobVersions returns the prior versions of a job, newest first
+func (c *Client) GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error) {
+	if _, err := primitive.ObjectIDFromHex(jobID); err != nil {
+		return nil, apperrors.NewNotFoundError("Job", jobID)
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, job_id, url, version, replaced_at, changes, job
+		FROM job_versions WHERE job_id = ? ORDER BY version DESC`, jobID)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch job versions", err)
+	}
+	defer rows.Close()
+
+	versions := []models.JobVersion{}
+	for rows.Next() {
+		var version models.JobVersion
+		var id, versionJobID, replacedAt string
+		var changes, job sql.NullString
+		if err := rows.Scan(&id, &versionJobID, &version.URL, &version.Version, &replacedAt, &changes, &job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		version.ID, _ = primitive.ObjectIDFromHex(id)
+		version.JobID, _ = primitive.ObjectIDFromHex(versionJobID)
+		version.ReplacedAt = parseTime(replacedAt)
+		if err := fromJSON(changes, &version.Changes); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		if err := fromJSON(job, &version.Job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode job versions", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch job versions", err)
+	}
+	return versions, nil
+}
+
+// replaceJob overwrites a stored job with a new scrape of the same posting. If the
+// content changed, the stored job is kept as prior version together with the diff.
+func replaceJob(ctx context.Context, tx *sql.Tx, existing, job models.Job) error {
+	job.ID = existing.ID
+	// Ein Repost derselben URL ist kein Duplikat von sich selbst
+	if job.DuplicateOf != nil && *job.DuplicateOf == existing.ID {
+		job.DuplicateOf = nil
+	}
+
+	changes, err := storage.DiffJobs(existing, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to compare job versions", err)
+	}
+	if len(changes) == 0 {
+		// Ohne neue Version werden nur Felder wie lastSeenAt, duplicateOf und source übernommen
+		if _, err := updateJob(ctx, tx, job); err != nil {
+			return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+		}
+		return nil
+	}
+
+	changesJSON, err := toJSON(changes)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+	jobJSON, err := toJSON(existing)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_versions (id, job_id, url, version, replaced_at, changes, job)
+		SELECT ?, ?, ?, COALESCE(MAX(version), 0) + 1, ?, ?, ? FROM job_versions WHERE job_id = ?`,
+		primitive.NewObjectID().Hex(), existing.ID.Hex(), existing.URL, formatTime(time.Now()),
+		changesJSON, jobJSON, existing.ID.Hex(),
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job version", err)
+	}
+
+	if _, err := updateJob(ctx, tx, job); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	return nil
+}
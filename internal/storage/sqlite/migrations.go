@@ -0,0 +1,264 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Migration is a versioned change of the database schema. Each step runs in a
+// transaction together with recording its version.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations lists all steps in ascending version order. Applied steps must not be
+// changed, new steps are appended with the next version.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create jobs, job versions, failed jobs and queued jobs tables",
+		// Listen, Orte, Lohn und Quelle sind JSON-Spalten und werden mit json_each abgefragt
+		Up: execAll(`
+			CREATE TABLE jobs (
+				pk                  INTEGER PRIMARY KEY,
+				id                  TEXT    NOT NULL UNIQUE,
+				url                 TEXT    NOT NULL UNIQUE,
+				title               TEXT    NOT NULL DEFAULT '',
+				description         TEXT    NOT NULL DEFAULT '',
+				company             TEXT    NOT NULL DEFAULT '',
+				location            TEXT    NOT NULL DEFAULT '',
+				locations           TEXT,
+				employment_type     TEXT    NOT NULL DEFAULT '',
+				posting_date        TEXT    NOT NULL,
+				expiration_date     TEXT    NOT NULL,
+				is_active           INTEGER NOT NULL DEFAULT 0,
+				job_categories      TEXT,
+				must_skills         TEXT,
+				optional_skills     TEXT,
+				salary              TEXT,
+				years_of_experience INTEGER NOT NULL DEFAULT 0,
+				education_level     TEXT    NOT NULL DEFAULT '',
+				benefits            TEXT,
+				company_size        INTEGER NOT NULL DEFAULT 0,
+				work_culture        TEXT    NOT NULL DEFAULT '',
+				remote              INTEGER NOT NULL DEFAULT 0,
+				languages           TEXT,
+				posting_language    TEXT    NOT NULL DEFAULT '',
+				prompt_version      TEXT    NOT NULL DEFAULT '',
+				extraction_method   TEXT    NOT NULL DEFAULT '',
+				provider            TEXT    NOT NULL DEFAULT '',
+				skill_taxonomy      TEXT    NOT NULL DEFAULT '',
+				fingerprint         TEXT    NOT NULL DEFAULT '',
+				duplicate_of        TEXT,
+				source              TEXT,
+				created_at          TEXT    NOT NULL
+			)`,
+			`CREATE INDEX jobs_posting_date ON jobs (posting_date)`,
+			`CREATE INDEX jobs_company ON jobs (company)`,
+			`CREATE INDEX jobs_posting_language ON jobs (posting_language)`,
+			`CREATE INDEX jobs_created_at ON jobs (created_at)`,
+			`CREATE TABLE job_versions (
+				id          TEXT    PRIMARY KEY,
+				job_id      TEXT    NOT NULL,
+				url         TEXT    NOT NULL,
+				version     INTEGER NOT NULL,
+				replaced_at TEXT    NOT NULL,
+				changes     TEXT,
+				job         TEXT    NOT NULL,
+				UNIQUE (job_id, version)
+			)`,
+			`CREATE TABLE failed_jobs (
+				url             TEXT    PRIMARY KEY,
+				description     TEXT    NOT NULL DEFAULT '',
+				source          TEXT,
+				error           TEXT    NOT NULL DEFAULT '',
+				attempts        INTEGER NOT NULL DEFAULT 0,
+				first_failed_at TEXT    NOT NULL,
+				last_failed_at  TEXT    NOT NULL
+			)`,
+			`CREATE TABLE queued_jobs (
+				url       TEXT PRIMARY KEY,
+				job       TEXT NOT NULL,
+				source    TEXT,
+				queued_at TEXT NOT NULL
+			)`,
+		),
+	},
+	{
+		Version:     2,
+		Description: "create full-text index over title, skills, company, location and description",
+		// Der FTS5-Index liest die Spalten aus jobs und wird per Trigger nachgeführt
+		Up: execAll(`
+			CREATE VIRTUAL TABLE jobs_fts USING fts5(
+				title, must_skills, optional_skills, company, location, description,
+				content = 'jobs', content_rowid = 'pk',
+				tokenize = 'unicode61 remove_diacritics 2'
+			)`,
+			`CREATE TRIGGER jobs_fts_insert AFTER INSERT ON jobs BEGIN
+				INSERT INTO jobs_fts (rowid, title, must_skills, optional_skills, company, location, description)
+				VALUES (new.pk, new.title, new.must_skills, new.optional_skills, new.company, new.location, new.description);
+			END`,
+			`CREATE TRIGGER jobs_fts_delete AFTER DELETE ON jobs BEGIN
+				INSERT INTO jobs_fts (jobs_fts, rowid, title, must_skills, optional_skills, company, location, description)
+				VALUES ('delete', old.pk, old.title, old.must_skills, old.optional_skills, old.company, old.location, old.description);
+			END`,
+			`CREATE TRIGGER jobs_fts_update AFTER UPDATE ON jobs BEGIN
+				INSERT INTO jobs_fts (jobs_fts, rowid, title, must_skills, optional_skills, company, location, description)
+				VALUES ('delete', old.pk, old.title, old.must_skills, old.optional_skills, old.company, old.location, old.description);
+				INSERT INTO jobs_fts (rowid, title, must_skills, optional_skills, company, location, description)
+				VALUES (new.pk, new.title, new.must_skills, new.optional_skills, new.company, new.location, new.description);
+			END`,
+			`INSERT INTO jobs_fts (jobs_fts) VALUES ('rebuild')`,
+		),
+	},
+	{
+		Version:     3,
+		Description: "add closed and last seen time of jobs",
+		Up: execAll(
+			`ALTER TABLE jobs ADD COLUMN closed_at TEXT`,
+			`ALTER TABLE jobs ADD COLUMN last_seen_at TEXT`,
+			`CREATE INDEX jobs_closed_at ON jobs (closed_at)`,
+		),
+	},
+}
+
+// execAll returns a migration step that runs the statements in order
+func execAll(statements ...string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Migrate applies the migrations that have not been applied yet, in version order.
+// It stops at the first failing step and returns the steps applied before.
+func (c *Client) Migrate(ctx context.Context) ([]storage.MigrationStatus, error) {
+	_, err := c.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version     INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at  TEXT NOT NULL
+		)`)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to create schema_migrations", err)
+	}
+
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []storage.MigrationStatus
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		start := time.Now()
+		status, err := c.apply(ctx, migration)
+		if err != nil {
+			return result, apperrors.NewBaseError(
+				apperrors.ErrCodeStorage,
+				fmt.Sprintf("migration %d (%s) failed", migration.Version, migration.Description),
+				err,
+			)
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("description", migration.Description).
+			Dur("duration", time.Since(start)).
+			Msg("Applied schema migration")
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+func (c *Client) apply(ctx context.Context, migration Migration) (storage.MigrationStatus, error) {
+	status := storage.MigrationStatus{
+		Version:     migration.Version,
+		Description: migration.Description,
+		Applied:     true,
+		AppliedAt:   time.Now(),
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return status, err
+	}
+	defer tx.Rollback()
+
+	if err := migration.Up(ctx, tx); err != nil {
+		return status, err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)",
+		status.Version, status.Description, formatTime(status.AppliedAt),
+	)
+	if err != nil {
+		return status, err
+	}
+	return status, tx.Commit()
+}
+
+// MigrationStatus returns all known migrations and whether they have been applied
+func (c *Client) MigrationStatus(ctx context.Context) ([]storage.MigrationStatus, error) {
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := storage.MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// appliedMigrations returns the recorded migrations, none if the table does not exist yet
+func (c *Client) appliedMigrations(ctx context.Context) (map[int]storage.MigrationStatus, error) {
+	var exists int
+	err := c.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	).Scan(&exists)
+	if err != nil || exists == 0 {
+		return map[int]storage.MigrationStatus{}, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, "SELECT version, description, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to read schema migrations", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]storage.MigrationStatus)
+	for rows.Next() {
+		var record storage.MigrationStatus
+		var appliedAt string
+		if err := rows.Scan(&record.Version, &record.Description, &appliedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode schema migrations", err)
+		}
+		record.Applied = true
+		record.AppliedAt = parseTime(appliedAt)
+		applied[record.Version] = record
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+)
+
+// sortColumns maps the sort fields of storage.JobQuery to columns
+var sortColumns = map[string]string{
+	storage.SortPostingDate:       "posting_date",
+	storage.SortTitle:             "title",
+	storage.SortCompany:           "company",
+	storage.SortYearsOfExperience: "years_of_experience",
+}
+
+// pageCursor ist die Position nach dem letzten Job einer Seite
+type pageCursor struct {
+	SortBy string      `json:"s"`
+	Value  interface{} `json:"v"`
+	ID     string      `json:"id"`
+}
+
+// where collects the conditions of a query on the jobs table, aliased as j
+type where struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (w *where) add(condition string, args ...interface{}) {
+	w.conditions = append(w.conditions, condition)
+	w.args = append(w.args, args...)
+}
+
+func (w *where) String() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// QueryJobs returns a page of the jobs matching the query. Pages are continued with
+// the cursor of the previous page, which stays stable while new jobs are stored.
+func (c *Client) QueryJobs(ctx context.Context, query storage.JobQuery) (storage.JobPage, error) {
+	page := storage.JobPage{Jobs: []models.Job{}}
+
+	sortBy, err := query.SortField()
+	if err != nil {
+		return page, err
+	}
+	column := sortColumns[sortBy]
+	direction, op := "ASC", ">"
+	if query.SortDescending {
+		direction, op = "DESC", "<"
+	}
+	limit := query.PageSize()
+
+	w := jobQueryToSQL(query)
+	if err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs j"+w.String(), w.args...).Scan(&page.Total); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count jobs", err)
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, sortBy)
+		if err != nil {
+			return page, err
+		}
+		// Jobs mit gleichem Sortierwert sind nach ID geordnet
+		w.add("(j."+column+" "+op+" ? OR (j."+column+" = ? AND j.id "+op+" ?))", cursor.Value, cursor.Value, cursor.ID)
+	}
+
+	// Ein zusätzlicher Job zeigt an, ob es eine weitere Seite gibt
+	jobs, err := c.queryJobs(ctx,
+		"SELECT "+jobColumns+" FROM jobs j"+w.String()+
+			" ORDER BY j."+column+" "+direction+", j.id "+direction+" LIMIT ?",
+		append(w.args, limit+1)...,
+	)
+	if err != nil {
+		return page, err
+	}
+	page.Jobs = jobs
+
+	if len(page.Jobs) > limit {
+		page.Jobs = page.Jobs[:limit]
+		if page.NextCursor, err = encodeCursor(page.Jobs[limit-1], sortBy); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// jobFilterToSQL translates a storage.JobFilter into conditions on the jobs table
+func jobFilterToSQL(filter storage.JobFilter) *where {
+	w := &where{}
+	if !filter.PostedFrom.IsZero() {
+		w.add("j.posting_date >= ?", formatTime(filter.PostedFrom))
+	}
+	if !filter.PostedTo.IsZero() {
+		w.add("j.posting_date < ?", formatTime(filter.PostedTo))
+	}
+	if filter.Category != "" {
+		w.add("EXISTS (SELECT 1 FROM json_each(j.job_categories) WHERE value = ?)", filter.Category)
+	}
+	if filter.PromptVersion != "" {
+		w.add("j.prompt_version = ?", filter.PromptVersion)
+	}
+	if filter.ExtractionMethod != "" {
+		w.add("j.extraction_method = ?", filter.ExtractionMethod)
+	}
+	if filter.PostingLanguage != "" {
+		w.add("j.posting_language = ?", filter.PostingLanguage)
+	}
+	if filter.OriginalsOnly {
+		w.add("j.duplicate_of IS NULL")
+	}
+	if filter.OpenOnly {
+		w.add("j.closed_at IS NULL")
+	}
+	if filter.ClosedOnly {
+		w.add("j.closed_at IS NOT NULL")
+	}
+	return w
+}
+
+// jobQueryToSQL translates the filter fields of a storage.JobQuery into conditions
+// on the jobs table. Like in MongoDB, text is compared ignoring case.
+func jobQueryToSQL(query storage.JobQuery) *where {
+	w := &where{}
+
+	if query.Category != "" {
+		w.add("EXISTS (SELECT 1 FROM json_each(j.job_categories) WHERE value = ?)",
+			strings.ToUpper(strings.TrimSpace(query.Category)))
+	}
+	if query.Skill != "" {
+		skill := strings.TrimSpace(query.Skill)
+		mustSkill := "EXISTS (SELECT 1 FROM json_each(j.must_skills) WHERE value = ? COLLATE NOCASE)"
+		optionalSkill := "EXISTS (SELECT 1 FROM json_each(j.optional_skills) WHERE value = ? COLLATE NOCASE)"
+		switch query.SkillType {
+		case storage.SkillTypeMust:
+			w.add(mustSkill, skill)
+		case storage.SkillTypeOptional:
+			w.add(optionalSkill, skill)
+		default:
+			w.add("("+mustSkill+" OR "+optionalSkill+")", skill, skill)
+		}
+	}
+	if query.Company != "" {
+		w.add("instr(lower(j.company), lower(?)) > 0", strings.TrimSpace(query.Company))
+	}
+	if query.Location != "" {
+		location := strings.TrimSpace(query.Location)
+		w.add(`(instr(lower(j.location), lower(?)) > 0 OR EXISTS (
+			SELECT 1 FROM json_each(j.locations)
+			WHERE json_extract(value, '$.city') = ? COLLATE NOCASE OR json_extract(value, '$.canton') = ?))`,
+			location, location, strings.ToUpper(location))
+	}
+	if query.Remote != nil {
+		w.add("j.remote = ?", *query.Remote)
+	}
+	if query.EmploymentType != "" {
+		w.add("j.employment_type = ? COLLATE NOCASE", strings.TrimSpace(query.EmploymentType))
+	}
+	if !query.PostedFrom.IsZero() {
+		w.add("j.posting_date >= ?", formatTime(query.PostedFrom))
+	}
+	if !query.PostedTo.IsZero() {
+		w.add("j.posting_date < ?", formatTime(query.PostedTo))
+	}
+	if query.MinExperience > 0 {
+		w.add("j.years_of_experience >= ?", query.MinExperience)
+	}
+	return w
+}
+
+func encodeCursor(job models.Job, sortBy string) (string, error) {
+	cursor := pageCursor{SortBy: sortBy, ID: job.ID.Hex()}
+	switch sortBy {
+	case storage.SortPostingDate:
+		cursor.Value = formatTime(job.PostingDate)
+	case storage.SortTitle:
+		cursor.Value = job.Title
+	case storage.SortCompany:
+		cursor.Value = job.Company
+	case storage.SortYearsOfExperience:
+		cursor.Value = job.YearsOfExperience
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value, sortBy string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	if cursor.SortBy != sortBy {
+		return cursor, apperrors.NewBaseError(apperrors.ErrCodeValidation, "page cursor belongs to a different sort order", nil)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+)
+
+// searchWeights gewichtet die Spalten von jobs_fts wie der MongoDB-Textindex
+const searchWeights = "10.0, 5.0, 3.0, 3.0, 3.0, 1.0"
+
+// searchCursor ist die Anzahl Treffer auf den vorherigen Seiten
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+// SearchJobs finds jobs with the full-text index, most relevant first
+func (c *Client) SearchJobs(ctx context.Context, search storage.JobSearch) (storage.SearchPage, error) {
+	page := storage.SearchPage{Results: []storage.SearchResult{}}
+
+	if strings.TrimSpace(search.Text) == "" {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeValidation, "search text is required", nil)
+	}
+	limit := search.PageSize()
+	offset, err := decodeSearchCursor(search.Cursor)
+	if err != nil {
+		return page, err
+	}
+
+	match := ftsQuery(search.Text)
+	if match == "" {
+		// Nur ausgeschlossene Wörter finden wie in MongoDB nichts
+		return page, nil
+	}
+	w := jobQueryToSQL(search.JobQuery)
+	w.conditions = append([]string{"jobs_fts MATCH ?"}, w.conditions...)
+	w.args = append([]interface{}{match}, w.args...)
+	from := " FROM jobs_fts JOIN jobs j ON j.pk = jobs_fts.rowid" + w.String()
+
+	if err := c.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from, w.args...).Scan(&page.Total); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count search results", err)
+	}
+
+	// bm25 ist negativ, kleinere Werte sind relevanter
+	rows, err := c.db.QueryContext(ctx,
+		"SELECT "+qualifiedJobColumns()+", -bm25(jobs_fts, "+searchWeights+") AS score"+from+
+			" ORDER BY score DESC, j.id LIMIT ? OFFSET ?",
+		append(w.args, limit, offset)...,
+	)
+	if err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to search jobs", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result storage.SearchResult
+		if result.Job, err = scanJob(rows, &result.Score); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode search results", err)
+		}
+		page.Results = append(page.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to search jobs", err)
+	}
+
+	if next := offset + len(page.Results); next < page.Total {
+		if page.NextCursor, err = encodeSearchCursor(next); err != nil {
+			return page, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to encode page cursor", err)
+		}
+	}
+	return page, nil
+}
+
+// ftsQuery translates a search text into an FTS5 query. An empty result matches nothing.
+func ftsQuery(text string) string {
+	terms := storage.ParseSearchText(text)
+
+	var query string
+	switch {
+	case len(terms.Phrases) > 0:
+		query = "(" + strings.Join(quoteAll(terms.Phrases), " AND ") + ")"
+	case len(terms.Words) > 0:
+		query = "(" + strings.Join(quoteAll(terms.Words), " OR ") + ")"
+	default:
+		return ""
+	}
+	for _, term := range quoteAll(terms.Excluded) {
+		query += " NOT " + term
+	}
+	return query
+}
+
+// quoteAll quotes terms as FTS5 strings, so operators in them are searched as text
+func quoteAll(terms []string) []string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return quoted
+}
+
+// qualifiedJobColumns returns jobColumns prefixed with the alias of the jobs table,
+// as jobs_fts has columns of the same name
+func qualifiedJobColumns() string {
+	columns := strings.Split(jobColumns, ",")
+	for i, column := range columns {
+		columns[i] = "j." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}
+
+func encodeSearchCursor(offset int) (string, error) {
+	data, err := json.Marshal(searchCursor{Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSearchCursor(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Offset < 0 {
+		return 0, apperrors.NewBaseError(apperrors.ErrCodeValidation, "invalid page cursor", err)
+	}
+	return cursor.Offset, nil
+}
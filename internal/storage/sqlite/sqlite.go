@@ -0,0 +1,555 @@
+// Package sqlite stores jobs in a SQLite database file. It needs no server and is
+// meant for local development and small deployments.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	_ "modernc.org/sqlite"
+)
+
+// timeLayout speichert Zeitpunkte in UTC mit fester Länge, damit sie als Text sortierbar sind
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// jobColumns are the columns of the jobs table in the order of jobValues and scanJob
+const jobColumns = `id, url, title, description, company, location, locations, employment_type,
+	posting_date, expiration_date, is_active, job_categories, must_skills, optional_skills, salary,
+	years_of_experience, education_level, benefits, company_size, work_culture, remote, languages,
+	posting_language, prompt_version, extraction_method, provider, skill_taxonomy, fingerprint,
+	duplicate_of, source, closed_at, last_seen_at`
+
+type Client struct {
+	db *sql.DB
+}
+
+// NewClient opens the database file at path and creates it if it does not exist.
+// The schema is created by Migrate.
+func NewClient(ctx context.Context, path string) (*Client, error) {
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite erlaubt nur einen Schreiber; eine Verbindung vermeidet SQLITE_BUSY
+	// und hält auch eine :memory:-Datenbank über alle Anfragen zusammen
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Client{db: db}, nil
+}
+
+func (c *Client) GetJobs(ctx context.Context) ([]models.Job, error) {
+	return c.queryJobs(ctx, "SELECT "+jobColumns+" FROM jobs j ORDER BY pk")
+}
+
+func (c *Client) GetJobByID(ctx context.Context, id string) (*models.Job, error) {
+	job, err := scanJob(c.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("Job", id)
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "database error", err)
+	}
+	return &job, nil
+}
+
+// GetJobByURL returns the job stored for a posting URL
+func (c *Client) GetJobByURL(ctx context.Context, url string) (*models.Job, error) {
+	job, err := findJobByURL(ctx, c.db, url)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, apperrors.NewNotFoundError("Job", url)
+	}
+	return job, nil
+}
+
+// SaveJob inserts a job or, if a job with the same URL is already stored, replaces it.
+// Prior versions of changed postings are kept in the job_versions table.
+func (c *Client) SaveJob(ctx context.Context, job models.Job) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := findJobByURL(ctx, tx, job.URL)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		err = replaceJob(ctx, tx, *existing, job)
+	} else {
+		err = insertJob(ctx, tx, job)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	return nil
+}
+
+func (c *Client) FindJobs(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	w := jobFilterToSQL(filter)
+	return c.queryJobs(ctx, "SELECT "+jobColumns+" FROM jobs j"+w.String()+" ORDER BY pk", w.args...)
+}
+
+func (c *Client) UpdateJob(ctx context.Context, job models.Job) error {
+	result, err := updateJob(ctx, c.db, job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to update job", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apperrors.NewNotFoundError("Job", job.ID.Hex())
+	}
+	return nil
+}
+
+func (c *Client) SaveFailedJob(ctx context.Context, job models.FailedJob) error {
+	source, err := toJSON(job.Source)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save failed job", err)
+	}
+	now := formatTime(time.Now())
+	_, err = c.db.ExecContext(ctx, `
+		INSERT INTO failed_jobs (url, description, source, error, attempts, first_failed_at, last_failed_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET
+			description = excluded.description,
+			source = excluded.source,
+			error = excluded.error,
+			attempts = attempts + 1,
+			last_failed_at = excluded.last_failed_at`,
+		job.URL, job.Description, source, job.Error, now, now,
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save failed job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetFailedJobs(ctx context.Context) ([]models.FailedJob, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT url, description, source, error, attempts, first_failed_at, last_failed_at
+		FROM failed_jobs ORDER BY first_failed_at`)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch failed jobs", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.FailedJob
+	for rows.Next() {
+		var job models.FailedJob
+		var source sql.NullString
+		var firstFailedAt, lastFailedAt string
+		if err := rows.Scan(&job.URL, &job.Description, &source, &job.Error, &job.Attempts, &firstFailedAt, &lastFailedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode failed jobs", err)
+		}
+		if err := fromJSON(source, &job.Source); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode failed jobs", err)
+		}
+		job.FirstFailedAt = parseTime(firstFailedAt)
+		job.LastFailedAt = parseTime(lastFailedAt)
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch failed jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteFailedJob(ctx context.Context, url string) error {
+	if _, err := c.db.ExecContext(ctx, "DELETE FROM failed_jobs WHERE url = ?", url); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete failed job", err)
+	}
+	return nil
+}
+
+// QueueJob stores a job waiting for the LLM budget. A job queued again keeps its queue time.
+// The raw payload is kept in its own column, as for failed jobs.
+func (c *Client) QueueJob(ctx context.Context, job models.Job) error {
+	source, err := toJSON(job.Source)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	_, err = c.db.ExecContext(ctx, `
+		INSERT INTO queued_jobs (url, job, source, queued_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET job = excluded.job, source = excluded.source`,
+		job.URL, string(data), source, formatTime(time.Now()),
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to queue job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error) {
+	rows, err := c.db.QueryContext(ctx, "SELECT url, job, source, queued_at FROM queued_jobs ORDER BY queued_at")
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch queued jobs", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.QueuedJob
+	for rows.Next() {
+		var queued models.QueuedJob
+		var job, queuedAt string
+		var source sql.NullString
+		if err := rows.Scan(&queued.URL, &job, &source, &queuedAt); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		if err := json.Unmarshal([]byte(job), &queued.Job); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		if err := fromJSON(source, &queued.Job.Source); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode queued jobs", err)
+		}
+		queued.QueuedAt = parseTime(queuedAt)
+		jobs = append(jobs, queued)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch queued jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) DeleteQueuedJob(ctx context.Context, url string) error {
+	if _, err := c.db.ExecContext(ctx, "DELETE FROM queued_jobs WHERE url = ?", url); err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to delete queued job", err)
+	}
+	return nil
+}
+
+func (c *Client) GetJobCountByCategory(ctx context.Context) (map[string]int, error) {
+	return c.countValues(ctx, `
+		SELECT c.value, COUNT(*) FROM jobs j, json_each(j.job_categories) c GROUP BY c.value`)
+}
+
+// GetSkillCounts returns the number of jobs per skill, required and optional skills combined
+func (c *Client) GetSkillCounts(ctx context.Context) (map[string]int, error) {
+	return c.countValues(ctx, `
+		SELECT value, COUNT(DISTINCT id) FROM (
+			SELECT j.id, s.value FROM jobs j, json_each(j.must_skills) s
+			UNION ALL
+			SELECT j.id, s.value FROM jobs j, json_each(j.optional_skills) s
+		) GROUP BY value`)
+}
+
+func (c *Client) GetTotalJobCount(ctx context.Context) (int, error) {
+	var count int
+	err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs").Scan(&count)
+	return count, err
+}
+
+func (c *Client) GetExistingURLs(ctx context.Context) (map[string]bool, error) {
+	rows, err := c.db.QueryContext(ctx, "SELECT url FROM jobs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls[url] = true
+	}
+	return urls, rows.Err()
+}
+
+// GetFingerprints returns the fingerprints of the original jobs stored since the given time.
+// Jobs marked as duplicates are left out, so new duplicates link to the original.
+func (c *Client) GetFingerprints(ctx context.Context, since time.Time) ([]storage.JobFingerprint, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, fingerprint FROM jobs
+		WHERE fingerprint <> '' AND duplicate_of IS NULL AND created_at >= ?
+		ORDER BY pk`, formatTime(since))
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch fingerprints", err)
+	}
+	defer rows.Close()
+
+	fingerprints := []storage.JobFingerprint{}
+	for rows.Next() {
+		var id, fingerprint string
+		if err := rows.Scan(&id, &fingerprint); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode fingerprints", err)
+		}
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode fingerprints", err)
+		}
+		fingerprints = append(fingerprints, storage.JobFingerprint{ID: objectID, Fingerprint: fingerprint})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch fingerprints", err)
+	}
+	return fingerprints, nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	return c.db.Close()
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (c *Client) queryJobs(ctx context.Context, query string, args ...interface{}) ([]models.Job, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch jobs", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode jobs", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to fetch jobs", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) countValues(ctx context.Context, query string) (map[string]int, error) {
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to count jobs", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var value sql.NullString
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode counts", err)
+		}
+		if value.Valid {
+			counts[value.String] = count
+		}
+	}
+	return counts, rows.Err()
+}
+
+// findJobByURL returns nil if no job is stored for the URL
+func findJobByURL(ctx context.Context, q queryer, url string) (*models.Job, error) {
+	job, err := scanJob(q.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE url = ?", url))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to find job by URL", err)
+	}
+	return &job, nil
+}
+
+func insertJob(ctx context.Context, q queryer, job models.Job) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	values, err := jobValues(job)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)+1), ", ")
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO jobs ("+jobColumns+", created_at) VALUES ("+placeholders+")",
+		append(values, formatTime(time.Now()))...,
+	)
+	if err != nil {
+		return apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to save job", err)
+	}
+	return nil
+}
+
+// updateJob overwrites all columns of the job with the same ID
+func updateJob(ctx context.Context, q queryer, job models.Job) (sql.Result, error) {
+	values, err := jobValues(job)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := strings.Split(jobColumns, ",")
+	assignments := make([]string, 0, len(columns)-1)
+	for _, column := range columns[1:] {
+		assignments = append(assignments, strings.TrimSpace(column)+" = ?")
+	}
+	return q.ExecContext(ctx,
+		"UPDATE jobs SET "+strings.Join(assignments, ", ")+" WHERE id = ?",
+		append(values[1:], values[0])...,
+	)
+}
+
+// jobValues returns the column values of a job in the order of jobColumns
+func jobValues(job models.Job) ([]interface{}, error) {
+	// Listen, Orte, Lohn und Quelle werden als JSON gespeichert
+	encoded := make([]interface{}, 0, 8)
+	for _, value := range []interface{}{
+		job.Locations, job.JobCategories, job.MustSkills, job.OptionalSkills,
+		job.Salary, job.Benefits, job.Languages, job.Source,
+	} {
+		column, err := toJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, column)
+	}
+	locations, categories, mustSkills, optionalSkills := encoded[0], encoded[1], encoded[2], encoded[3]
+	salary, benefits, languages, source := encoded[4], encoded[5], encoded[6], encoded[7]
+
+	var duplicateOf interface{}
+	if job.DuplicateOf != nil {
+		duplicateOf = job.DuplicateOf.Hex()
+	}
+
+	return []interface{}{
+		job.ID.Hex(), job.URL, job.Title, job.Description, job.Company, job.Location, locations, job.EmploymentType,
+		formatTime(job.PostingDate), formatTime(job.ExpirationDate), job.IsActive, categories, mustSkills, optionalSkills, salary,
+		job.YearsOfExperience, job.EducationLevel, benefits, job.CompanySize, job.WorkCulture, job.Remote, languages,
+		job.PostingLanguage, job.PromptVersion, job.ExtractionMethod, job.Provider, job.SkillTaxonomy, job.Fingerprint,
+		duplicateOf, source, formatOptionalTime(job.ClosedAt), formatOptionalTime(job.LastSeenAt),
+	}, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob reads the columns of jobColumns and, if given, further columns into extra
+func scanJob(row rowScanner, extra ...interface{}) (models.Job, error) {
+	var job models.Job
+	var id, postingDate, expirationDate string
+	var locations, categories, mustSkills, optionalSkills, salary, benefits, languages, source, duplicateOf sql.NullString
+	var closedAt, lastSeenAt sql.NullString
+
+	dest := []interface{}{
+		&id, &job.URL, &job.Title, &job.Description, &job.Company, &job.Location, &locations, &job.EmploymentType,
+		&postingDate, &expirationDate, &job.IsActive, &categories, &mustSkills, &optionalSkills, &salary,
+		&job.YearsOfExperience, &job.EducationLevel, &benefits, &job.CompanySize, &job.WorkCulture, &job.Remote, &languages,
+		&job.PostingLanguage, &job.PromptVersion, &job.ExtractionMethod, &job.Provider, &job.SkillTaxonomy, &job.Fingerprint,
+		&duplicateOf, &source, &closedAt, &lastSeenAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return job, err
+	}
+
+	var err error
+	if job.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return job, err
+	}
+	if duplicateOf.Valid {
+		original, err := primitive.ObjectIDFromHex(duplicateOf.String)
+		if err != nil {
+			return job, err
+		}
+		job.DuplicateOf = &original
+	}
+	job.PostingDate = parseTime(postingDate)
+	job.ExpirationDate = parseTime(expirationDate)
+	job.ClosedAt = parseOptionalTime(closedAt)
+	job.LastSeenAt = parseOptionalTime(lastSeenAt)
+
+	for _, column := range []struct {
+		value  sql.NullString
+		target interface{}
+	}{
+		{locations, &job.Locations}, {categories, &job.JobCategories}, {mustSkills, &job.MustSkills},
+		{optionalSkills, &job.OptionalSkills}, {salary, &job.Salary}, {benefits, &job.Benefits},
+		{languages, &job.Languages}, {source, &job.Source},
+	} {
+		if err := fromJSON(column.value, column.target); err != nil {
+			return job, err
+		}
+	}
+	return job, nil
+}
+
+// toJSON encodes a value for a JSON column. Nil slices and pointers are stored as NULL.
+func toJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+func fromJSON(column sql.NullString, target interface{}) error {
+	if !column.Valid {
+		return nil
+	}
+	return json.Unmarshal([]byte(column.String), target)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// formatOptionalTime stores a missing time as NULL
+func formatOptionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func parseOptionalTime(column sql.NullString) *time.Time {
+	if !column.Valid {
+		return nil
+	}
+	t := parseTime(column.String)
+	return &t
+}
@@ -0,0 +1,346 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) *Client {
+	ctx := context.Background()
+	client, err := NewClient(ctx, filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close(ctx) })
+
+	_, err = client.Migrate(ctx)
+	require.NoError(t, err)
+	return client
+}
+
+func testJob(url, title string, postingDate time.Time) models.Job {
+	return models.Job{
+		URL:            url,
+		Title:          title,
+		Description:    "Wir suchen Verstärkung für unser Team",
+		Company:        "Example AG",
+		Location:       "Zürich",
+		Locations:      []models.Place{{City: "Zürich", Canton: "ZH", Country: "CH"}},
+		EmploymentType: "Full-time",
+		PostingDate:    postingDate,
+		JobCategories:  []string{"SOFTWARE ENGINEER"},
+		MustSkills:     []string{"Go"},
+		OptionalSkills: []string{"Kubernetes"},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	// Ein zweiter Lauf hat nichts mehr zu tun
+	applied, err := client.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	status, err := client.MigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, len(migrations))
+	for i, migration := range status {
+		assert.Equal(t, i+1, migration.Version)
+		assert.True(t, migration.Applied)
+	}
+}
+
+func TestSaveJobKeepsVersions(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, client.SaveJob(ctx, testJob("https://example.com/1", "Go Developer", postingDate)))
+	stored, err := client.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	assert.Equal(t, "Go Developer", stored.Title)
+	assert.Equal(t, []string{"Go"}, stored.MustSkills)
+	assert.Equal(t, "ZH", stored.Locations[0].Canton)
+	assert.True(t, stored.PostingDate.Equal(postingDate))
+
+	// Derselbe Inhalt erzeugt keine Version, unversionierte Felder werden trotzdem gespeichert
+	seenAt := time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)
+	seen := testJob("https://example.com/1", "Go Developer", postingDate)
+	seen.LastSeenAt = &seenAt
+	require.NoError(t, client.SaveJob(ctx, seen))
+	versions, err := client.GetJobVersions(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+	stored, err = client.GetJobByURL(ctx, "https://example.com/1")
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastSeenAt)
+	assert.True(t, stored.LastSeenAt.Equal(seenAt))
+
+	require.NoError(t, client.SaveJob(ctx, testJob("https://example.com/1", "Senior Go Developer", postingDate)))
+	updated, err := client.GetJobByID(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "Senior Go Developer", updated.Title)
+
+	versions, err = client.GetJobVersions(ctx, stored.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "Go Developer", versions[0].Job.Title)
+	require.Len(t, versions[0].Changes, 1)
+	assert.Equal(t, "title", versions[0].Changes[0].Field)
+
+	total, err := client.GetTotalJobCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestFindOpenJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, client.SaveJob(ctx, testJob("https://example.com/1", "Go Developer", postingDate)))
+	require.NoError(t, client.SaveJob(ctx, testJob("https://example.com/2", "Java Developer", postingDate)))
+
+	closed, err := client.GetJobByURL(ctx, "https://example.com/2")
+	require.NoError(t, err)
+	closedAt := time.Date(2024, 11, 15, 12, 0, 0, 0, time.UTC)
+	closed.ClosedAt = &closedAt
+	require.NoError(t, client.UpdateJob(ctx, *closed))
+
+	stored, err := client.GetJobByID(ctx, closed.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, stored.ClosedAt)
+	assert.True(t, stored.ClosedAt.Equal(closedAt))
+	assert.Nil(t, stored.LastSeenAt)
+
+	open, err := client.FindJobs(ctx, storage.JobFilter{OpenOnly: true})
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "Go Developer", open[0].Title)
+}
+
+func TestQueryJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, title := range []string{"Go Developer", "Platform Engineer", "Backend Engineer"} {
+		job := testJob("https://example.com/"+title, title, postingDate.AddDate(0, 0, i))
+		if i == 1 {
+			job.MustSkills = []string{"Rust"}
+			job.Remote = true
+		}
+		require.NoError(t, client.SaveJob(ctx, job))
+	}
+
+	page, err := client.QueryJobs(ctx, storage.JobQuery{Skill: "go", SkillType: storage.SkillTypeMust})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	remote := true
+	page, err = client.QueryJobs(ctx, storage.JobQuery{Remote: &remote, Location: "zh"})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, "Platform Engineer", page.Jobs[0].Title)
+
+	// Seiten setzen nach dem letzten Job der vorherigen Seite fort
+	var titles []string
+	query := storage.JobQuery{SortDescending: true, Limit: 2}
+	for {
+		page, err := client.QueryJobs(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, 3, page.Total)
+		for _, job := range page.Jobs {
+			titles = append(titles, job.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"Backend Engineer", "Platform Engineer", "Go Developer"}, titles)
+
+	_, err = client.QueryJobs(ctx, storage.JobQuery{SortBy: storage.SortTitle, Cursor: query.Cursor})
+	assert.Error(t, err)
+}
+
+func TestSearchJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	postingDate := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	goJob := testJob("https://example.com/go", "Go Developer", postingDate)
+	rustJob := testJob("https://example.com/rust", "Rust Developer", postingDate)
+	rustJob.Description = "Rust und etwas Go"
+	require.NoError(t, client.SaveJob(ctx, goJob))
+	require.NoError(t, client.SaveJob(ctx, rustJob))
+
+	page, err := client.SearchJobs(ctx, storage.JobSearch{Text: "go"})
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	// Treffer im Titel wiegen schwerer als in der Beschreibung
+	assert.Equal(t, "Go Developer", page.Results[0].Job.Title)
+	assert.Greater(t, page.Results[0].Score, page.Results[1].Score)
+
+	page, err = client.SearchJobs(ctx, storage.JobSearch{Text: "developer -rust"})
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "Go Developer", page.Results[0].Job.Title)
+
+	page, err = client.SearchJobs(ctx, storage.JobSearch{Text: "zurich"})
+	require.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+
+	page, err = client.SearchJobs(ctx, storage.JobSearch{Text: "-rust"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Results)
+}
+
+func TestFtsQuery(t *testing.T) {
+	assert.Equal(t, `("go" OR "kubernetes")`, ftsQuery("go kubernetes"))
+	assert.Equal(t, `("machine learning") NOT "python"`, ftsQuery(`go "machine learning" -python`))
+	assert.Equal(t, `("c++")`, ftsQuery("c++ ++"))
+	assert.Equal(t, "", ftsQuery("-java"))
+}
+
+func TestFailedJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	failed := models.FailedJob{URL: "https://example.com/1", Error: "timeout", Attempts: 1, FirstFailedAt: time.Now(), LastFailedAt: time.Now()}
+	require.NoError(t, client.SaveFailedJob(ctx, failed))
+	failed.Attempts = 2
+	require.NoError(t, client.SaveFailedJob(ctx, failed))
+
+	jobs, err := client.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 2, jobs[0].Attempts)
+
+	require.NoError(t, client.DeleteFailedJob(ctx, failed.URL))
+	jobs, err = client.GetFailedJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestQueuedJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	job := testJob("https://example.com/1", "Go Developer", time.Now())
+	job.Source = &models.SourcePayload{RawPayload: "<p>Go Developer</p>", ContentType: "text/html"}
+	require.NoError(t, client.QueueJob(ctx, job))
+	// Erneutes Einreihen ersetzt den Job
+	require.NoError(t, client.QueueJob(ctx, job))
+
+	queued, err := client.GetQueuedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, job.URL, queued[0].URL)
+	assert.Equal(t, "Go Developer", queued[0].Job.Title)
+	require.NotNil(t, queued[0].Job.Source)
+	assert.Equal(t, "<p>Go Developer</p>", queued[0].Job.Source.RawPayload)
+
+	require.NoError(t, client.DeleteQueuedJob(ctx, job.URL))
+	queued, err = client.GetQueuedJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, queued)
+}
+
+func TestAggregateStats(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	day := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	closed := day.AddDate(0, 0, 10)
+	var jobs []models.Job
+	for i, spec := range []struct {
+		company string
+		size    int
+		skills  []string
+		years   int
+		salary  float64
+		canton  string
+	}{
+		// Doppelte Skills zählen nur einmal
+		{"Acme", 20, []string{"Go", "SQL", "Go"}, 3, 90000, "ZH"},
+		{"Acme", 30, []string{"Go"}, 5, 110000, "ZH"},
+		{"Globex", 500, []string{"Java", "SQL"}, 0, 0, "BE"},
+		{"Initech", 120, []string{"Go", "Rust"}, 8, 130000, ""},
+	} {
+		job := testJob(fmt.Sprintf("https://example.com/%d", i), "Developer", day.AddDate(0, i, i))
+		job.Company, job.CompanySize, job.MustSkills, job.YearsOfExperience = spec.company, spec.size, spec.skills, spec.years
+		job.Locations = []models.Place{{City: "Stadt " + spec.company, Canton: spec.canton, Country: "CH"}}
+		if spec.salary > 0 {
+			job.Salary = &models.Salary{Min: spec.salary, AnnualMinCHF: spec.salary, AnnualMaxCHF: spec.salary + 10000}
+		}
+		if i%2 == 0 {
+			job.ClosedAt = &closed
+		}
+		require.NoError(t, client.SaveJob(ctx, job))
+		jobs = append(jobs, job)
+	}
+
+	// SQL und Berechnung im Speicher liefern dieselben Gruppen
+	for _, query := range []storage.StatsQuery{
+		{
+			GroupBy: []storage.Dimension{storage.DimMustSkill},
+			Measures: []storage.Measure{
+				{Name: "avgExperience", Aggregate: storage.AggregateAvg, Field: storage.FieldYearsOfExperience},
+				{Name: "withExperience", Aggregate: storage.AggregateCount, Field: storage.FieldYearsOfExperience},
+				{Name: "maxSize", Aggregate: storage.AggregateMax, Field: storage.FieldCompanySize},
+				{Name: "medianSalary", Aggregate: storage.AggregateMedian, Field: storage.FieldAnnualSalary},
+				{Name: "p90Salary", Aggregate: storage.AggregateP90, Field: storage.FieldAnnualSalary},
+			},
+			OrderBy: []storage.StatsOrder{{Key: storage.CountKey, Descending: true}},
+		},
+		{
+			Where:   []storage.StatsCondition{{Dimension: storage.DimCompanySize, Values: []string{"small", "large"}}},
+			GroupBy: []storage.Dimension{storage.DimPostingDate},
+			Bucket:  storage.BucketMonth,
+			Limit:   2,
+		},
+		{GroupBy: []storage.Dimension{storage.DimCanton, storage.DimLocation}},
+		{GroupBy: []storage.Dimension{storage.DimExperience, storage.DimRemote}},
+		{
+			GroupBy:  []storage.Dimension{storage.DimClosedDate},
+			Measures: []storage.Measure{{Name: "lifetime", Aggregate: storage.AggregateAvg, Field: storage.FieldLifetime}},
+		},
+		{Measures: []storage.Measure{
+			{Name: "median", Aggregate: storage.AggregateMedian, Field: storage.FieldYearsOfExperience},
+			{Name: "posted", Aggregate: storage.AggregateMax, Field: storage.FieldPostingDate},
+		}},
+	} {
+		want, err := storage.ComputeStats(jobs, query)
+		require.NoError(t, err)
+		got, err := client.AggregateStats(ctx, query)
+		require.NoError(t, err)
+		require.Len(t, got, len(want))
+		for i := range want {
+			assert.Equal(t, want[i].Keys, got[i].Keys)
+			assert.Equal(t, want[i].Count, got[i].Count, want[i].Keys)
+			// COUNT liefert wie in PostgreSQL auch 0, ComputeStats lässt den Wert weg
+			for name, value := range want[i].Values {
+				assert.Contains(t, got[i].Values, name)
+				assert.InDelta(t, value, got[i].Values[name], 1e-6, name)
+			}
+			for name, value := range got[i].Values {
+				assert.InDelta(t, want[i].Values[name], value, 1e-6, name)
+			}
+		}
+	}
+
+	rows, err := client.AggregateStats(ctx, storage.StatsQuery{Filter: storage.JobFilter{Category: "NONE"}})
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+}
@@ -0,0 +1,250 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/storage"
+)
+
+// zeroTime ist das gespeicherte Nulldatum, siehe formatTime
+const zeroTime = "0001-01-01T00:00:00.000000000Z"
+
+// AggregateStats runs a statistic as SQL query, see statsSQL
+func (c *Client) AggregateStats(ctx context.Context, query storage.StatsQuery) ([]storage.StatsRow, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	sql, args := statsSQL(query)
+	rows, err := c.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to aggregate jobs", err)
+	}
+	defer rows.Close()
+
+	results := []storage.StatsRow{}
+	for rows.Next() {
+		row := storage.StatsRow{Keys: make([]string, len(query.GroupBy)), Values: make(map[string]float64)}
+		var count int64
+		values := make([]*float64, len(query.Measures))
+		targets := make([]interface{}, 0, len(row.Keys)+1+len(values))
+		for i := range row.Keys {
+			targets = append(targets, &row.Keys[i])
+		}
+		targets = append(targets, &count)
+		for i := range values {
+			targets = append(targets, &values[i])
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to decode aggregation results", err)
+		}
+
+		row.Count = int(count)
+		for i, value := range values {
+			if value != nil {
+				row.Values[query.Measures[i].Name] = *value
+			}
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewBaseError(apperrors.ErrCodeStorage, "failed to aggregate jobs", err)
+	}
+	return results, nil
+}
+
+// statsSQL translates a StatsQuery. Every dimension is a JSON array of the job's
+// values, which json_each joins as rows. The table t keeps one row per job and
+// group, so a job counts once per group and measures are not weighted by the
+// number of values. SQLite has no percentile_cont, so percentiles are computed
+// from the ranked values of a group, see percentileSQL.
+func statsSQL(query storage.StatsQuery) (string, []interface{}) {
+	w := jobFilterToSQL(query.Filter)
+	for _, condition := range query.Where {
+		placeholders := make([]string, len(condition.Values))
+		args := make([]interface{}, len(condition.Values))
+		for i, value := range condition.Values {
+			placeholders[i], args[i] = "?", value
+		}
+		w.add(fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE value COLLATE NOCASE IN (%s))",
+			dimensionSQL(condition.Dimension, query.TimeBucket()), strings.Join(placeholders, ", ")), args...)
+	}
+
+	inner := []string{"j.id"}
+	from := "jobs j"
+	var keys []string
+	for i, dimension := range query.GroupBy {
+		key := fmt.Sprintf("k%d", i)
+		inner = append(inner, fmt.Sprintf("d%d.value AS %s", i, key))
+		from += fmt.Sprintf(" JOIN json_each(%s) d%d", dimensionSQL(dimension, query.TimeBucket()), i)
+		keys = append(keys, key)
+	}
+	outer := append([]string{}, keys...)
+	outer = append(outer, "COUNT(*) AS count")
+	var ranked []string
+	for i, measure := range query.Measures {
+		column := fmt.Sprintf("m%d", i)
+		inner = append(inner, fmt.Sprintf("%s AS %s", measureSQL(measure.Field), column))
+		if measure.Aggregate.IsPercentile() {
+			ranked = append(ranked, rankedSQL(column, keys))
+			outer = append(outer, percentileSQL(measure.Aggregate, column, keys)+" AS "+column)
+		} else {
+			outer = append(outer, fmt.Sprintf("CAST(%s(%s) AS REAL) AS %s", strings.ToUpper(string(measure.Aggregate)), column, column))
+		}
+	}
+	t := "t AS (SELECT DISTINCT " + strings.Join(inner, ", ") + " FROM " + from + w.String() + ")"
+
+	sql := "WITH " + strings.Join(append([]string{t}, ranked...), ", ") + " SELECT " + strings.Join(outer, ", ") + " FROM t"
+	if len(keys) > 0 {
+		sql += " GROUP BY " + strings.Join(keys, ", ")
+	} else {
+		// Ohne Jobs gibt es wie in MongoDB keine Gruppe
+		sql += " HAVING COUNT(*) > 0"
+	}
+	if order := statsOrder(query); order != "" {
+		sql += " ORDER BY " + order
+	}
+	args := w.args
+	if query.Limit > 0 {
+		sql += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+	return sql, args
+}
+
+// rankedSQL numbers the values of a measure column within each group
+func rankedSQL(column string, keys []string) string {
+	partition := ""
+	if len(keys) > 0 {
+		partition = "PARTITION BY " + strings.Join(keys, ", ")
+	}
+	return fmt.Sprintf(`r%s AS (SELECT %s%s AS v, ROW_NUMBER() OVER (%s ORDER BY %s) AS n,
+		COUNT(*) OVER (%s) AS total FROM t WHERE %s IS NOT NULL)`,
+		column, prefixKeys(keys), column, partition, column, partition, column)
+}
+
+// percentileSQL interpolates between the two values nearest to the percentile, like
+// storage.Percentile. The ranked values are those of the group of the outer row.
+func percentileSQL(aggregate storage.Aggregate, column string, keys []string) string {
+	position := fmt.Sprintf("(1 + %v * (total - 1))", aggregate.Fraction())
+	var match []string
+	for _, key := range keys {
+		match = append(match, fmt.Sprintf("r%s.%s IS t.%s", column, key, key))
+	}
+	where := ""
+	if len(match) > 0 {
+		where = " WHERE " + strings.Join(match, " AND ")
+	}
+	return fmt.Sprintf(`(SELECT SUM(CASE WHEN n = CAST(%[1]s AS INTEGER) THEN v * (1 - (%[1]s - CAST(%[1]s AS INTEGER)))
+		WHEN n = CAST(%[1]s AS INTEGER) + 1 THEN v * (%[1]s - CAST(%[1]s AS INTEGER)) END) FROM r%[2]s%[3]s)`,
+		position, column, where)
+}
+
+// prefixKeys returns the key columns as start of a select list
+func prefixKeys(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return strings.Join(keys, ", ") + ", "
+}
+
+// statsOrder sorts by the OrderBy keys and then by the dimension values. Texts are
+// compared bytewise and missing values sort first, as in MongoDB.
+func statsOrder(query storage.StatsQuery) string {
+	var order []string
+	for _, o := range query.OrderBy {
+		column := o.Key
+		for i, dimension := range query.GroupBy {
+			if string(dimension) == o.Key {
+				column = fmt.Sprintf("k%d", i)
+			}
+		}
+		for i, measure := range query.Measures {
+			if measure.Name == o.Key {
+				column = fmt.Sprintf("m%d", i)
+			}
+		}
+		if o.Descending {
+			order = append(order, column+" DESC NULLS LAST")
+		} else {
+			order = append(order, column+" NULLS FIRST")
+		}
+	}
+	for i := range query.GroupBy {
+		order = append(order, fmt.Sprintf("k%d", i))
+	}
+	return strings.Join(order, ", ")
+}
+
+// dimensionSQL returns a JSON array of the values of the job j in a dimension
+func dimensionSQL(dimension storage.Dimension, bucket storage.TimeBucket) string {
+	switch dimension {
+	case storage.DimCategory:
+		return `j.job_categories`
+	case storage.DimMustSkill:
+		return `j.must_skills`
+	case storage.DimOptionalSkill:
+		return `j.optional_skills`
+	case storage.DimBenefit:
+		return `j.benefits`
+	case storage.DimLanguage:
+		return `j.languages`
+	case storage.DimCompany:
+		return `json_array(j.company)`
+	case storage.DimEmploymentType:
+		return `json_array(j.employment_type)`
+	case storage.DimEducationLevel:
+		return `json_array(j.education_level)`
+	case storage.DimRemote:
+		return `json_array(CASE WHEN j.remote THEN 'true' ELSE 'false' END)`
+	case storage.DimCompanySize:
+		return `json_array(CASE WHEN j.company_size < 0 THEN 'Unknown' WHEN j.company_size <= 50 THEN 'Small'
+			WHEN j.company_size <= 250 THEN 'Medium' ELSE 'Large' END)`
+	case storage.DimExperience:
+		return `json_array(CASE WHEN j.years_of_experience <= 2 THEN 'Junior' WHEN j.years_of_experience <= 5 THEN 'Mid-Level'
+			ELSE 'Senior' END)`
+	case storage.DimLocation:
+		return `CASE WHEN COALESCE(json_array_length(j.locations), 0) = 0 THEN json_array(j.location)
+			ELSE (SELECT json_group_array(COALESCE(NULLIF(json_extract(p.value, '$.city'), ''), j.location))
+				FROM json_each(j.locations) p) END`
+	case storage.DimCanton:
+		return `(SELECT json_group_array(json_extract(p.value, '$.canton')) FROM json_each(j.locations) p
+			WHERE json_extract(p.value, '$.canton') <> '')`
+	case storage.DimPostingDate:
+		return `json_array(` + dateSQL("j.posting_date", bucket) + `)`
+	case storage.DimClosedDate:
+		return `CASE WHEN j.closed_at IS NOT NULL THEN json_array(` + dateSQL("j.closed_at", bucket) + `) ELSE '[]' END`
+	}
+	return `'[]'`
+}
+
+// dateSQL cuts a time column, stored in UTC, to the bucket
+func dateSQL(column string, bucket storage.TimeBucket) string {
+	if bucket == storage.BucketMonth {
+		return `substr(` + column + `, 1, 7)`
+	}
+	return `substr(` + column + `, 1, 10)`
+}
+
+// measureSQL returns an expression for the value of a measure field of the job j,
+// NULL if the job has none
+func measureSQL(field storage.MeasureField) string {
+	switch field {
+	case storage.FieldYearsOfExperience:
+		return `CASE WHEN j.years_of_experience > 0 THEN j.years_of_experience END`
+	case storage.FieldCompanySize:
+		return `CASE WHEN j.company_size > 0 THEN j.company_size END`
+	case storage.FieldAnnualSalary:
+		return `(SELECT AVG(v) FROM (SELECT json_extract(j.salary, '$.annualMinChf') AS v
+			UNION ALL SELECT json_extract(j.salary, '$.annualMaxChf')) WHERE v > 0)`
+	case storage.FieldPostingDate:
+		return `CASE WHEN j.posting_date > '` + zeroTime + `' THEN unixepoch(j.posting_date) END`
+	case storage.FieldLifetime:
+		return `CASE WHEN j.posting_date > '` + zeroTime + `' AND j.closed_at >= j.posting_date
+			THEN (unixepoch(j.closed_at, 'subsec') - unixepoch(j.posting_date, 'subsec')) / 86400 END`
+	}
+	return `NULL`
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"slices"
+
+	"job-scraper/internal/apperrors"
+)
+
+// Dimension is a property of jobs that statistics are grouped by. A job has one or
+// more values per dimension and counts once for each of them.
+type Dimension string
+
+const (
+	DimCategory       Dimension = "category"
+	DimMustSkill      Dimension = "mustSkill"
+	DimOptionalSkill  Dimension = "optionalSkill"
+	DimBenefit        Dimension = "benefit"
+	DimLanguage       Dimension = "language" // languages required for the job
+	DimCompany        Dimension = "company"
+	DimEmploymentType Dimension = "employmentType"
+	DimEducationLevel Dimension = "educationLevel"
+	DimRemote         Dimension = "remote"          // "true" or "false"
+	DimCompanySize    Dimension = "companySize"     // see CompanySizeClass
+	DimExperience     Dimension = "experienceLevel" // see ExperienceLevel
+	// DimLocation is the resolved city of each place of work, or the extracted
+	// location text if no place has been resolved
+	DimLocation Dimension = "location"
+	DimCanton   Dimension = "canton" // jobs without resolved canton have no value
+	// DimPostingDate is the posting date in UTC, cut to the StatsQuery.Bucket
+	DimPostingDate Dimension = "postingDate"
+	// DimClosedDate is the date the job was closed in UTC, cut to the StatsQuery.Bucket.
+	// Open jobs have no value.
+	DimClosedDate Dimension = "closedDate"
+)
+
+// TimeBucket is the period DimPostingDate and DimClosedDate are grouped by
+type TimeBucket string
+
+const (
+	BucketDay   TimeBucket = "day"   // 2006-01-02
+	BucketMonth TimeBucket = "month" // 2006-01
+)
+
+// Aggregate combines the values of a measure field within a group
+type Aggregate string
+
+const (
+	AggregateAvg   Aggregate = "avg"
+	AggregateMax   Aggregate = "max"
+	AggregateCount Aggregate = "count" // number of jobs with a value
+	// AggregateMedian and AggregateP90 are percentiles, interpolated linearly between
+	// the two nearest values like percentile_cont in SQL, see Percentile
+	AggregateMedian Aggregate = "median"
+	AggregateP90    Aggregate = "p90"
+)
+
+// MeasureField is a numeric property of jobs. Jobs without a value, e.g. without
+// salary, are ignored by the aggregate.
+type MeasureField string
+
+const (
+	FieldYearsOfExperience MeasureField = "yearsOfExperience" // values above 0
+	FieldCompanySize       MeasureField = "companySize"       // values above 0
+	// FieldAnnualSalary is the annual salary in CHF: the middle of the range, or the
+	// known value of an open range
+	FieldAnnualSalary MeasureField = "annualSalary"
+	FieldPostingDate  MeasureField = "postingDate" // Unix time in seconds
+	// FieldLifetime is the number of days from posting to closing, for closed jobs
+	// with a posting date not after the closing
+	FieldLifetime MeasureField = "lifetime"
+)
+
+// CountKey sorts by the number of jobs of a group, see StatsOrder
+const CountKey = "count"
+
+// Measure aggregates a field over the jobs of a group. Name is its key in
+// StatsRow.Values and StatsOrder.
+type Measure struct {
+	Name      string
+	Aggregate Aggregate
+	Field     MeasureField
+}
+
+// StatsCondition keeps the jobs with at least one value of the dimension in
+// Values, compared ignoring case
+type StatsCondition struct {
+	Dimension Dimension
+	Values    []string
+}
+
+// StatsOrder sorts the groups by CountKey, a measure name or a grouped dimension
+type StatsOrder struct {
+	Key        string
+	Descending bool
+}
+
+// StatsQuery describes a statistic independent of the storage backend: the jobs
+// matching Filter and Where are grouped by the GroupBy dimensions, and every group
+// is counted and aggregated by the Measures. Without GroupBy, all jobs form one
+// group. Groups with equal sort keys are ordered by their dimension values.
+type StatsQuery struct {
+	Filter   JobFilter
+	Where    []StatsCondition
+	GroupBy  []Dimension
+	Bucket   TimeBucket // for DimPostingDate and DimClosedDate, BucketDay if empty
+	Measures []Measure
+	OrderBy  []StatsOrder
+	Limit    int // top N groups, all if 0
+}
+
+// StatsRow is a group of a StatsQuery
+type StatsRow struct {
+	Keys   []string // the values of the GroupBy dimensions
+	Count  int      // number of jobs in the group
+	Values map[string]float64
+}
+
+// Key returns the value of a grouped dimension
+func (r StatsRow) Key(query StatsQuery, dimension Dimension) string {
+	if i := slices.Index(query.GroupBy, dimension); i >= 0 && i < len(r.Keys) {
+		return r.Keys[i]
+	}
+	return ""
+}
+
+// StatsAggregator is implemented by storages that compute statistics in the
+// database. For other storages, ComputeStats works on the jobs of FindJobs.
+type StatsAggregator interface {
+	AggregateStats(ctx context.Context, query StatsQuery) ([]StatsRow, error)
+}
+
+// Validate checks the dimensions, measures and sort keys of the query
+func (q StatsQuery) Validate() error {
+	invalid := func(message string) error {
+		return apperrors.NewBaseError(apperrors.ErrCodeValidation, message, nil)
+	}
+
+	for _, dimension := range q.GroupBy {
+		if !isDimension(dimension) {
+			return invalid("invalid statistics dimension: " + string(dimension))
+		}
+	}
+	for _, condition := range q.Where {
+		if !isDimension(condition.Dimension) {
+			return invalid("invalid statistics dimension: " + string(condition.Dimension))
+		}
+	}
+	switch q.Bucket {
+	case "", BucketDay, BucketMonth:
+	default:
+		return invalid("invalid time bucket: " + string(q.Bucket))
+	}
+
+	names := map[string]bool{CountKey: true}
+	for _, measure := range q.Measures {
+		switch {
+		case !isAggregate(measure.Aggregate):
+			return invalid("invalid aggregate: " + string(measure.Aggregate))
+		case !isMeasureField(measure.Field):
+			return invalid("invalid measure field: " + string(measure.Field))
+		case names[measure.Name] || isDimension(Dimension(measure.Name)):
+			return invalid("duplicate statistics key: " + measure.Name)
+		}
+		names[measure.Name] = true
+	}
+	for _, order := range q.OrderBy {
+		if !names[order.Key] && !slices.Contains(q.GroupBy, Dimension(order.Key)) {
+			return invalid("invalid statistics sort key: " + order.Key)
+		}
+	}
+	if q.Limit < 0 {
+		return invalid("invalid statistics limit")
+	}
+	return nil
+}
+
+// TimeBucket returns the bucket of DimPostingDate and DimClosedDate
+func (q StatsQuery) TimeBucket() TimeBucket {
+	if q.Bucket == "" {
+		return BucketDay
+	}
+	return q.Bucket
+}
+
+func isDimension(dimension Dimension) bool {
+	switch dimension {
+	case DimCategory, DimMustSkill, DimOptionalSkill, DimBenefit, DimLanguage, DimCompany,
+		DimEmploymentType, DimEducationLevel, DimRemote, DimCompanySize, DimExperience,
+		DimLocation, DimCanton, DimPostingDate, DimClosedDate:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPercentile reports whether the aggregate is AggregateMedian or AggregateP90
+func (a Aggregate) IsPercentile() bool {
+	return a == AggregateMedian || a == AggregateP90
+}
+
+// Fraction returns the fraction of values below a percentile aggregate
+func (a Aggregate) Fraction() float64 {
+	switch a {
+	case AggregateMedian:
+		return 0.5
+	case AggregateP90:
+		return 0.9
+	default:
+		return 0
+	}
+}
+
+func isAggregate(aggregate Aggregate) bool {
+	switch aggregate {
+	case AggregateAvg, AggregateMax, AggregateCount, AggregateMedian, AggregateP90:
+		return true
+	default:
+		return false
+	}
+}
+
+func isMeasureField(field MeasureField) bool {
+	switch field {
+	case FieldYearsOfExperience, FieldCompanySize, FieldAnnualSalary, FieldPostingDate, FieldLifetime:
+		return true
+	default:
+		return false
+	}
+}
+
+// CompanySizeClass returns Small (up to 50 employees), Medium (up to 250) or Large.
+// Negative sizes are invalid and Unknown.
+func CompanySizeClass(size int) string {
+	switch {
+	case size < 0:
+		return "Unknown"
+	case size <= 50:
+		return "Small"
+	case size <= 250:
+		return "Medium"
+	default:
+		return "Large"
+	}
+}
+
+// ExperienceLevel returns Junior (up to 2 years), Mid-Level (up to 5) or Senior
+func ExperienceLevel(years int) string {
+	switch {
+	case years <= 2:
+		return "Junior"
+	case years <= 5:
+		return "Mid-Level"
+	default:
+		return "Senior"
+	}
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"job-scraper/internal/models"
+)
+
+// ComputeStats computes a statistic from jobs in memory, for storages that are no
+// StatsAggregator. The jobs must match query.Filter already.
+func ComputeStats(jobs []models.Job, query StatsQuery) ([]StatsRow, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		row     StatsRow
+		counts  map[string]int       // jobs with a value per measure
+		samples map[string][]float64 // values of the percentile measures
+	}
+	groups := make(map[string]*group)
+	for _, job := range jobs {
+		if !matchesConditions(job, query) {
+			continue
+		}
+		for _, keys := range keyCombinations(job, query) {
+			id := strings.Join(keys, "\x00")
+			g, ok := groups[id]
+			if !ok {
+				g = &group{
+					row:     StatsRow{Keys: keys, Values: make(map[string]float64)},
+					counts:  make(map[string]int),
+					samples: make(map[string][]float64),
+				}
+				groups[id] = g
+			}
+			g.row.Count++
+
+			for _, measure := range query.Measures {
+				value, ok := measureValue(job, measure.Field)
+				if !ok {
+					continue
+				}
+				current, seen := g.row.Values[measure.Name]
+				switch {
+				case measure.Aggregate.IsPercentile():
+					g.samples[measure.Name] = append(g.samples[measure.Name], value)
+				case measure.Aggregate == AggregateAvg:
+					g.row.Values[measure.Name] = current + value
+				case measure.Aggregate == AggregateCount:
+					g.row.Values[measure.Name] = current + 1
+				case !seen || value > current:
+					g.row.Values[measure.Name] = value
+				}
+				g.counts[measure.Name]++
+			}
+		}
+	}
+
+	rows := make([]StatsRow, 0, len(groups))
+	for _, g := range groups {
+		for _, measure := range query.Measures {
+			switch n := g.counts[measure.Name]; {
+			case n == 0:
+			case measure.Aggregate == AggregateAvg:
+				g.row.Values[measure.Name] /= float64(n)
+			case measure.Aggregate.IsPercentile():
+				g.row.Values[measure.Name] = Percentile(g.samples[measure.Name], measure.Aggregate.Fraction())
+			}
+		}
+		rows = append(rows, g.row)
+	}
+	return SortStats(rows, query), nil
+}
+
+// SortStats orders groups by query.OrderBy and cuts them to query.Limit, for
+// backends that cannot sort by every measure in the database
+func SortStats(rows []StatsRow, query StatsQuery) []StatsRow {
+	slices.SortFunc(rows, func(a, b StatsRow) int { return compareRows(a, b, query) })
+	if query.Limit > 0 && len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+	}
+	return rows
+}
+
+// Percentile returns the value below which the fraction p of the values lies,
+// interpolated linearly between the two nearest values. The values are sorted in place.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	rank := p * float64(len(values)-1)
+	lower := int(rank)
+	if lower+1 >= len(values) {
+		return values[lower]
+	}
+	return values[lower] + (rank-float64(lower))*(values[lower+1]-values[lower])
+}
+
+// compareRows orders groups by query.OrderBy and then by their dimension values.
+// Missing measure values sort before all others, like null in MongoDB.
+func compareRows(a, b StatsRow, query StatsQuery) int {
+	for _, order := range query.OrderBy {
+		var c int
+		switch i := slices.Index(query.GroupBy, Dimension(order.Key)); {
+		case order.Key == CountKey:
+			c = cmp.Compare(a.Count, b.Count)
+		case i >= 0:
+			c = strings.Compare(a.Keys[i], b.Keys[i])
+		default:
+			x, okA := a.Values[order.Key]
+			y, okB := b.Values[order.Key]
+			c = cmp.Or(compareBool(okA, okB), cmp.Compare(x, y))
+		}
+		if order.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return slices.Compare(a.Keys, b.Keys)
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// matchesConditions reports whether a job matches the Where conditions of the query
+func matchesConditions(job models.Job, query StatsQuery) bool {
+	for _, condition := range query.Where {
+		values := dimensionValues(job, condition.Dimension, query.TimeBucket())
+		if !slices.ContainsFunc(values, func(value string) bool {
+			return slices.ContainsFunc(condition.Values, func(wanted string) bool { return strings.EqualFold(value, wanted) })
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyCombinations returns every combination of the job's values of the grouped
+// dimensions. Jobs without a value in one of the dimensions are not grouped.
+func keyCombinations(job models.Job, query StatsQuery) [][]string {
+	combinations := [][]string{{}}
+	for _, dimension := range query.GroupBy {
+		values := dimensionValues(job, dimension, query.TimeBucket())
+		next := make([][]string, 0, len(combinations)*len(values))
+		for _, keys := range combinations {
+			for _, value := range values {
+				next = append(next, append(slices.Clip(keys), value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// dimensionValues returns the distinct values of a job in a dimension
+func dimensionValues(job models.Job, dimension Dimension, bucket TimeBucket) []string {
+	var values []string
+	switch dimension {
+	case DimCategory:
+		values = job.JobCategories
+	case DimMustSkill:
+		values = job.MustSkills
+	case DimOptionalSkill:
+		values = job.OptionalSkills
+	case DimBenefit:
+		values = job.Benefits
+	case DimLanguage:
+		values = job.Languages
+	case DimCompany:
+		return []string{job.Company}
+	case DimEmploymentType:
+		return []string{job.EmploymentType}
+	case DimEducationLevel:
+		return []string{job.EducationLevel}
+	case DimRemote:
+		return []string{strconv.FormatBool(job.Remote)}
+	case DimCompanySize:
+		return []string{CompanySizeClass(job.CompanySize)}
+	case DimExperience:
+		return []string{ExperienceLevel(job.YearsOfExperience)}
+	case DimLocation:
+		if len(job.Locations) == 0 {
+			return []string{job.Location}
+		}
+		for _, place := range job.Locations {
+			values = append(values, cmp.Or(place.City, job.Location))
+		}
+	case DimCanton:
+		for _, place := range job.Locations {
+			if place.Canton != "" {
+				values = append(values, place.Canton)
+			}
+		}
+	case DimPostingDate:
+		return []string{bucketDate(job.PostingDate, bucket)}
+	case DimClosedDate:
+		if job.ClosedAt == nil {
+			return nil
+		}
+		return []string{bucketDate(*job.ClosedAt, bucket)}
+	}
+
+	distinct := make([]string, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(distinct, value) {
+			distinct = append(distinct, value)
+		}
+	}
+	return distinct
+}
+
+// bucketDate formats a date in UTC, cut to the bucket
+func bucketDate(t time.Time, bucket TimeBucket) string {
+	if bucket == BucketMonth {
+		return t.UTC().Format("2006-01")
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// measureValue returns the value of a measure field and whether the job has one
+func measureValue(job models.Job, field MeasureField) (float64, bool) {
+	switch field {
+	case FieldYearsOfExperience:
+		return float64(job.YearsOfExperience), job.YearsOfExperience > 0
+	case FieldCompanySize:
+		return float64(job.CompanySize), job.CompanySize > 0
+	case FieldAnnualSalary:
+		if job.Salary == nil {
+			return 0, false
+		}
+		var sum float64
+		var n int
+		for _, value := range []float64{job.Salary.AnnualMinCHF, job.Salary.AnnualMaxCHF} {
+			if value > 0 {
+				sum += value
+				n++
+			}
+		}
+		return sum / float64(max(n, 1)), n > 0
+	case FieldPostingDate:
+		return float64(job.PostingDate.Unix()), !job.PostingDate.IsZero()
+	case FieldLifetime:
+		if job.ClosedAt == nil || job.PostingDate.IsZero() || job.ClosedAt.Before(job.PostingDate) {
+			return 0, false
+		}
+		return job.ClosedAt.Sub(job.PostingDate).Hours() / 24, true
+	}
+	return 0, false
+}
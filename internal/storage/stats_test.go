@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"job-scraper/internal/apperrors"
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	day := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	jobs := []models.Job{
+		// Doppelte Skills zählen nur einmal
+		{Company: "Acme", CompanySize: 20, MustSkills: []string{"Go", "SQL", "Go"}, YearsOfExperience: 3, PostingDate: day},
+		{Company: "Acme", CompanySize: 30, MustSkills: []string{"Go"}, YearsOfExperience: 5, PostingDate: day.AddDate(0, 0, 1)},
+		{Company: "Globex", CompanySize: 500, MustSkills: []string{"Java", "SQL"}, PostingDate: day.AddDate(0, 1, 0)},
+	}
+
+	query := StatsQuery{
+		GroupBy: []Dimension{DimMustSkill},
+		Measures: []Measure{
+			{Name: "avgExperience", Aggregate: AggregateAvg, Field: FieldYearsOfExperience},
+			{Name: "withExperience", Aggregate: AggregateCount, Field: FieldYearsOfExperience},
+			{Name: "maxSize", Aggregate: AggregateMax, Field: FieldCompanySize},
+		},
+		OrderBy: []StatsOrder{{Key: CountKey, Descending: true}},
+	}
+	rows, err := ComputeStats(jobs, query)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	// Gleich viele Jobs werden nach dem Skill sortiert
+	assert.Equal(t, []string{"Go"}, rows[0].Keys)
+	assert.Equal(t, 2, rows[0].Count)
+	assert.Equal(t, map[string]float64{"avgExperience": 4, "withExperience": 2, "maxSize": 30}, rows[0].Values)
+	assert.Equal(t, "SQL", rows[1].Key(query, DimMustSkill))
+	assert.Equal(t, 2, rows[1].Count)
+	assert.Equal(t, map[string]float64{"avgExperience": 3, "withExperience": 1, "maxSize": 500}, rows[1].Values)
+	assert.Equal(t, "Java", rows[2].Keys[0])
+	assert.NotContains(t, rows[2].Values, "avgExperience")
+
+	rows, err = ComputeStats(jobs, StatsQuery{
+		Where:   []StatsCondition{{Dimension: DimCompanySize, Values: []string{"small"}}},
+		GroupBy: []Dimension{DimPostingDate},
+		Bucket:  BucketMonth,
+		Limit:   1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []StatsRow{{Keys: []string{"2024-10"}, Count: 2, Values: map[string]float64{}}}, rows)
+
+	rows, err = ComputeStats(nil, StatsQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func TestComputeLifetimeStats(t *testing.T) {
+	posted := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	closed := func(days int) *time.Time {
+		at := posted.AddDate(0, 0, days)
+		return &at
+	}
+	jobs := []models.Job{
+		{Company: "Acme", PostingDate: posted, ClosedAt: closed(10)},
+		{Company: "Acme", PostingDate: posted, ClosedAt: closed(40)},
+		{Company: "Acme", PostingDate: posted, ClosedAt: closed(20)},
+		// Offene Jobs und Schliessungen vor der Veröffentlichung haben keine Lebensdauer
+		{Company: "Acme", PostingDate: posted},
+		{Company: "Globex", PostingDate: posted, ClosedAt: closed(-1)},
+	}
+
+	query := StatsQuery{
+		GroupBy: []Dimension{DimCompany},
+		Measures: []Measure{
+			{Name: "median", Aggregate: AggregateMedian, Field: FieldLifetime},
+			{Name: "p90", Aggregate: AggregateP90, Field: FieldLifetime},
+		},
+		OrderBy: []StatsOrder{{Key: "median", Descending: true}},
+	}
+	rows, err := ComputeStats(jobs, query)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Acme", rows[0].Keys[0])
+	assert.Equal(t, map[string]float64{"median": 20, "p90": 36}, rows[0].Values)
+	assert.Empty(t, rows[1].Values)
+
+	rows, err = ComputeStats(jobs, StatsQuery{GroupBy: []Dimension{DimClosedDate}, Bucket: BucketMonth})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"2024-08"}, rows[0].Keys)
+	assert.Equal(t, 1, rows[0].Count)
+	assert.Equal(t, []string{"2024-09"}, rows[1].Keys)
+	assert.Equal(t, 2, rows[1].Count)
+	assert.Equal(t, []string{"2024-10"}, rows[2].Keys)
+	assert.Equal(t, 1, rows[2].Count)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, 0.0, Percentile(nil, 0.5))
+	assert.Equal(t, 7.0, Percentile([]float64{7}, 0.9))
+	assert.Equal(t, 2.5, Percentile([]float64{4, 1, 3, 2}, 0.5))
+	assert.InDelta(t, 3.7, Percentile([]float64{4, 1, 3, 2}, 0.9), 1e-9)
+}
+
+func TestStatsQueryValidate(t *testing.T) {
+	invalid := []StatsQuery{
+		{GroupBy: []Dimension{"salary"}},
+		{Bucket: "week"},
+		{Measures: []Measure{{Name: "x", Aggregate: "sum", Field: FieldCompanySize}}},
+		{Measures: []Measure{{Name: CountKey, Aggregate: AggregateMax, Field: FieldCompanySize}}},
+		{OrderBy: []StatsOrder{{Key: "company"}}},
+		{Limit: -1},
+		{Measures: []Measure{{Name: "x", Aggregate: AggregateMedian, Field: "openDays"}}},
+	}
+	for _, query := range invalid {
+		assert.True(t, apperrors.HasCode(query.Validate(), apperrors.ErrCodeValidation), "%+v", query)
+	}
+
+	assert.NoError(t, StatsQuery{GroupBy: []Dimension{DimCompany}, OrderBy: []StatsOrder{{Key: "company"}}}.Validate())
+}
@@ -3,18 +3,40 @@ package storage
 import (
 	"context"
 	"job-scraper/internal/models"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
+// Storage is implemented by every storage backend. Backend-specific capabilities
+// like schema migrations are separate interfaces, see Unwrap.
 type Storage interface {
 	GetJobs(ctx context.Context) ([]models.Job, error)
 	GetJobByID(ctx context.Context, id string) (*models.Job, error)
+	GetJobByURL(ctx context.Context, url string) (*models.Job, error)
+	GetJobVersions(ctx context.Context, jobID string) ([]models.JobVersion, error)
+	FindJobs(ctx context.Context, filter JobFilter) ([]models.Job, error)
+	QueryJobs(ctx context.Context, query JobQuery) (JobPage, error)
+	SearchJobs(ctx context.Context, search JobSearch) (SearchPage, error)
 	SaveJob(ctx context.Context, job models.Job) error
+	QueueJob(ctx context.Context, job models.Job) error
+	GetQueuedJobs(ctx context.Context) ([]models.QueuedJob, error)
+	DeleteQueuedJob(ctx context.Context, url string) error
+	UpdateJob(ctx context.Context, job models.Job) error
+	SaveFailedJob(ctx context.Context, job models.FailedJob) error
+	GetFailedJobs(ctx context.Context) ([]models.FailedJob, error)
+	DeleteFailedJob(ctx context.Context, url string) error
 	GetJobCountByCategory(ctx context.Context) (map[string]int, error)
+	GetSkillCounts(ctx context.Context) (map[string]int, error)
 	GetTotalJobCount(ctx context.Context) (int, error)
 	GetExistingURLs(ctx context.Context) (map[string]bool, error)
+	GetFingerprints(ctx context.Context, since time.Time) ([]JobFingerprint, error)
 	Close(ctx context.Context) error
-	AggregateJobs(ctx context.Context, pipeline mongo.Pipeline) ([]bson.M, error)
+}
+
+// Unwrap returns the backend behind a MetricsDecorator, so that optional
+// interfaces of the backend can be checked
+func Unwrap(s Storage) Storage {
+	if decorator, ok := s.(*MetricsDecorator); ok {
+		return decorator.GetOriginalStorage()
+	}
+	return s
 }
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"reflect"
+	"sort"
+
+	"job-scraper/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Felder, die sich bei jedem Scraping ändern oder abgeleitet sind, zählen nicht als Änderung
+var unversionedFields = map[string]bool{
+	"_id":         true,
+	"fingerprint": true,
+	"duplicateOf": true,
+	"source":      true,
+	"lastSeenAt":  true,
+}
+
+// DiffJobs compares the stored fields of two jobs, so backends record the same
+// changes when a re-scraped posting replaces a stored job. The raw source is only
+// compared by content and reported without values.
+func DiffJobs(old, new models.Job) ([]models.FieldChange, error) {
+	oldFields, err := ToDocument(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := ToDocument(new)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(oldFields))
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+
+	var changes []models.FieldChange
+	for key := range keys {
+		if unversionedFields[key] || equalValues(oldFields[key], newFields[key]) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: key, Old: oldFields[key], New: newFields[key]})
+	}
+	if rawPayload(old) != rawPayload(new) {
+		changes = append(changes, models.FieldChange{Field: "source.rawPayload"})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// equalValues compares two field values. Missing and empty lists are equal, as
+// not every backend can tell them apart.
+func equalValues(a, b interface{}) bool {
+	if isEmptyList(a) && isEmptyList(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isEmptyList(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	list, ok := value.(bson.A)
+	return ok && len(list) == 0
+}
+
+// UnversionedFields returns the stored fields, except the ID, that are updated
+// without keeping a prior version
+func UnversionedFields() []string {
+	fields := make([]string, 0, len(unversionedFields))
+	for field := range unversionedFields {
+		if field != "_id" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ToDocument converts a job into the field names and values of the job document,
+// so values compare the same in every backend
+func ToDocument(job models.Job) (bson.M, error) {
+	data, err := bson.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func rawPayload(job models.Job) string {
+	if job.Source == nil {
+		return ""
+	}
+	return job.Source.RawPayload
+}
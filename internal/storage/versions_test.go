@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiffJobs(t *testing.T) {
+	old := models.Job{
+		ID:         primitive.NewObjectID(),
+		URL:        "https://example.com/jobs/1",
+		Title:      "Go Developer",
+		MustSkills: []string{"Go"},
+		Salary:     &models.Salary{Min: 100000, Max: 120000, Currency: "CHF", Period: models.SalaryPeriodYear},
+		Source:     &models.SourcePayload{RawPayload: "<p>Go Developer</p>", FetchedAt: time.Now().Add(-time.Hour)},
+	}
+
+	unchanged := old
+	unchanged.ID = primitive.NewObjectID()
+	unchanged.Fingerprint = "00000000000000ff"
+	unchanged.Source = &models.SourcePayload{RawPayload: old.Source.RawPayload, FetchedAt: time.Now()}
+	// Eine leere Liste ist dasselbe wie keine
+	unchanged.Benefits = []string{}
+
+	changes, err := DiffJobs(old, unchanged)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changed := unchanged
+	changed.Title = "Senior Go Developer"
+	changed.MustSkills = []string{"Go", "Kubernetes"}
+	changed.Source = &models.SourcePayload{RawPayload: "<p>Senior Go Developer</p>"}
+
+	changes, err = DiffJobs(old, changed)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "mustSkills", changes[0].Field)
+	assert.Equal(t, models.FieldChange{Field: "source.rawPayload"}, changes[1])
+	assert.Equal(t, models.FieldChange{Field: "title", Old: "Go Developer", New: "Senior Go Developer"}, changes[2])
+}
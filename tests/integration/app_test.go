@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"job-scraper/internal/app"
 	"job-scraper/internal/models"
+	"job-scraper/internal/storage"
 	"net"
 	"net/http"
 	"os"
@@ -420,10 +421,12 @@ func TestJobScraper(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, resp.StatusCode, "Should get OK status when fetching jobs")
 
-		var jobs []models.Job
-		err = json.NewDecoder(resp.Body).Decode(&jobs)
+		var page storage.JobPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
 		require.NoError(t, err, "Should be able to decode jobs response")
 		resp.Body.Close()
+		jobs := page.Jobs
+		assert.Equal(t, len(jobs), page.Total, "All jobs should fit on the first page")
 
 		// Prüfe die Anzahl der Jobs (eine Seite = 20 Jobs)
 		assert.Equal(t, 20, len(jobs), "Should have found exactly 20 jobs (one page)")
@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"job-scraper/internal/models"
+	"job-scraper/internal/services"
+	"job-scraper/internal/storage"
+	"job-scraper/internal/storage/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// setupPostgres startet einen PostgreSQL-Container und gibt einen migrierten Client zurück
+func setupPostgres(t *testing.T) *postgres.Client {
+	ctx := context.Background()
+
+	t.Log("Starting PostgreSQL container...")
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "jobs",
+				"POSTGRES_PASSWORD": "jobs",
+				"POSTGRES_DB":       "jobsdb_test",
+			},
+			// Das Init-Skript startet den Server einmal vor dem eigentlichen Start
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "Failed to start PostgreSQL container")
+	t.Cleanup(func() { container.Terminate(ctx) })
+
+	mappedPort, err := container.MappedPort(ctx, "5432")
+	require.NoError(t, err)
+	hostIP, err := container.Host(ctx)
+	require.NoError(t, err)
+	uri := fmt.Sprintf("postgres://jobs:jobs@%s:%s/jobsdb_test?sslmode=disable", hostIP, mappedPort.Port())
+
+	client, err := postgres.NewClient(ctx, uri, 4)
+	require.NoError(t, err, "Failed to connect to PostgreSQL")
+	t.Cleanup(func() { client.Close(ctx) })
+
+	applied, err := client.Migrate(ctx)
+	require.NoError(t, err, "Failed to migrate PostgreSQL schema")
+	assert.NotEmpty(t, applied)
+	return client
+}
+
+func postgresTestJob(url, title string, skills ...string) models.Job {
+	return models.Job{
+		URL:               url,
+		Title:             title,
+		Description:       "Wir suchen Verstärkung für unser Team",
+		Company:           "Tech Corp",
+		Location:          "Zürich",
+		Locations:         []models.Place{{City: "Zürich", Canton: "ZH", Country: "CH"}},
+		EmploymentType:    "Full-time",
+		PostingDate:       time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		ExpirationDate:    time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+		IsActive:          true,
+		JobCategories:     []string{"SOFTWARE_DEVELOPER"},
+		MustSkills:        skills,
+		OptionalSkills:    []string{"Kubernetes"},
+		Benefits:          []string{"Home Office"},
+		YearsOfExperience: 3,
+		CompanySize:       120,
+		Languages:         []string{"German"},
+		PostingLanguage:   "de",
+	}
+}
+
+func TestPostgresStorage(t *testing.T) {
+	ctx := context.Background()
+	client := setupPostgres(t)
+
+	require.NoError(t, client.SaveJob(ctx, postgresTestJob("https://example.com/go", "Go Developer", "Go", "PostgreSQL")))
+	require.NoError(t, client.SaveJob(ctx, postgresTestJob("https://example.com/rust", "Rust Developer", "Rust", "Go")))
+
+	t.Run("Versions", func(t *testing.T) {
+		stored, err := client.GetJobByURL(ctx, "https://example.com/go")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Go", "PostgreSQL"}, stored.MustSkills)
+		assert.Equal(t, "ZH", stored.Locations[0].Canton)
+
+		changed := postgresTestJob("https://example.com/go", "Senior Go Developer", "Go", "PostgreSQL")
+		require.NoError(t, client.SaveJob(ctx, changed))
+
+		versions, err := client.GetJobVersions(ctx, stored.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, "Go Developer", versions[0].Job.Title)
+
+		total, err := client.GetTotalJobCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		page, err := client.QueryJobs(ctx, storage.JobQuery{Skill: "rust", SkillType: storage.SkillTypeMust})
+		require.NoError(t, err)
+		require.Len(t, page.Jobs, 1)
+		assert.Equal(t, "Rust Developer", page.Jobs[0].Title)
+
+		page, err = client.QueryJobs(ctx, storage.JobQuery{Location: "zh", SortBy: storage.SortTitle, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, page.Total)
+		require.NotEmpty(t, page.NextCursor)
+
+		next, err := client.QueryJobs(ctx, storage.JobQuery{Location: "zh", SortBy: storage.SortTitle, Limit: 1, Cursor: page.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, next.Jobs, 1)
+		assert.NotEqual(t, page.Jobs[0].ID, next.Jobs[0].ID)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		page, err := client.SearchJobs(ctx, storage.JobSearch{Text: "rust"})
+		require.NoError(t, err)
+		require.Len(t, page.Results, 1)
+		assert.Equal(t, "Rust Developer", page.Results[0].Job.Title)
+
+		page, err = client.SearchJobs(ctx, storage.JobSearch{Text: "developer -rust"})
+		require.NoError(t, err)
+		require.Len(t, page.Results, 1)
+		assert.Equal(t, "Senior Go Developer", page.Results[0].Job.Title)
+	})
+
+	t.Run("Statistics", func(t *testing.T) {
+		stats := services.NewJobStatisticsService(client)
+
+		skills, err := stats.GetTopSkills(services.StatsFilter{})
+		require.NoError(t, err)
+		require.NotEmpty(t, skills)
+		assert.Equal(t, "Go", skills[0].ID)
+		assert.EqualValues(t, 2, skills[0].Count)
+
+		cantons, err := stats.GetJobsByCanton(services.StatsFilter{PostingLanguage: "de"})
+		require.NoError(t, err)
+		require.Len(t, cantons, 1)
+		assert.Equal(t, "ZH", cantons[0].ID)
+	})
+	t.Run("QueuedJobs", func(t *testing.T) {
+		job := postgresTestJob("https://example.com/queued", "Queued Developer", "Go")
+		job.Source = &models.SourcePayload{RawPayload: "<p>Queued Developer</p>", ContentType: "text/html"}
+		require.NoError(t, client.QueueJob(ctx, job))
+
+		queued, err := client.GetQueuedJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+		assert.Equal(t, "Queued Developer", queued[0].Job.Title)
+		require.NotNil(t, queued[0].Job.Source)
+		assert.Equal(t, "<p>Queued Developer</p>", queued[0].Job.Source.RawPayload)
+
+		require.NoError(t, client.DeleteQueuedJob(ctx, job.URL))
+		queued, err = client.GetQueuedJobs(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, queued)
+	})
+}